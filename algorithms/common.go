@@ -1,15 +1,19 @@
 package algorithms
 
 import (
-	"github.com/KeepTheBeats/routing-algorithms/random"
+	"context"
+	"fmt"
 	"gogeneticwrsp/model"
-	"log"
+	"math"
 	"sort"
 )
 
 // SchedulingAlgorithm is the interface that all algorithms should implement
 type SchedulingAlgorithm interface {
 	Schedule(clouds []model.Cloud, apps []model.Application) (model.Solution, error)
+	// ScheduleContext is Schedule that stops searching when ctx is canceled or its deadline is exceeded.
+	// After stopping, it returns the best acceptable solution found until then with Truncated set.
+	ScheduleContext(ctx context.Context, clouds []model.Cloud, apps []model.Application) (model.Solution, error)
 }
 
 // SimulateDeploy record the applications in the target cloud's RunningApps
@@ -29,8 +33,8 @@ func SimulateDeploy(clouds []model.Cloud, apps []model.Application, solution mod
 		// service does not need to subtract resources here, instead, we check it latter
 
 		// task does not need to subtract resources, because:
-		// 1. at one time, only one task will be executed on the cloud;
-		// 2. we will compare the remaining resources with the tasks with the highest requirements for acceptance check
+		// 1. at one time, only one task will be executed on the cloud, or the tasks share the logical cores left by services on a cloud with ConcurrentTasks;
+		// 2. we will compare the remaining resources with the tasks with the highest requirements, or with the sum of the requirements of concurrent tasks, for acceptance check
 	}
 	return deployedClouds
 }
@@ -49,6 +53,7 @@ func TrulyDeploy(clouds []model.Cloud, apps []model.Application, solution model.
 
 	var appsCopy []model.Application = model.AppsCopy(apps)
 	var deployedClouds []model.Cloud = model.CloudsCopy(clouds)
+	var replicaSets [][]int = model.ReplicaSets(appsCopy)
 	for appIndex := 0; appIndex < len(solution.SchedulingResult); appIndex++ {
 		// this application is rejected, and will not be deployed
 		if solution.SchedulingResult[appIndex] == len(clouds) {
@@ -64,11 +69,10 @@ func TrulyDeploy(clouds []model.Cloud, apps []model.Application, solution model.
 		// subtract cloud allocatable resources
 		if !thisApp.IsTask {
 			deployedClouds[cloudIndex].Allocatable.CPU.LogicalCores -= thisApp.SvcReq.CPUClock / deployedClouds[cloudIndex].Allocatable.CPU.BaseClock
-			deployedClouds[cloudIndex].Allocatable.Memory -= thisApp.SvcReq.Memory
-			deployedClouds[cloudIndex].Allocatable.Storage -= thisApp.SvcReq.Storage
+			deployedClouds[cloudIndex].Allocatable.SetAmounts(deployedClouds[cloudIndex].Allocatable.Amounts().Sub(thisApp.SvcReq.Amounts()))
 
 			for _, dependence := range thisApp.Depend {
-				dependCloudIdx := solution.SchedulingResult[dependence.AppIdx]
+				dependCloudIdx := servingCloud(deployedClouds, replicaSets, solution.SchedulingResult, dependence.AppIdx, cloudIndex)
 				//log.Println(cloudIndex, dependCloudIdx)
 				dependentApp := appsCopy[dependence.AppIdx]
 
@@ -83,16 +87,34 @@ func TrulyDeploy(clouds []model.Cloud, apps []model.Application, solution model.
 		}
 
 		// task does not need to subtract resources, because:
-		// 1. at one time, only one task will be executed on the cloud;
-		// 2. we will compare the remaining resources with the tasks with the highest requirements for acceptance check
+		// 1. at one time, only one task will be executed on the cloud, or the tasks share the logical cores left by services on a cloud with ConcurrentTasks;
+		// 2. we will compare the remaining resources with the tasks with the highest requirements, or with the sum of the requirements of concurrent tasks, for acceptance check
 	}
 	return deployedClouds
 }
 
 // Acceptable check whether a chromosome is acceptable
 func Acceptable(clouds []model.Cloud, apps []model.Application, schedulingResult []int) bool {
+	return len(checkAcceptable(clouds, apps, schedulingResult, false)) == 0
+}
+
+// AcceptableViolations is the diagnostic version of Acceptable. It walks every cloud and returns all violated constraints, and the chromosome is acceptable if and only if it returns nothing.
+// An app that violates a constraint does not take up the resources of this constraint, so the apps with lower priorities are checked with the resources left by the apps that fit.
+func AcceptableViolations(clouds []model.Cloud, apps []model.Application, schedulingResult []int) []model.Violation {
+	return checkAcceptable(clouds, apps, schedulingResult, true)
+}
+
+// checkAcceptable returns the violations of a chromosome, if !all, it stops at the first violation
+func checkAcceptable(clouds []model.Cloud, apps []model.Application, schedulingResult []int, all bool) []model.Violation {
+	var violations []model.Violation
+	// record a violation, and return whether the check should stop
+	var violate func(model.Violation) bool = func(v model.Violation) bool {
+		violations = append(violations, v)
+		return !all
+	}
 
 	var deployedClouds []model.Cloud = SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+	var replicaSets [][]int = model.ReplicaSets(apps)
 
 	// check every cloud
 	for cloudIndex := 0; cloudIndex < len(deployedClouds); cloudIndex++ {
@@ -100,33 +122,90 @@ func Acceptable(clouds []model.Cloud, apps []model.Application, schedulingResult
 		deployedClouds[cloudIndex].TmpAlloc = model.ResCopy(deployedClouds[cloudIndex].Allocatable)
 
 		curCPULC := deployedClouds[cloudIndex].TmpAlloc.CPU.LogicalCores
-		curMem := deployedClouds[cloudIndex].TmpAlloc.Memory
-		curStorage := deployedClouds[cloudIndex].TmpAlloc.Storage
+		curAmounts := deployedClouds[cloudIndex].TmpAlloc.Amounts()
+		var antiAffinity map[string]int // the app of each anti-affinity group on this cloud
 
 		// if a task together with the services with priorities higher than this task uses up any type of resources, this solution cannot be accpeted
 		sort.Sort(model.AppSlice(deployedClouds[cloudIndex].RunningApps))
 		for _, deployedApp := range deployedClouds[cloudIndex].RunningApps {
+			var resourceViolation func(model.RejectReason, string, float64, float64) model.Violation = func(reason model.RejectReason, resource string, required, available float64) model.Violation {
+				return model.Violation{CloudIdx: cloudIndex, AppIdx: deployedApp.AppIdx, Reason: reason, Resource: resource, DependAppIdx: -1, DependCloudIdx: -1, Required: required, Available: available}
+			}
 			//fmt.Println(deployedApp)
-			//fmt.Println(curCPULC, curMem, curStorage)
-			if deployedApp.IsTask { // task releases resources after completion, so all applications with the priorities lower than it can wait for its completion, so it will not block other applications with lower priorities.
-				if curMem < deployedApp.TaskReq.Memory || curStorage < deployedApp.TaskReq.Storage {
-					return false
+			//fmt.Println(curCPULC, curAmounts)
+
+			// placement constraints
+			if !CloudMeetApp(deployedClouds[cloudIndex], deployedApp) {
+				if violate(model.Violation{CloudIdx: cloudIndex, AppIdx: deployedApp.AppIdx, Reason: model.RejectReasonPlacement, Resource: "cloud", DependAppIdx: -1, DependCloudIdx: -1}) {
+					return violations
+				}
+			}
+			if deployedApp.AntiAffinityGroup != "" {
+				if otherAppIdx, exist := antiAffinity[deployedApp.AntiAffinityGroup]; exist {
+					if violate(model.Violation{CloudIdx: cloudIndex, AppIdx: deployedApp.AppIdx, Reason: model.RejectReasonAntiAffinity, Resource: "antiAffinity", DependAppIdx: otherAppIdx, DependCloudIdx: cloudIndex}) {
+						return violations
+					}
+				} else {
+					if antiAffinity == nil {
+						antiAffinity = make(map[string]int)
+					}
+					antiAffinity[deployedApp.AntiAffinityGroup] = deployedApp.AppIdx
+				}
+			}
+			for _, coLocateIdx := range deployedApp.CoLocateWith {
+				if coLocateCloudIdx := schedulingResult[coLocateIdx]; coLocateCloudIdx != cloudIndex {
+					if coLocateCloudIdx == len(deployedClouds) {
+						coLocateCloudIdx = -1
+					}
+					if violate(model.Violation{CloudIdx: cloudIndex, AppIdx: deployedApp.AppIdx, Reason: model.RejectReasonCoLocation, Resource: "coLocation", DependAppIdx: coLocateIdx, DependCloudIdx: coLocateCloudIdx}) {
+						return violations
+					}
+				}
+			}
+
+			if deployedApp.IsTask && !deployedClouds[cloudIndex].ConcurrentTasks() { // task releases resources after completion, so all applications with the priorities lower than it can wait for its completion, so it will not block other applications with lower priorities.
+				for _, shortage := range curAmounts.Shortages(deployedApp.TaskReq.Amounts()) {
+					if violate(resourceViolation(shortage.Reason, shortage.Name, shortage.Required, shortage.Available)) {
+						return violations
+					}
 				}
 			} else { // service should take up the resources, so it will block other applications.
-				curCPULC -= deployedApp.SvcReq.CPUClock / deployedClouds[cloudIndex].TmpAlloc.CPU.BaseClock
-				curMem -= deployedApp.SvcReq.Memory
-				curStorage -= deployedApp.SvcReq.Storage
-				if curCPULC < 0 || curMem < 0 || curStorage < 0 {
-					return false
+				reqCPULC, reqAmounts := deployedApp.SvcReq.CPUClock/deployedClouds[cloudIndex].TmpAlloc.CPU.BaseClock, deployedApp.SvcReq.Amounts()
+				if deployedApp.IsTask { // concurrent tasks share the logical cores left by services, but may take up the other resources at the same time
+					reqCPULC, reqAmounts = 0, deployedApp.TaskReq.Amounts()
+				}
+				var fit bool = true
+				if curCPULC-reqCPULC < 0 {
+					fit = false
+					if violate(resourceViolation(model.RejectReasonCPU, "cpu", reqCPULC, curCPULC)) {
+						return violations
+					}
+				}
+				for _, shortage := range curAmounts.Shortages(reqAmounts) {
+					fit = false
+					if violate(resourceViolation(shortage.Reason, shortage.Name, shortage.Required, shortage.Available)) {
+						return violations
+					}
+				}
+				if fit {
+					curCPULC -= reqCPULC
+					curAmounts = curAmounts.Sub(reqAmounts)
 				}
 			}
 
 			// network bandwidths and RTT
 			for _, dependence := range deployedApp.Depend {
-				dependentCloudIdx := schedulingResult[dependence.AppIdx]
+				dependentCloudIdx := servingCloud(deployedClouds, replicaSets, schedulingResult, dependence.AppIdx, cloudIndex) // a replicated service is served by its nearest replica
 				dependentApp := apps[dependence.AppIdx]
+				var edgeViolation func(model.RejectReason, string, float64, float64) model.Violation = func(reason model.RejectReason, resource string, required, available float64) model.Violation {
+					return model.Violation{CloudIdx: cloudIndex, AppIdx: deployedApp.AppIdx, Reason: reason, Resource: resource, DependAppIdx: dependence.AppIdx, DependCloudIdx: dependentCloudIdx, Required: required, Available: available}
+				}
 				if dependentCloudIdx == len(deployedClouds) {
-					return false // the dependent app is rejected
+					// the dependent app is rejected
+					if violate(model.Violation{CloudIdx: cloudIndex, AppIdx: deployedApp.AppIdx, Reason: model.RejectReasonDependencyRejected, Resource: "dependency", DependAppIdx: dependence.AppIdx, DependCloudIdx: -1}) {
+						return violations
+					}
+					continue
 				}
 
 				// cloudIndex: current cloud
@@ -139,27 +218,102 @@ func Acceptable(clouds []model.Cloud, apps []model.Application, schedulingResult
 
 				// check RTT requirements
 				if deployedClouds[cloudIndex].TmpAlloc.NetCondClouds[dependentCloudIdx].RTT > dependence.RTT {
-					return false
+					if violate(edgeViolation(model.RejectReasonRTT, "rtt", dependence.RTT, deployedClouds[cloudIndex].TmpAlloc.NetCondClouds[dependentCloudIdx].RTT)) {
+						return violations
+					}
 				}
 
 				// check downstream and upstream bandwidth requirements
 				if deployedApp.IsTask {
-					if deployedClouds[cloudIndex].TmpAlloc.NetCondClouds[dependentCloudIdx].DownBw < dependence.DownBw ||
-						deployedClouds[dependentCloudIdx].TmpAlloc.NetCondClouds[cloudIndex].DownBw < dependence.UpBw {
-						return false
+					downBw := deployedClouds[cloudIndex].TmpAlloc.NetCondClouds[dependentCloudIdx].DownBw
+					upBw := deployedClouds[dependentCloudIdx].TmpAlloc.NetCondClouds[cloudIndex].DownBw
+					if downBw < dependence.DownBw {
+						if violate(edgeViolation(model.RejectReasonBandwidth, "downBw", dependence.DownBw, downBw)) {
+							return violations
+						}
+					}
+					if upBw < dependence.UpBw {
+						if violate(edgeViolation(model.RejectReasonBandwidth, "upBw", dependence.UpBw, upBw)) {
+							return violations
+						}
 					}
 				} else {
+					// when both apps are on the same cloud, the two directions use the same link, so upBw is read after the subtraction of downstream bandwidth
+					downBw := deployedClouds[cloudIndex].TmpAlloc.NetCondClouds[dependentCloudIdx].DownBw
 					deployedClouds[cloudIndex].TmpAlloc.NetCondClouds[dependentCloudIdx].DownBw -= dependence.DownBw
+					upBw := deployedClouds[dependentCloudIdx].TmpAlloc.NetCondClouds[cloudIndex].DownBw
 					deployedClouds[dependentCloudIdx].TmpAlloc.NetCondClouds[cloudIndex].DownBw -= dependence.UpBw
-					if deployedClouds[cloudIndex].TmpAlloc.NetCondClouds[dependentCloudIdx].DownBw < 0 || deployedClouds[dependentCloudIdx].TmpAlloc.NetCondClouds[cloudIndex].DownBw < 0 {
-						return false
+					var fit bool = true
+					if deployedClouds[cloudIndex].TmpAlloc.NetCondClouds[dependentCloudIdx].DownBw < 0 {
+						fit = false
+						if violate(edgeViolation(model.RejectReasonBandwidth, "downBw", dependence.DownBw, downBw)) {
+							return violations
+						}
+					}
+					if deployedClouds[dependentCloudIdx].TmpAlloc.NetCondClouds[cloudIndex].DownBw < 0 {
+						fit = false
+						if violate(edgeViolation(model.RejectReasonBandwidth, "upBw", dependence.UpBw, upBw)) {
+							return violations
+						}
+					}
+					// a service that does not fit does not take up the bandwidth
+					if !fit {
+						deployedClouds[cloudIndex].TmpAlloc.NetCondClouds[dependentCloudIdx].DownBw += dependence.DownBw
+						deployedClouds[dependentCloudIdx].TmpAlloc.NetCondClouds[cloudIndex].DownBw += dependence.UpBw
 					}
 				}
 			}
 		}
 	}
 
-	return true
+	// the replicas of a service are checked together
+	for _, v := range replicaViolations(clouds, apps, schedulingResult, replicaSets) {
+		if violate(v) {
+			return violations
+		}
+	}
+
+	// hard deadlines are checked after resources, because they need the timing model
+	for _, v := range deadlineViolations(clouds, apps, schedulingResult) {
+		if violate(v) {
+			return violations
+		}
+	}
+
+	// so do budgets, because tasks pay until they complete
+	for _, v := range budgetViolations(clouds, apps, schedulingResult) {
+		if violate(v) {
+			return violations
+		}
+	}
+
+	return violations
+}
+
+// deadlineViolations returns the accepted apps with hard deadlines that are missed, it only calculates the time when such apps exist
+func deadlineViolations(clouds []model.Cloud, apps []model.Application, schedulingResult []int) []model.Violation {
+	var hasHardDeadline bool
+	for i := 0; i < len(apps); i++ {
+		if schedulingResult[i] != len(clouds) && apps[i].HardDeadline() {
+			hasHardDeadline = true
+			break
+		}
+	}
+	if !hasHardDeadline {
+		return nil
+	}
+
+	var violations []model.Violation
+	var deployedClouds []model.Cloud = SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+	var timeApps []model.Application = CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+	for i := 0; i < len(timeApps); i++ {
+		if schedulingResult[i] == len(clouds) || !timeApps[i].HardDeadline() || timeApps[i].Lateness() == 0 {
+			continue
+		}
+		due, _ := timeApps[i].Due()
+		violations = append(violations, model.Violation{CloudIdx: schedulingResult[i], AppIdx: i, Reason: model.RejectReasonDeadline, Resource: "deadline", DependAppIdx: -1, DependCloudIdx: -1, Required: due, Available: timeApps[i].FinishTime()})
+	}
+	return violations
 }
 
 // CalcRemainingApps
@@ -167,9 +321,9 @@ func Acceptable(clouds []model.Cloud, apps []model.Application, schedulingResult
 // timeClouds have the information: 1. execution time; 2. deployed apps
 // I need to use them to calculate: at this time what applications are still running on each cloud, and how many cycles of them still need to be executed
 // timeSinceLastDeploy unit is second
-func CalcRemainingApps(resClouds, timeClouds []model.Cloud, timeSinceLastDeploy float64) []model.Application {
+func CalcRemainingApps(resClouds, timeClouds []model.Cloud, timeSinceLastDeploy float64) ([]model.Application, error) {
 	if len(timeClouds) != len(resClouds) {
-		log.Panicf("len(timeClouds): %d, len(resClouds): %d\n", len(timeClouds), len(resClouds))
+		return nil, fmt.Errorf("%w: len(timeClouds): %d, len(resClouds): %d", ErrCloudsMismatch, len(timeClouds), len(resClouds))
 	}
 
 	timeCloudsCopy := model.CloudsCopy(timeClouds)
@@ -180,6 +334,17 @@ func CalcRemainingApps(resClouds, timeClouds []model.Cloud, timeSinceLastDeploy
 		// initialize TmpAlloc.CPU.LogicalCores
 		timeCloudsCopy[j].TmpAlloc.CPU.LogicalCores = timeCloudsCopy[j].Allocatable.CPU.LogicalCores
 		sort.Sort(model.AppSlice(timeCloudsCopy[j].RunningApps))
+		// the speed of concurrent tasks changes when other tasks start or complete, so the pool is simulated again until now
+		var pool *taskPool
+		if timeCloudsCopy[j].ConcurrentTasks() {
+			pool = newTaskPool(timeCloudsCopy[j])
+			for _, runningApp := range timeCloudsCopy[j].RunningApps {
+				if runningApp.IsTask {
+					pool.add(runningApp, runningApp.StableTime)
+				}
+			}
+			pool.advance(timeSinceLastDeploy)
+		}
 		for k := 0; k < len(timeCloudsCopy[j].RunningApps); k++ {
 			thisApp := timeCloudsCopy[j].RunningApps[k]
 			if thisApp.IsTask { // only retain tasks not done
@@ -202,17 +367,22 @@ func CalcRemainingApps(resClouds, timeClouds []model.Cloud, timeSinceLastDeploy
 
 					// tasks being executed
 					if timeSinceLastDeploy > thisApp.StableTime {
-						// calculate how many CPU cycles are not done
+						// calculate how many CPU cycles are not done, the task executes at a constant speed from StableTime to TaskCompletionTime
 						executedTime := timeSinceLastDeploy - thisApp.StableTime
-						executedCycles := executedTime * (timeCloudsCopy[j].TmpAlloc.CPU.LogicalCores * timeCloudsCopy[j].TmpAlloc.CPU.BaseClock)
+						executedCycles := thisApp.TaskReq.CPUCycle * executedTime / (thisApp.TaskCompletionTime - thisApp.StableTime)
+						if pool != nil {
+							executedCycles = thisApp.TaskReq.CPUCycle - pool.remaining(thisApp.AppIdx)
+						}
 						remainingCycles := thisApp.TaskReq.CPUCycle - executedCycles
 
 						remainingApp.AlreadyStable = true // after starting executing, it was already stable
 						remainingApp.CanMigrate = false   // cannot be migrated after starting executing
 						remainingApp.TaskReq.CPUCycle = remainingCycles
+						remainingApp.ExecutedCPUCycle += executedCycles
 
 					}
 
+					shiftDeadline(&remainingApp, timeSinceLastDeploy)
 					remainingApps = append(remainingApps, remainingApp)
 				}
 			} else { // retain all services
@@ -236,6 +406,7 @@ func CalcRemainingApps(resClouds, timeClouds []model.Cloud, timeSinceLastDeploy
 					remainingApp.AlreadyStable = true
 					timeCloudsCopy[j].TmpAlloc.CPU.LogicalCores -= thisApp.SvcReq.CPUClock / timeCloudsCopy[j].TmpAlloc.CPU.BaseClock
 				}
+				shiftDeadline(&remainingApp, timeSinceLastDeploy)
 				remainingApps = append(remainingApps, remainingApp)
 			}
 
@@ -257,6 +428,17 @@ func CalcRemainingApps(resClouds, timeClouds []model.Cloud, timeSinceLastDeploy
 				remainingApps[i].Depend = append(remainingApps[i].Depend[:j], remainingApps[i].Depend[j+1:]...)
 			}
 		}
+		for j := 0; j < len(remainingApps[i].CoLocateWith); {
+			if newIdx, exist := idxMap[remainingApps[i].CoLocateWith[j]]; exist {
+				remainingApps[i].CoLocateWith[j] = newIdx
+				j++
+			} else { // the co-located app is finished, the constraint does not apply any more
+				remainingApps[i].CoLocateWith = append(remainingApps[i].CoLocateWith[:j], remainingApps[i].CoLocateWith[j+1:]...)
+			}
+		}
+		if remainingApps[i].ReplicaIdx > 0 { // the replicas of a service are accepted together, so the first one is also remaining
+			remainingApps[i].ReplicaOf = idxMap[remainingApps[i].ReplicaOf]
+		}
 	}
 
 	// the dependent apps of executing tasks also cannot be migrated
@@ -274,13 +456,33 @@ func CalcRemainingApps(resClouds, timeClouds []model.Cloud, timeSinceLastDeploy
 		}
 	}
 
-	return remainingApps
+	return remainingApps, nil
+}
+
+// shiftDeadline makes the deadline of a remaining app relative to the start of the next round.
+// If it has already been missed, it cannot be met in any scheduling result, so a hard deadline becomes soft, otherwise no remaining apps could be accepted.
+func shiftDeadline(remainingApp *model.Application, timeSinceLastDeploy float64) {
+	if !remainingApp.IsTask && remainingApp.AlreadyStable { // the startup of this service is already done
+		remainingApp.MaxStartupTime = 0
+	}
+	if _, hasDue := remainingApp.Due(); !hasDue {
+		return
+	}
+	var due *float64 = &remainingApp.MaxStartupTime
+	if remainingApp.IsTask {
+		due = &remainingApp.Deadline
+	}
+	*due -= timeSinceLastDeploy
+	if *due <= 0 {
+		*due = math.SmallestNonzeroFloat64 // still has a deadline, and any finish time is late
+		remainingApp.DeadlineMode = model.DeadlineSoft
+	}
 }
 
 // OnePointCrossOver one point crossover operator
-func OnePointCrossOver(firstChromosome, secondChromosome Chromosome) (Chromosome, Chromosome) {
+func OnePointCrossOver(r *Random, firstChromosome, secondChromosome Chromosome) (Chromosome, Chromosome) {
 	// randomly choose a gene after which the genes are exchanged
-	startPosition := random.RandomInt(1, len(firstChromosome)-1) // in each chromosome, there are len(apps) genes
+	startPosition := r.RandomInt(1, len(firstChromosome)-1) // in each chromosome, there are len(apps) genes
 
 	//log.Println("firstIndex:", firstIndex, "secondIndex:", secondIndex, "startPosition:", startPosition)
 
@@ -305,9 +507,9 @@ func OnePointCrossOver(firstChromosome, secondChromosome Chromosome) (Chromosome
 }
 
 // TwoPointCrossOver two point crossover operator
-func TwoPointCrossOver(firstChromosome, secondChromosome Chromosome) (Chromosome, Chromosome) {
+func TwoPointCrossOver(r *Random, firstChromosome, secondChromosome Chromosome) (Chromosome, Chromosome) {
 	tmpForPick := make([]int, len(firstChromosome))
-	points := random.RandomPickN(tmpForPick, 2)
+	points := r.RandomPickN(tmpForPick, 2)
 	var point1, point2 int
 	if points[0] <= points[1] {
 		point1, point2 = points[0], points[1]
@@ -333,8 +535,7 @@ func TwoPointCrossOver(firstChromosome, secondChromosome Chromosome) (Chromosome
 	return firstNew, secondNew
 }
 
-// CloudMeetApp check whether a cloud can meet an application
+// CloudMeetApp check whether a cloud can meet an application, i.e., the placement constraints of the app about clouds allow the cloud
 func CloudMeetApp(cloud model.Cloud, app model.Application) bool {
-
-	return true
+	return app.MeetCloud(cloud)
 }
@@ -249,3 +249,330 @@ func TestAcceptable(t *testing.T) {
 
 	fmt.Println(Acceptable(clouds, apps, schedulingResult))
 }
+
+func TestAcceptableViolations(t *testing.T) {
+	var gib float64 = 1024 * 1024 * 1024
+	testCases := []struct {
+		name               string
+		modify             func(clouds []model.Cloud, apps []model.Application)
+		schedulingResult   []int
+		expectedViolations []model.Violation
+	}{
+		{
+			name:               "acceptable",
+			modify:             func(clouds []model.Cloud, apps []model.Application) {},
+			schedulingResult:   []int{0, 1, 0, 1, 2, 2, 1, 0},
+			expectedViolations: nil,
+		},
+		{
+			name: "memory of a service and a task",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				apps[0].SvcReq.Memory = 40 * gib
+				apps[1].TaskReq.Memory = 40 * gib
+			},
+			schedulingResult: []int{0, 0, 1, 3, 1, 1, 1, 1},
+			expectedViolations: []model.Violation{
+				{CloudIdx: 0, AppIdx: 0, Reason: model.RejectReasonMemory, Resource: "memory", DependAppIdx: -1, DependCloudIdx: -1, Required: 40 * gib, Available: 32 * gib},
+				{CloudIdx: 0, AppIdx: 1, Reason: model.RejectReasonMemory, Resource: "memory", DependAppIdx: -1, DependCloudIdx: -1, Required: 40 * gib, Available: 32 * gib}, // app 0 does not fit, so it does not take up memory
+			},
+		},
+		{
+			name: "gpu of a service and a task",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[0].Allocatable.GPU = model.AcceleratorResource{Count: 2, Memory: 16 * gib}
+				apps[0].SvcReq.GPU = model.AcceleratorResource{Count: 2, Memory: 8 * gib}
+				apps[1].TaskReq.GPU = model.AcceleratorResource{Count: 1, Memory: 12 * gib}
+			},
+			schedulingResult: []int{0, 0, 1, 3, 1, 1, 1, 1},
+			expectedViolations: []model.Violation{
+				{CloudIdx: 0, AppIdx: 1, Reason: model.RejectReasonGPU, Resource: "gpu", DependAppIdx: -1, DependCloudIdx: -1, Required: 1, Available: 0},
+				{CloudIdx: 0, AppIdx: 1, Reason: model.RejectReasonGPU, Resource: "gpuMemory", DependAppIdx: -1, DependCloudIdx: -1, Required: 12 * gib, Available: 8 * gib},
+			},
+		},
+		{
+			name: "scalar resources of a service and a task",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[0].Allocatable.Scalars = model.ScalarResources{"licenses": 2}
+				apps[0].SvcReq.Scalars = model.ScalarResources{"licenses": 2}
+				apps[1].TaskReq.Scalars = model.ScalarResources{"licenses": 1, "hugepages": 4}
+			},
+			schedulingResult: []int{0, 0, 1, 3, 1, 1, 1, 1},
+			expectedViolations: []model.Violation{
+				{CloudIdx: 0, AppIdx: 1, Reason: model.RejectReasonScalar, Resource: "hugepages", DependAppIdx: -1, DependCloudIdx: -1, Required: 4, Available: 0},
+				{CloudIdx: 0, AppIdx: 1, Reason: model.RejectReasonScalar, Resource: "licenses", DependAppIdx: -1, DependCloudIdx: -1, Required: 1, Available: 0},
+			},
+		},
+		{
+			name: "placement constraints",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[0].Name = "edge-0"
+				clouds[1].Labels = map[string]string{"region": "eu"}
+				apps[0].ForbiddenClouds = []string{"edge-0"}
+				apps[2].CloudSelector = map[string]string{"region": "us"}
+				apps[4].AntiAffinityGroup = "web"
+				apps[6].AntiAffinityGroup = "web"
+				apps[7].CoLocateWith = []int{5}
+			},
+			schedulingResult: []int{0, 1, 1, 0, 2, 1, 2, 0},
+			expectedViolations: []model.Violation{
+				{CloudIdx: 0, AppIdx: 0, Reason: model.RejectReasonPlacement, Resource: "cloud", DependAppIdx: -1, DependCloudIdx: -1},
+				{CloudIdx: 0, AppIdx: 7, Reason: model.RejectReasonCoLocation, Resource: "coLocation", DependAppIdx: 5, DependCloudIdx: 1},
+				{CloudIdx: 1, AppIdx: 2, Reason: model.RejectReasonPlacement, Resource: "cloud", DependAppIdx: -1, DependCloudIdx: -1},
+				{CloudIdx: 2, AppIdx: 6, Reason: model.RejectReasonAntiAffinity, Resource: "antiAffinity", DependAppIdx: 4, DependCloudIdx: 2},
+			},
+		},
+		{
+			name: "placement constraints are met",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[0].Name = "edge-0"
+				clouds[1].Labels = map[string]string{"region": "eu"}
+				apps[0].RequiredClouds = []string{"edge-0"}
+				apps[2].CloudSelector = map[string]string{"region": "eu"}
+				apps[4].AntiAffinityGroup = "web"
+				apps[6].AntiAffinityGroup = "web"
+				apps[7].CoLocateWith = []int{5}
+			},
+			schedulingResult:   []int{0, 1, 1, 0, 2, 1, 0, 1},
+			expectedViolations: nil,
+		},
+		{
+			name: "cpu of a service and a rejected dependency",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				apps[2].SvcReq.CPUClock = 25 // 10 cores, but cloud 2 only has 8
+			},
+			schedulingResult: []int{3, 1, 2, 0, 1, 1, 1, 1},
+			expectedViolations: []model.Violation{
+				{CloudIdx: 0, AppIdx: 3, Reason: model.RejectReasonDependencyRejected, Resource: "dependency", DependAppIdx: 0, DependCloudIdx: -1},
+				{CloudIdx: 2, AppIdx: 2, Reason: model.RejectReasonCPU, Resource: "cpu", DependAppIdx: -1, DependCloudIdx: -1, Required: 10, Available: 8},
+			},
+		},
+		{
+			name: "rtt and bandwidth of a dependency",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				apps[6].Depend = []model.Dependence{{AppIdx: 2, DownBw: 300, UpBw: 10, RTT: 20}}
+			},
+			schedulingResult: []int{0, 0, 1, 0, 0, 0, 2, 0},
+			expectedViolations: []model.Violation{
+				{CloudIdx: 2, AppIdx: 6, Reason: model.RejectReasonRTT, Resource: "rtt", DependAppIdx: 2, DependCloudIdx: 1, Required: 20, Available: 30},
+				{CloudIdx: 2, AppIdx: 6, Reason: model.RejectReasonBandwidth, Resource: "downBw", DependAppIdx: 2, DependCloudIdx: 1, Required: 300, Available: 200},
+			},
+		},
+		{
+			name: "budget of a service and a task",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[0].Price.CPUHour = 0.25
+				clouds[1].Price.CPUHour = 0.25
+				apps[0].Budget = 0.3 // 1.6 cores on cloud 0 cost 0.4 every hour
+				apps[2].Budget = 10
+				apps[1].Budget = 1 // 44 core-seconds on cloud 1
+			},
+			schedulingResult: []int{0, 1, 0, 1, 2, 2, 1, 0},
+			expectedViolations: []model.Violation{
+				{CloudIdx: 0, AppIdx: 0, Reason: model.RejectReasonBudget, Resource: "budget", DependAppIdx: -1, DependCloudIdx: -1, Required: 0.3, Available: 0.4},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		testCase.modify(clouds, apps)
+		violations := AcceptableViolations(clouds, apps, testCase.schedulingResult)
+		assert.Equal(t, testCase.expectedViolations, violations, fmt.Sprintf("%s: violations are not expected", testCase.name))
+		assert.Equal(t, len(testCase.expectedViolations) == 0, Acceptable(clouds, apps, testCase.schedulingResult), fmt.Sprintf("%s: Acceptable is not consistent", testCase.name))
+	}
+}
+
+func TestAcceptableViolationsConsistent(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	apps[0].SvcReq.Memory = 20 * 1024 * 1024 * 1024
+	apps[4].SvcReq.CPUClock = 40
+	apps[6].Depend = []model.Dependence{{AppIdx: 2, DownBw: 150, UpBw: 60, RTT: 25}, {AppIdx: 4, DownBw: 80, UpBw: 40, RTT: 40}}
+	r := NewRandom(1)
+	var acceptableCount int
+	for i := 0; i < 2000; i++ {
+		chromosome := make([]int, len(apps))
+		for j := 0; j < len(chromosome); j++ {
+			chromosome[j] = r.RandomInt(0, len(clouds))
+		}
+		acceptable := Acceptable(clouds, apps, chromosome)
+		if acceptable {
+			acceptableCount++
+		}
+		assert.Equal(t, acceptable, len(AcceptableViolations(clouds, apps, chromosome)) == 0, fmt.Sprintf("chromosome %v", chromosome))
+	}
+	assert.Greater(t, acceptableCount, 0, "no chromosome is acceptable, the test does not cover both cases")
+}
+
+func TestGPUIdleRate(t *testing.T) {
+	testCases := []struct {
+		name             string
+		cloudGPUs        []float64
+		schedulingResult []int
+		expected         float64
+	}{
+		{name: "case 1: no GPUs", cloudGPUs: []float64{0, 0, 0}, schedulingResult: []int{0, 0, 1, 1, 1, 1, 1, 1}, expected: 0},
+		{name: "case 2: all GPUs idle", cloudGPUs: []float64{4, 0, 4}, schedulingResult: []int{3, 0, 1, 1, 1, 1, 1, 1}, expected: 1},
+		{name: "case 3: some GPUs used", cloudGPUs: []float64{4, 0, 4}, schedulingResult: []int{0, 0, 1, 1, 1, 1, 1, 1}, expected: 5.0 / 8},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		for i := 0; i < len(clouds); i++ {
+			clouds[i].Capacity.GPU.Count = testCase.cloudGPUs[i]
+			clouds[i].Allocatable.GPU.Count = testCase.cloudGPUs[i]
+		}
+		apps[0].SvcReq.GPU.Count = 3
+		apps[1].TaskReq.GPU.Count = 1 // a task does not hold GPUs after the deployment
+		assert.InDelta(t, testCase.expected, GPUIdleRate(clouds, apps, testCase.schedulingResult), 1e-9, fmt.Sprintf("%s: wrong GPU idle rate", testCase.name))
+	}
+}
+
+func TestScalarIdleRate(t *testing.T) {
+	testCases := []struct {
+		name             string
+		resource         string
+		schedulingResult []int
+		expected         float64
+	}{
+		{name: "case 1: no such resource", resource: "hugepages", schedulingResult: []int{0, 0, 1, 1, 1, 1, 1, 1}, expected: 0},
+		{name: "case 2: all idle", resource: "licenses", schedulingResult: []int{3, 0, 1, 1, 1, 1, 1, 1}, expected: 1},
+		{name: "case 3: some used", resource: "licenses", schedulingResult: []int{0, 0, 1, 1, 1, 1, 1, 1}, expected: 7.0 / 10},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		clouds[0].Capacity.Scalars = model.ScalarResources{"licenses": 4}
+		clouds[0].Allocatable.Scalars = model.ScalarResources{"licenses": 4}
+		clouds[2].Capacity.Scalars = model.ScalarResources{"licenses": 6}
+		clouds[2].Allocatable.Scalars = model.ScalarResources{"licenses": 6}
+		apps[0].SvcReq.Scalars = model.ScalarResources{"licenses": 3}
+		apps[1].TaskReq.Scalars = model.ScalarResources{"licenses": 1} // a task does not hold scalar resources after the deployment
+		assert.InDelta(t, testCase.expected, ScalarIdleRate(clouds, apps, testCase.schedulingResult, testCase.resource), 1e-9, fmt.Sprintf("%s: wrong idle rate", testCase.name))
+		assert.Equal(t, 4.0, clouds[0].Allocatable.Scalars["licenses"], fmt.Sprintf("%s: the input clouds are modified", testCase.name))
+	}
+}
+
+func TestCost(t *testing.T) {
+	var gib float64 = 1024 * 1024 * 1024
+	testCases := []struct {
+		name             string
+		modify           func(clouds []model.Cloud, apps []model.Application)
+		schedulingResult []int
+		hours            float64
+		expected         float64
+	}{
+		{
+			name:             "case 1: no prices",
+			modify:           func(clouds []model.Cloud, apps []model.Application) {},
+			schedulingResult: []int{0, 1, 0, 1, 2, 2, 1, 0},
+			hours:            10,
+			expected:         0,
+		},
+		{
+			name: "case 2: a service",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[0].Price = model.CloudPrice{CPUHour: 0.05, MemoryGBHour: 0.01, StorageGBHour: 0.001}
+				clouds[0].Capacity.NetCondImage.PricePerGB = 0.1
+				clouds[0].Capacity.NetCondController.PricePerGB = 0.2
+			},
+			schedulingResult: []int{0, 3, 3, 3, 3, 3, 3, 3},
+			hours:            10,
+			expected:         10*1024*1024/gib*0.1 + 1*1024*1024/gib*0.2 + 10*(4/2.5*0.05+2*0.01+8*0.001),
+		},
+		{
+			name: "case 3: traffic of a dependency across clouds",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[1].Capacity.NetCondClouds[0].PricePerGB = 0.05
+				clouds[0].Capacity.NetCondClouds[1].PricePerGB = 0.02
+				apps[2].Depend = []model.Dependence{{AppIdx: 0, DownBw: 16, UpBw: 8}}
+			},
+			schedulingResult: []int{0, 3, 1, 3, 3, 3, 3, 3},
+			hours:            2,
+			expected:         2 * (16.0*3600/(8*1024)*0.05 + 8.0*3600/(8*1024)*0.02),
+		},
+		{
+			name: "case 4: the traffic on the same cloud is free",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[0].Capacity.NetCondClouds[0].PricePerGB = 0.05
+				apps[2].Depend = []model.Dependence{{AppIdx: 0, DownBw: 16, UpBw: 8}}
+			},
+			schedulingResult: []int{0, 3, 0, 3, 3, 3, 3, 3},
+			hours:            2,
+			expected:         0,
+		},
+		{
+			name: "case 5: a task is paid once",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[2].Price.CPUHour = 0.36
+			},
+			schedulingResult: []int{3, 2, 3, 3, 3, 3, 3, 3},
+			hours:            100,
+			expected:         110 / 2.5 / 3600 * 0.36,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		testCase.modify(clouds, apps)
+		assert.InDelta(t, testCase.expected, Cost(clouds, apps, testCase.schedulingResult, testCase.hours), 1e-9, fmt.Sprintf("%s: wrong cost", testCase.name))
+	}
+}
+
+func TestEnergyCarbon(t *testing.T) {
+	var period float64 = 1e6
+	testCases := []struct {
+		name             string
+		modify           func(clouds []model.Cloud, apps []model.Application)
+		schedulingResult []int
+		expectedWatts    float64 // constant power of the whole period
+		expectedJoules   float64 // energy besides the constant power
+	}{
+		{
+			name:             "case 1: idle clouds",
+			modify:           func(clouds []model.Cloud, apps []model.Application) {},
+			schedulingResult: []int{3, 3, 3, 3, 3, 3, 3, 3},
+			expectedWatts:    100,
+		},
+		{
+			name: "case 2: cores already taken up",
+			modify: func(clouds []model.Cloud, apps []model.Application) {
+				clouds[0].Allocatable.CPU.LogicalCores -= 4
+			},
+			schedulingResult: []int{3, 3, 3, 3, 3, 3, 3, 3},
+			expectedWatts:    100 + 4*10,
+		},
+		{
+			name:             "case 3: a task",
+			modify:           func(clouds []model.Cloud, apps []model.Application) {},
+			schedulingResult: []int{3, 0, 3, 3, 3, 3, 3, 3},
+			expectedWatts:    100,
+			expectedJoules:   110 / 2.5 * 10,
+		},
+		{
+			name:             "case 4: a task on a cloud without a power model",
+			modify:           func(clouds []model.Cloud, apps []model.Application) {},
+			schedulingResult: []int{3, 1, 3, 3, 3, 3, 3, 3},
+			expectedWatts:    100,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		clouds[0].Power = model.CloudPower{IdleWatts: 100, PeakWatts: 260, PUE: 1.5, CarbonIntensity: []model.CarbonIntensityPoint{{Time: 0, GramsPerKWh: 360}}} // 10 watts per core
+		testCase.modify(clouds, apps)
+		var expected float64 = 1.5 * (testCase.expectedWatts*period + testCase.expectedJoules)
+		assert.InDelta(t, expected, Energy(clouds, apps, testCase.schedulingResult, period), 1e-6, fmt.Sprintf("%s: wrong energy", testCase.name))
+		assert.InDelta(t, expected/3.6e6*360, Carbon(clouds, apps, testCase.schedulingResult, 0, period), 1e-9, fmt.Sprintf("%s: wrong carbon", testCase.name))
+	}
+
+	// a service draws power after it is stable
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	clouds[0].Power = model.CloudPower{IdleWatts: 100, PeakWatts: 260}
+	var schedulingResult []int = []int{0, 3, 3, 3, 3, 3, 3, 3}
+	var stableTime float64 = DescribeSolution(clouds, apps, model.Solution{SchedulingResult: schedulingResult}).Placements[0].StableTime
+	assert.InDelta(t, 100*period+4/2.5*10*(period-stableTime), Energy(clouds, apps, schedulingResult, period), 1e-6, "wrong energy of a service")
+
+	// the carbon intensity changes during the period
+	clouds[0].Power.CarbonIntensity = []model.CarbonIntensityPoint{{Time: 0, GramsPerKWh: 0}, {Time: 1000, GramsPerKWh: 3600}}
+	assert.InDelta(t, 100*500*3600/3.6e6, Carbon(clouds, apps, []int{3, 3, 3, 3, 3, 3, 3, 3}, 500, 1000), 1e-9, "wrong carbon with a changing intensity")
+}
@@ -1 +1,14 @@
 package algorithms
+
+// units for the costs
+const (
+	bytesPerGB     float64 = 1024 * 1024 * 1024 // memory, storage and data sizes are in Byte
+	mbPerGB        float64 = 8 * 1024           // bandwidths are in Mb/s
+	secondsPerHour float64 = 3600
+)
+
+// units for the energy
+const (
+	joulesPerKWh float64 = 3.6e6
+	cyclesPerGHz float64 = 1024 * 1024 * 1024 // CPUCycle / (BaseClock * cyclesPerGHz) is the core-seconds of a task
+)
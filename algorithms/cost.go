@@ -0,0 +1,98 @@
+package algorithms
+
+import "gogeneticwrsp/model"
+
+// appCosts calculates the cost of every accepted app with the prices of its cloud. cost is paid once, including the image pulling, the data input, and the whole execution of a task,
+// hourlyCost is paid every hour while a service runs. Both are 0 for rejected apps.
+// A service pays for its logical cores, memory, storage and GPUs every hour. A task pays for its CPU cycles, and for its memory, storage and GPUs from its start until its completion.
+// An app also pays for the traffic with the services it depends on, as if it always used the bandwidth it requires, and the traffic is free on the same cloud.
+func appCosts(clouds []model.Cloud, apps []model.Application, schedulingResult []int) (costs, hourlyCosts []float64) {
+	// only the cost of tasks needs the timing model
+	var timeApps []model.Application
+	for i := 0; i < len(apps); i++ {
+		if schedulingResult[i] != len(clouds) && apps[i].IsTask {
+			var deployedClouds []model.Cloud = SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+			timeApps = CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+			break
+		}
+	}
+	return appCostsAt(clouds, apps, timeApps, schedulingResult)
+}
+
+// appCostsAt is appCosts with the start and completion times of the tasks taken from timeApps, which is only read when a task is accepted
+func appCostsAt(clouds []model.Cloud, apps, timeApps []model.Application, schedulingResult []int) (costs, hourlyCosts []float64) {
+	costs, hourlyCosts = make([]float64, len(apps)), make([]float64, len(apps))
+	var replicaSets [][]int = model.ReplicaSets(apps)
+	for appIdx := 0; appIdx < len(apps); appIdx++ {
+		cloudIdx := schedulingResult[appIdx]
+		if cloudIdx == len(clouds) {
+			continue
+		}
+		var cloud model.Cloud = clouds[cloudIdx]
+		var app model.Application = apps[appIdx]
+		var price model.CloudPrice = cloud.Price
+
+		// an old app does not pull its image or input its data again on the same cloud
+		if !(!app.IsNew && app.ImagePullDone && cloudIdx == app.CloudRemainingOn) {
+			costs[appIdx] += app.ImageSize / bytesPerGB * cloud.Capacity.NetCondImage.PricePerGB
+		}
+		if !(!app.IsNew && app.AlreadyStable && cloudIdx == app.CloudRemainingOn) {
+			costs[appIdx] += app.InputDataSize / bytesPerGB * cloud.Capacity.NetCondController.PricePerGB
+		}
+
+		// the resources held and the traffic with dependent services in one hour
+		var hourly float64
+		if app.IsTask {
+			hourly = app.TaskReq.Memory/bytesPerGB*price.MemoryGBHour + app.TaskReq.Storage/bytesPerGB*price.StorageGBHour + app.TaskReq.GPU.Count*price.GPUHour
+		} else {
+			hourly = app.SvcReq.CPUClock/cloud.Capacity.CPU.BaseClock*price.CPUHour + app.SvcReq.Memory/bytesPerGB*price.MemoryGBHour + app.SvcReq.Storage/bytesPerGB*price.StorageGBHour + app.SvcReq.GPU.Count*price.GPUHour
+		}
+		for _, dependence := range app.Depend {
+			servingCloudIdx := servingCloud(clouds, replicaSets, schedulingResult, dependence.AppIdx, cloudIdx)
+			if servingCloudIdx == len(clouds) || servingCloudIdx == cloudIdx {
+				continue
+			}
+			hourly += dependence.DownBw * secondsPerHour / mbPerGB * cloud.Capacity.NetCondClouds[servingCloudIdx].PricePerGB
+			hourly += dependence.UpBw * secondsPerHour / mbPerGB * clouds[servingCloudIdx].Capacity.NetCondClouds[cloudIdx].PricePerGB
+		}
+
+		if !app.IsTask {
+			hourlyCosts[appIdx] = hourly
+			continue
+		}
+		var execHours float64 = (timeApps[appIdx].TaskCompletionTime - timeApps[appIdx].StartTime) / secondsPerHour
+		var coreHours float64 = app.TaskReq.CPUCycle / (cloud.Capacity.CPU.BaseClock * cyclesPerGHz) / secondsPerHour
+		costs[appIdx] += hourly*execHours + coreHours*price.CPUHour
+	}
+	return costs, hourlyCosts
+}
+
+// budgetViolations returns the accepted apps that cost more than their budgets, it only calculates the costs when such apps exist
+func budgetViolations(clouds []model.Cloud, apps []model.Application, schedulingResult []int) []model.Violation {
+	var hasBudget bool
+	for i := 0; i < len(apps); i++ {
+		if schedulingResult[i] != len(clouds) && apps[i].Budget > 0 {
+			hasBudget = true
+			break
+		}
+	}
+	if !hasBudget {
+		return nil
+	}
+
+	var violations []model.Violation
+	costs, hourlyCosts := appCosts(clouds, apps, schedulingResult)
+	for i := 0; i < len(apps); i++ {
+		if schedulingResult[i] == len(clouds) || apps[i].Budget <= 0 {
+			continue
+		}
+		var cost float64 = hourlyCosts[i]
+		if apps[i].IsTask {
+			cost = costs[i]
+		}
+		if cost > apps[i].Budget {
+			violations = append(violations, model.Violation{CloudIdx: schedulingResult[i], AppIdx: i, Reason: model.RejectReasonBudget, Resource: "budget", DependAppIdx: -1, DependCloudIdx: -1, Required: apps[i].Budget, Available: cost})
+		}
+	}
+	return violations
+}
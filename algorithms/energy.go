@@ -0,0 +1,66 @@
+package algorithms
+
+import "gogeneticwrsp/model"
+
+// powerInterval is a constant power drawn by a cloud from From to To, unit watt and second
+type powerInterval struct {
+	From, To float64
+	Watts    float64
+}
+
+// powerIntervals returns the power drawn by every cloud from time 0 until period (unit second) with the timing model, before multiplied by PUE.
+// A cloud always draws its idle power, and every busy logical core adds (PeakWatts - IdleWatts) / LogicalCores.
+// The logical cores already taken up in the given clouds (Capacity - Allocatable) are busy during the whole period, and the accepted apps add appPowerIntervals.
+func powerIntervals(clouds []model.Cloud, apps []model.Application, schedulingResult []int, period float64) [][]powerInterval {
+	var deployedClouds []model.Cloud = SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+	var timeApps []model.Application = CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+
+	var intervals [][]powerInterval = make([][]powerInterval, len(clouds))
+	for i := 0; i < len(clouds); i++ {
+		var busyCores float64 = clouds[i].Capacity.CPU.LogicalCores - clouds[i].Allocatable.CPU.LogicalCores
+		intervals[i] = append(intervals[i], powerInterval{From: 0, To: period, Watts: clouds[i].Power.IdleWatts + busyCores*wattsPerCore(clouds[i])})
+	}
+	for i, appIntervals := range appPowerIntervals(clouds, apps, timeApps, schedulingResult, period) {
+		intervals[i] = append(intervals[i], appIntervals...)
+	}
+	return intervals
+}
+
+// appPowerIntervals returns the power added to every cloud by the accepted apps from time 0 until period (unit second), with the start and completion times taken from timeApps.
+// An accepted service keeps its logical cores busy after it is stable, and an accepted task spreads its core-seconds evenly from its stable time to its completion. The CPU cycles of startup are ignored.
+func appPowerIntervals(clouds []model.Cloud, apps, timeApps []model.Application, schedulingResult []int, period float64) [][]powerInterval {
+	var intervals [][]powerInterval = make([][]powerInterval, len(clouds))
+	for appIdx := 0; appIdx < len(apps); appIdx++ {
+		cloudIdx := schedulingResult[appIdx]
+		if cloudIdx == len(clouds) {
+			continue
+		}
+		var baseClock float64 = clouds[cloudIdx].Capacity.CPU.BaseClock
+		var from float64 = timeApps[appIdx].StableTime
+		if !apps[appIdx].IsTask {
+			if from < period {
+				intervals[cloudIdx] = append(intervals[cloudIdx], powerInterval{From: from, To: period, Watts: apps[appIdx].SvcReq.CPUClock / baseClock * wattsPerCore(clouds[cloudIdx])})
+			}
+			continue
+		}
+		var coreSeconds float64 = apps[appIdx].TaskReq.CPUCycle / (baseClock * cyclesPerGHz)
+		var to float64 = timeApps[appIdx].TaskCompletionTime
+		if to <= from || from >= period {
+			continue
+		}
+		var watts float64 = coreSeconds / (to - from) * wattsPerCore(clouds[cloudIdx])
+		if to > period {
+			to = period
+		}
+		intervals[cloudIdx] = append(intervals[cloudIdx], powerInterval{From: from, To: to, Watts: watts})
+	}
+	return intervals
+}
+
+// wattsPerCore returns the power added by one busy logical core of cloud, unit watt
+func wattsPerCore(cloud model.Cloud) float64 {
+	if cloud.Capacity.CPU.LogicalCores <= 0 {
+		return 0
+	}
+	return (cloud.Power.PeakWatts - cloud.Power.IdleWatts) / cloud.Capacity.CPU.LogicalCores
+}
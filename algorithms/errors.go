@@ -0,0 +1,94 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"gogeneticwrsp/model"
+)
+
+var (
+	// ErrDirtyTimeState is matched by errors.Is for every DirtyTimeStateError
+	ErrDirtyTimeState = errors.New("dirty time state")
+	// ErrNoAcceptableSolution is matched by errors.Is for every NoAcceptableSolutionError
+	ErrNoAcceptableSolution = errors.New("no acceptable solution")
+	// ErrCloudsMismatch means that two slices describing the same clouds have different lengths
+	ErrCloudsMismatch = errors.New("clouds mismatch")
+	// ErrUnknownObjective means that an objective name is not in the objectives of a solution
+	ErrUnknownObjective = errors.New("unknown objective")
+	// ErrEmptyParetoFront means that no point on the Pareto front can be chosen
+	ErrEmptyParetoFront = errors.New("empty Pareto front")
+	// ErrUnknownScheduler means that no scheduler is registered under a name
+	ErrUnknownScheduler = errors.New("unknown scheduler")
+	// ErrInvalidParams means that the parameters of a scheduler cannot be decoded or have invalid values
+	ErrInvalidParams = errors.New("invalid scheduler params")
+	// ErrBrokenInvariant is matched by errors.Is for every BrokenInvariantError
+	ErrBrokenInvariant = errors.New("broken scheduler invariant")
+)
+
+// DirtyTimeStateError means that an app passed to Schedule already has a time attribute, i.e., it has been used in another calculation
+type DirtyTimeStateError struct {
+	AppIdx int     // index of the dirty app
+	Field  string  // name of the time attribute that is not 0
+	Value  float64 // value of this attribute
+}
+
+func (e *DirtyTimeStateError) Error() string {
+	return fmt.Sprintf("%s: apps[%d].%s is %g", ErrDirtyTimeState.Error(), e.AppIdx, e.Field, e.Value)
+}
+
+// Is makes errors.Is(err, ErrDirtyTimeState) true
+func (e *DirtyTimeStateError) Is(target error) bool {
+	return target == ErrDirtyTimeState
+}
+
+// NoAcceptableSolutionError means that a scheduler stopped without finding any acceptable solution
+type NoAcceptableSolutionError struct {
+	Iterations int   // number of iterations that have been searched
+	Cause      error // why the search stopped early, e.g., ctx.Err(), nil if all iterations are finished
+}
+
+func (e *NoAcceptableSolutionError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s is found before the scheduling is stopped: %s", ErrNoAcceptableSolution.Error(), e.Cause.Error())
+	}
+	return fmt.Sprintf("%s is found in %d iterations", ErrNoAcceptableSolution.Error(), e.Iterations)
+}
+
+// Is makes errors.Is(err, ErrNoAcceptableSolution) true
+func (e *NoAcceptableSolutionError) Is(target error) bool {
+	return target == ErrNoAcceptableSolution
+}
+
+// Unwrap returns the cause, so that errors.Is(err, context.DeadlineExceeded) also works
+func (e *NoAcceptableSolutionError) Unwrap() error {
+	return e.Cause
+}
+
+// BrokenInvariantError means that a scheduler reached a state that its operators should never produce, which is a bug in the scheduler rather than invalid input
+type BrokenInvariantError struct {
+	Iteration int    // iteration in which the invariant is broken
+	Reason    string // which invariant is broken
+}
+
+func (e *BrokenInvariantError) Error() string {
+	return fmt.Sprintf("%s in iteration %d: %s", ErrBrokenInvariant.Error(), e.Iteration, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrBrokenInvariant) true
+func (e *BrokenInvariantError) Is(target error) bool {
+	return target == ErrBrokenInvariant
+}
+
+// checkTimeState makes sure that all time attributes of each app are 0, because the schedulers calculate them from 0
+func checkTimeState(apps []model.Application) error {
+	for i := 0; i < len(apps); i++ {
+		var fields []string = []string{"StartTime", "ImagePullDoneTime", "DataInputDoneTime", "StableTime", "TaskCompletionTime"}
+		var values []float64 = []float64{apps[i].StartTime, apps[i].ImagePullDoneTime, apps[i].DataInputDoneTime, apps[i].StableTime, apps[i].TaskCompletionTime}
+		for j := 0; j < len(fields); j++ {
+			if values[j] != 0 {
+				return &DirtyTimeStateError{AppIdx: i, Field: fields[j], Value: values[j]}
+			}
+		}
+	}
+	return nil
+}
@@ -35,6 +35,68 @@ func StorageIdleRate(clouds []model.Cloud, apps []model.Application, schedulingR
 	return idleStorage / totalStorage
 }
 
+// GPUIdleRate calculates the GPU idle rate according to given clouds, apps, schedulingResult, it is 0 if the clouds have no GPUs
+func GPUIdleRate(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+	var deployedClouds []model.Cloud = TrulyDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+	var idleGPU, totalGPU float64
+	for i := 0; i < len(clouds); i++ {
+		idleGPU += deployedClouds[i].Allocatable.GPU.Count
+		totalGPU += deployedClouds[i].Capacity.GPU.Count
+	}
+	if totalGPU == 0 {
+		return 0
+	}
+	return idleGPU / totalGPU
+}
+
+// ScalarIdleRate calculates the idle rate of the scalar resource name according to given clouds, apps, schedulingResult, it is 0 if the clouds have none of this resource
+func ScalarIdleRate(clouds []model.Cloud, apps []model.Application, schedulingResult []int, name string) float64 {
+	var deployedClouds []model.Cloud = TrulyDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+	var idle, total float64
+	for i := 0; i < len(clouds); i++ {
+		idle += deployedClouds[i].Allocatable.Scalars[name]
+		total += deployedClouds[i].Capacity.Scalars[name]
+	}
+	if total == 0 {
+		return 0
+	}
+	return idle / total
+}
+
+// Cost calculates the cost of running the accepted apps for hours hours according to given clouds, apps, schedulingResult, with the prices of the clouds.
+// The tasks and the one-time costs of the services are paid once, and the services are paid every hour.
+func Cost(clouds []model.Cloud, apps []model.Application, schedulingResult []int, hours float64) float64 {
+	costs, hourlyCosts := appCosts(clouds, apps, schedulingResult)
+	var cost float64
+	for i := 0; i < len(apps); i++ {
+		cost += costs[i] + hourlyCosts[i]*hours
+	}
+	return cost
+}
+
+// Energy calculates the energy consumed by the clouds from the beginning of the schedule until period seconds according to given clouds, apps, schedulingResult, with the power models of the clouds, including PUE, unit joule.
+func Energy(clouds []model.Cloud, apps []model.Application, schedulingResult []int, period float64) float64 {
+	var energy float64
+	for cloudIdx, intervals := range powerIntervals(clouds, apps, schedulingResult, period) {
+		for _, interval := range intervals {
+			energy += interval.Watts * (interval.To - interval.From) * clouds[cloudIdx].Power.EffectivePUE()
+		}
+	}
+	return energy
+}
+
+// Carbon calculates the carbon emissions of Energy with the carbon intensity curves of the clouds, unit gram CO2-equivalent.
+// The schedule begins at start on the curves, unit second since the beginning of the simulation.
+func Carbon(clouds []model.Cloud, apps []model.Application, schedulingResult []int, start, period float64) float64 {
+	var carbon float64
+	for cloudIdx, intervals := range powerIntervals(clouds, apps, schedulingResult, period) {
+		for _, interval := range intervals {
+			carbon += interval.Watts * clouds[cloudIdx].Power.EffectivePUE() * clouds[cloudIdx].Power.CarbonIntegral(start+interval.From, start+interval.To) / joulesPerKWh
+		}
+	}
+	return carbon
+}
+
 // BwIdleRate calculates the Bandwidth idle rate according to given clouds, apps, schedulingResult
 func BwIdleRate(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
 	var deployedClouds []model.Cloud = TrulyDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
@@ -102,3 +164,24 @@ func AcceptedTaskPriRate(clouds []model.Cloud, apps []model.Application, schedul
 	}
 	return float64(acceptedPriority) / float64(totalPriority)
 }
+
+// DeadlineHitPriRate calculates the priority rate of the apps with deadlines (Deadline of tasks and MaxStartupTime of services) that are accepted and finish in time,
+// according to given clouds, apps, schedulingResult. Rejected apps miss their deadlines. If no app has a deadline, it returns 1.
+func DeadlineHitPriRate(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+	var deployedClouds []model.Cloud = SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+	var timeApps []model.Application = CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+	var hitPriority, totalPriority uint64
+	for i := 0; i < len(timeApps); i++ {
+		if _, hasDue := timeApps[i].Due(); !hasDue {
+			continue
+		}
+		if schedulingResult[i] != len(clouds) && timeApps[i].Lateness() == 0 {
+			hitPriority += uint64(timeApps[i].Priority)
+		}
+		totalPriority += uint64(timeApps[i].Priority)
+	}
+	if totalPriority == 0 {
+		return 1
+	}
+	return float64(hitPriority) / float64(totalPriority)
+}
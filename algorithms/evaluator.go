@@ -0,0 +1,95 @@
+package algorithms
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Evaluation is the fitness and the acceptability of a chromosome
+type Evaluation struct {
+	Fitness    float64   // scalar fitness value, used by the selection of single-objective algorithms
+	Objectives []float64 // fitness values of every objective, used by multi-objective algorithms
+	Acceptable bool      // the result of Acceptable on this chromosome
+}
+
+// FitnessEvaluator evaluates the chromosomes of one generation on a pool of goroutines.
+// Chromosomes that appear more than once in a generation are only evaluated once.
+// Every evaluation is written to the index of its chromosome, so the results do not depend on the scheduling of goroutines.
+type FitnessEvaluator struct {
+	WorkerNum int // number of goroutines that evaluate chromosomes
+}
+
+// NewFitnessEvaluator creates an evaluator with workerNum goroutines, workerNum <= 0 means one goroutine per logical CPU
+func NewFitnessEvaluator(workerNum int) *FitnessEvaluator {
+	if workerNum <= 0 {
+		workerNum = runtime.NumCPU()
+	}
+	return &FitnessEvaluator{
+		WorkerNum: workerNum,
+	}
+}
+
+// EvaluatePopulation evaluates every chromosome in population with evalFunc, and returns the evaluations in the order of population.
+// The cache only lives during one call, because fitness functions may change between generations (e.g., RejectExecTime).
+// evalFunc is called concurrently, so it must not modify shared states.
+func (fe *FitnessEvaluator) EvaluatePopulation(population Population, evalFunc func(Chromosome) Evaluation) []Evaluation {
+	// find the distinct chromosomes in this generation
+	var distinctIdx map[string]int = make(map[string]int) // chromosome key -> index in distinct
+	var distinct []int                                    // index in population of every distinct chromosome
+	var owner []int = make([]int, len(population))        // index in distinct of every chromosome in population
+	for i := 0; i < len(population); i++ {
+		key := chromosomeKey(population[i])
+		if idx, exist := distinctIdx[key]; exist {
+			owner[i] = idx
+			continue
+		}
+		distinctIdx[key] = len(distinct)
+		owner[i] = len(distinct)
+		distinct = append(distinct, i)
+	}
+
+	// evaluate the distinct chromosomes on the worker pool
+	var distinctEvaluations []Evaluation = make([]Evaluation, len(distinct))
+	workerNum := fe.WorkerNum
+	if workerNum <= 0 {
+		workerNum = 1
+	}
+	if workerNum > len(distinct) {
+		workerNum = len(distinct)
+	}
+	var jobs chan int = make(chan int, len(distinct))
+	for i := 0; i < len(distinct); i++ {
+		jobs <- i
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for w := 0; w < workerNum; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				distinctEvaluations[idx] = evalFunc(population[distinct[idx]])
+			}
+		}()
+	}
+	wg.Wait()
+
+	// every chromosome gets the evaluation of its distinct one
+	var evaluations []Evaluation = make([]Evaluation, len(population))
+	for i := 0; i < len(population); i++ {
+		evaluations[i] = distinctEvaluations[owner[i]]
+	}
+	return evaluations
+}
+
+// chromosomeKey is the cache key of a chromosome
+func chromosomeKey(chromosome Chromosome) string {
+	var b strings.Builder
+	for i := 0; i < len(chromosome); i++ {
+		b.WriteString(strconv.Itoa(chromosome[i]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
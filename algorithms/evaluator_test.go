@@ -0,0 +1,61 @@
+package algorithms
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluatePopulation(t *testing.T) {
+	testCases := []struct {
+		name          string
+		workerNum     int
+		population    Population
+		expectedCalls int32
+	}{
+		{
+			name:          "one worker",
+			workerNum:     1,
+			population:    Population{{0, 1, 2}, {2, 1, 0}, {1, 1, 1}},
+			expectedCalls: 3,
+		},
+		{
+			name:          "more workers than chromosomes",
+			workerNum:     16,
+			population:    Population{{0, 1, 2}, {2, 1, 0}, {1, 1, 1}},
+			expectedCalls: 3,
+		},
+		{
+			name:          "duplicated chromosomes",
+			workerNum:     4,
+			population:    Population{{0, 1, 2}, {0, 1, 2}, {3, 3, 3}, {0, 1, 2}, {3, 3, 3}},
+			expectedCalls: 2,
+		},
+		{
+			name:          "empty population",
+			workerNum:     4,
+			population:    Population{},
+			expectedCalls: 0,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		var calls int32
+		evalFunc := func(chromosome Chromosome) Evaluation {
+			atomic.AddInt32(&calls, 1)
+			var sum float64
+			for _, gene := range chromosome {
+				sum = sum*10 + float64(gene)
+			}
+			return Evaluation{Fitness: sum, Acceptable: chromosome[0] != 3}
+		}
+		evaluations := NewFitnessEvaluator(testCase.workerNum).EvaluatePopulation(testCase.population, evalFunc)
+		assert.Equal(t, testCase.expectedCalls, calls, fmt.Sprintf("%s: wrong number of evaluations", testCase.name))
+		assert.Equal(t, len(testCase.population), len(evaluations), fmt.Sprintf("%s: wrong number of results", testCase.name))
+		for i, chromosome := range testCase.population {
+			assert.Equal(t, evalFunc(chromosome), evaluations[i], fmt.Sprintf("%s: chromosome %d has a wrong evaluation", testCase.name, i))
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package algorithms
+
+import "gogeneticwrsp/model"
+
+// EvictFailedApps restarts the remaining apps affected by the failures beginning after lastTime and until now (unit second since the beginning of the simulation). remainingApps is from CalcRemainingApps at now.
+// An app is affected if its cloud fails, or it depends on an app across a failed link. The apps that cannot be migrated and depend on, or are co-located with, a restarted app,
+// and all replicas of a restarted service, are also restarted, because otherwise they cannot be accepted.
+// A restarted app loses its progress and is scheduled like a new app, so it can be rejected, which means it is lost.
+// It returns the restarted apps mapped to how long they have been out of service until now.
+func EvictFailedApps(remainingApps []model.Application, failures []model.CloudFailure, lastTime, now float64) map[int]float64 {
+	var evicted map[int]float64 = make(map[int]float64)
+	var evict func(appIdx int, downTime float64) = func(appIdx int, downTime float64) {
+		if old, exist := evicted[appIdx]; !exist || downTime > old {
+			evicted[appIdx] = downTime
+		}
+	}
+
+	for _, failure := range failures {
+		if failure.Start <= lastTime || failure.Start > now {
+			continue
+		}
+		var downTime float64 = now - failure.Start
+		for i := 0; i < len(remainingApps); i++ {
+			var cloudIdx int = remainingApps[i].CloudRemainingOn
+			if failure.Kind != model.FailureLink {
+				if cloudIdx == failure.CloudIdx {
+					evict(i, downTime)
+				}
+				continue
+			}
+			for _, dependence := range remainingApps[i].Depend {
+				if dependence.AppIdx < 0 || dependence.AppIdx >= len(remainingApps) {
+					continue
+				}
+				var dependCloudIdx int = remainingApps[dependence.AppIdx].CloudRemainingOn
+				if (cloudIdx == failure.CloudIdx && dependCloudIdx == failure.PeerCloudIdx) || (cloudIdx == failure.PeerCloudIdx && dependCloudIdx == failure.CloudIdx) {
+					evict(i, downTime)
+					break
+				}
+			}
+		}
+	}
+
+	// restarting an app can break the constraints of other remaining apps, so the eviction spreads until no more apps are affected
+	var replicaSets [][]int = model.ReplicaSets(remainingApps)
+	for changed := len(evicted) > 0; changed; {
+		changed = false
+		var spread func(appIdx int, downTime float64) = func(appIdx int, downTime float64) {
+			if _, exist := evicted[appIdx]; !exist {
+				evicted[appIdx] = downTime
+				changed = true
+			}
+		}
+		for i := 0; i < len(remainingApps); i++ {
+			downTime, exist := evicted[i]
+			if exist && replicaSets != nil {
+				for _, replicaIdx := range replicaSets[i] {
+					spread(replicaIdx, downTime)
+				}
+			}
+			if exist || remainingApps[i].CanMigrate {
+				continue
+			}
+			for _, dependence := range remainingApps[i].Depend {
+				if downTime, exist := evicted[dependence.AppIdx]; exist {
+					spread(i, downTime)
+				}
+			}
+			for _, coLocatedIdx := range remainingApps[i].CoLocateWith {
+				if downTime, exist := evicted[coLocatedIdx]; exist {
+					spread(i, downTime)
+				}
+			}
+		}
+	}
+
+	for appIdx := range evicted {
+		restartApp(&remainingApps[appIdx])
+	}
+	return evicted
+}
+
+// restartApp makes a remaining app start again from scratch on any cloud
+func restartApp(app *model.Application) {
+	app.IsNew = true
+	app.CanMigrate = true
+	app.ImagePullDone = false
+	app.AlreadyStable = false
+	if app.IsTask { // the executed CPU cycles are lost
+		app.TaskReq.CPUCycle += app.ExecutedCPUCycle
+		app.ExecutedCPUCycle = 0
+	}
+}
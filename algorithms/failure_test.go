@@ -0,0 +1,72 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/model"
+)
+
+// remaining apps on 3 clouds: app 0 is a task executing on cloud 0, app 2 is a task executing on cloud 1 and depends on app 3 on cloud 0, so app 3 cannot be migrated,
+// app 4 depends on app 3 but can be migrated, app 5 is not related, apps 6 and 7 are the replicas of a service on cloud 0 and cloud 2
+func forTestRemainingApps() []model.Application {
+	return []model.Application{
+		{AppIdx: 0, IsTask: true, CloudRemainingOn: 0, AlreadyStable: true, ImagePullDone: true, TaskReq: model.TaskResources{CPUCycle: 70}, ExecutedCPUCycle: 30},
+		{AppIdx: 1, CloudRemainingOn: 1, CanMigrate: true},
+		{AppIdx: 2, IsTask: true, CloudRemainingOn: 1, AlreadyStable: true, TaskReq: model.TaskResources{CPUCycle: 10}, ExecutedCPUCycle: 90, Depend: []model.Dependence{{AppIdx: 3}}},
+		{AppIdx: 3, CloudRemainingOn: 0, AlreadyStable: true},
+		{AppIdx: 4, CloudRemainingOn: 2, CanMigrate: true, Depend: []model.Dependence{{AppIdx: 3}}},
+		{AppIdx: 5, CloudRemainingOn: 2, CanMigrate: true},
+		{AppIdx: 6, CloudRemainingOn: 0, CanMigrate: true, Replicas: 2},
+		{AppIdx: 7, CloudRemainingOn: 2, CanMigrate: true, Replicas: 2, ReplicaIdx: 1, ReplicaOf: 6},
+	}
+}
+
+func TestEvictFailedApps(t *testing.T) {
+	testCases := []struct {
+		name     string
+		failures []model.CloudFailure
+		expected map[int]float64
+	}{
+		{
+			name:     "case 1: cloud 0 down",
+			failures: []model.CloudFailure{{CloudIdx: 0, Start: 130, End: 300, Factor: 0}},
+			expected: map[int]float64{0: 20, 2: 20, 3: 20, 6: 20, 7: 20},
+		},
+		{
+			name:     "case 2: failed before last round",
+			failures: []model.CloudFailure{{CloudIdx: 0, Start: 90, End: 300, Factor: 0}},
+			expected: map[int]float64{},
+		},
+		{
+			name:     "case 3: link from cloud 0 to cloud 1 halved",
+			failures: []model.CloudFailure{{Kind: model.FailureLink, CloudIdx: 0, PeerCloudIdx: 1, Start: 110, Factor: 0.5}},
+			expected: map[int]float64{2: 40},
+		},
+		{
+			name:     "case 4: cloud 2 degraded, then cloud 1 down",
+			failures: []model.CloudFailure{{CloudIdx: 2, Start: 140, Factor: 0.5}, {CloudIdx: 1, Start: 145, Factor: 0}},
+			expected: map[int]float64{1: 5, 2: 5, 4: 10, 5: 10, 6: 10, 7: 10},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		remainingApps := forTestRemainingApps()
+		actual := EvictFailedApps(remainingApps, testCase.failures, 100, 150)
+		assert.Equal(t, testCase.expected, actual, fmt.Sprintf("%s: evicted apps not as expected", testCase.name))
+		for i := 0; i < len(remainingApps); i++ {
+			_, evicted := testCase.expected[i]
+			assert.Equal(t, evicted, remainingApps[i].IsNew, fmt.Sprintf("%s: app %d should be new: %t", testCase.name, i, evicted))
+		}
+	}
+
+	// a restarted task loses its executed CPU cycles
+	remainingApps := forTestRemainingApps()
+	EvictFailedApps(remainingApps, []model.CloudFailure{{CloudIdx: 0, Start: 130, Factor: 0}}, 100, 150)
+	assert.Equal(t, 100.0, remainingApps[0].TaskReq.CPUCycle, "the task should restart from scratch")
+	assert.Equal(t, 0.0, remainingApps[0].ExecutedCPUCycle, "the task should restart from scratch")
+	assert.True(t, remainingApps[0].CanMigrate && !remainingApps[0].AlreadyStable && !remainingApps[0].ImagePullDone, "the task should be able to start on any cloud")
+}
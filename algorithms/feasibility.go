@@ -0,0 +1,336 @@
+package algorithms
+
+import (
+	"gogeneticwrsp/model"
+	"sort"
+)
+
+// FeasibilityState keeps the result of Acceptable for a scheduling result that changes one app at a time.
+// Acceptable checks clouds one by one, and the check of a cloud only depends on:
+// 1. the apps deployed on it and the clouds of their dependent (or the replicas of them) and co-located apps;
+// 2. the bandwidth left by the clouds checked before it.
+// So after an app is moved, only its old and new clouds, the clouds of the apps depending on it, and the clouds whose left bandwidth changes are checked again.
+// The result of state.Acceptable() is always the same as Acceptable(clouds, apps, state.SchedulingResult()).
+type FeasibilityState struct {
+	clouds           []model.Cloud
+	apps             []model.Application
+	schedulingResult []int
+
+	runningApps   [][]model.Application // apps already running on each cloud before this scheduling, Acceptable also checks them
+	cloudApps     [][]int               // indexes of the apps deployed on each cloud, in ascending order, the same as the order in SimulateDeploy
+	dependents    [][]int               // dependents[i] are the apps that depend on or must be co-located with apps[i]
+	runningDepend [][]int               // runningDepend[i] are the clouds with running apps that depend on or must be co-located with apps[i]
+
+	hardDeadline bool    // whether any app has a hard deadline, which is checked on the whole scheduling result after all clouds are ok
+	budget       bool    // whether any app has a budget, the cost of a task also depends on the timing, so budgets are checked like hard deadlines
+	replicaSets  [][]int // from model.ReplicaSets, the replicas of a service are also checked on the whole scheduling result
+
+	cloudOK   []bool      // the check result of each cloud
+	badClouds int         // number of clouds that are not ok
+	leftDown  [][]float64 // leftDown[c][d] is TmpAlloc.NetCondClouds[d].DownBw of cloud c after checking cloud c
+	dirty     []bool      // clouds that need to be checked again
+
+	// buffers reused by checkCloud
+	sortBuf      appPtrSlice
+	up           []float64
+	upSet        []bool
+	antiAffinity map[string]struct{}
+}
+
+// appPtrSlice sorts application pointers with the same Less as model.AppSlice, so sort.Sort gives the same order
+type appPtrSlice []*model.Application
+
+func (as appPtrSlice) Len() int {
+	return len(as)
+}
+
+func (as appPtrSlice) Swap(i, j int) {
+	as[i], as[j] = as[j], as[i]
+}
+
+func (as appPtrSlice) Less(i, j int) bool {
+	return as[i].Priority > as[j].Priority
+}
+
+// NewFeasibilityState checks schedulingResult once, and then keeps the result up to date when apps are moved
+func NewFeasibilityState(clouds []model.Cloud, apps []model.Application, schedulingResult []int) *FeasibilityState {
+	fs := &FeasibilityState{
+		clouds:           clouds,
+		apps:             apps,
+		schedulingResult: make([]int, len(schedulingResult)),
+		runningApps:      make([][]model.Application, len(clouds)),
+		cloudApps:        make([][]int, len(clouds)),
+		dependents:       make([][]int, len(apps)),
+		runningDepend:    make([][]int, len(apps)),
+		cloudOK:          make([]bool, len(clouds)),
+		leftDown:         make([][]float64, len(clouds)),
+		dirty:            make([]bool, len(clouds)),
+		up:               make([]float64, len(clouds)),
+		upSet:            make([]bool, len(clouds)),
+		antiAffinity:     make(map[string]struct{}),
+		replicaSets:      model.ReplicaSets(apps),
+	}
+	copy(fs.schedulingResult, schedulingResult)
+
+	for i := 0; i < len(apps); i++ {
+		if apps[i].HardDeadline() {
+			fs.hardDeadline = true
+		}
+		if apps[i].Budget > 0 {
+			fs.budget = true
+		}
+		for _, dependence := range apps[i].Depend {
+			for _, replicaIdx := range fs.servedBy(dependence.AppIdx) {
+				fs.dependents[replicaIdx] = append(fs.dependents[replicaIdx], i)
+			}
+		}
+		for _, coLocateIdx := range apps[i].CoLocateWith {
+			fs.dependents[coLocateIdx] = append(fs.dependents[coLocateIdx], i)
+		}
+	}
+	for c := 0; c < len(clouds); c++ {
+		fs.runningApps[c] = model.AppsCopy(clouds[c].RunningApps)
+		for _, runningApp := range fs.runningApps[c] {
+			for _, dependence := range runningApp.Depend {
+				if dependence.AppIdx >= 0 && dependence.AppIdx < len(apps) {
+					for _, replicaIdx := range fs.servedBy(dependence.AppIdx) {
+						fs.runningDepend[replicaIdx] = append(fs.runningDepend[replicaIdx], c)
+					}
+				}
+			}
+			for _, coLocateIdx := range runningApp.CoLocateWith {
+				if coLocateIdx >= 0 && coLocateIdx < len(apps) {
+					fs.runningDepend[coLocateIdx] = append(fs.runningDepend[coLocateIdx], c)
+				}
+			}
+		}
+		fs.leftDown[c] = make([]float64, len(clouds[c].Allocatable.NetCondClouds))
+		fs.cloudOK[c] = true
+		fs.dirty[c] = true
+	}
+	for appIdx, cloudIdx := range fs.schedulingResult {
+		if cloudIdx != len(clouds) {
+			fs.cloudApps[cloudIdx] = append(fs.cloudApps[cloudIdx], appIdx)
+		}
+	}
+
+	fs.recheck()
+	return fs
+}
+
+// Acceptable returns whether the current scheduling result is acceptable
+func (fs *FeasibilityState) Acceptable() bool {
+	if fs.badClouds != 0 {
+		return false
+	}
+	if fs.replicaSets != nil && len(replicaViolations(fs.clouds, fs.apps, fs.schedulingResult, fs.replicaSets)) != 0 {
+		return false
+	}
+	// the time of an app depends on the apps on all clouds, so hard deadlines are not checked incrementally
+	if fs.hardDeadline && len(deadlineViolations(fs.clouds, fs.apps, fs.schedulingResult)) != 0 {
+		return false
+	}
+	return !fs.budget || len(budgetViolations(fs.clouds, fs.apps, fs.schedulingResult)) == 0
+}
+
+// SchedulingResult returns a copy of the current scheduling result
+func (fs *FeasibilityState) SchedulingResult() []int {
+	var schedulingResult []int = make([]int, len(fs.schedulingResult))
+	copy(schedulingResult, fs.schedulingResult)
+	return schedulingResult
+}
+
+// Add deploys apps[appIdx] on clouds[cloudIdx], if the app is already deployed on another cloud, it is moved. cloudIdx == len(clouds) means rejecting it.
+func (fs *FeasibilityState) Add(appIdx, cloudIdx int) {
+	oldCloudIdx := fs.schedulingResult[appIdx]
+	if oldCloudIdx == cloudIdx {
+		return
+	}
+
+	if oldCloudIdx != len(fs.clouds) {
+		fs.cloudApps[oldCloudIdx] = removeSorted(fs.cloudApps[oldCloudIdx], appIdx)
+		fs.dirty[oldCloudIdx] = true
+	}
+	if cloudIdx != len(fs.clouds) {
+		fs.cloudApps[cloudIdx] = insertSorted(fs.cloudApps[cloudIdx], appIdx)
+		fs.dirty[cloudIdx] = true
+	}
+	fs.schedulingResult[appIdx] = cloudIdx
+
+	// the apps depending on this app check its cloud
+	for _, dependent := range fs.dependents[appIdx] {
+		if fs.schedulingResult[dependent] != len(fs.clouds) {
+			fs.dirty[fs.schedulingResult[dependent]] = true
+		}
+	}
+	for _, c := range fs.runningDepend[appIdx] {
+		fs.dirty[c] = true
+	}
+
+	fs.recheck()
+}
+
+// Remove rejects apps[appIdx]
+func (fs *FeasibilityState) Remove(appIdx int) {
+	fs.Add(appIdx, len(fs.clouds))
+}
+
+// TestPlacement returns whether the scheduling result is acceptable if apps[appIdx] is deployed on clouds[cloudIdx], the state is not changed
+func (fs *FeasibilityState) TestPlacement(appIdx, cloudIdx int) bool {
+	oldCloudIdx := fs.schedulingResult[appIdx]
+	fs.Add(appIdx, cloudIdx)
+	acceptable := fs.Acceptable()
+	fs.Add(appIdx, oldCloudIdx)
+	return acceptable
+}
+
+// recheck checks the dirty clouds in the same order as Acceptable, and a cloud becomes dirty if the bandwidth left to it by a cloud before it changes
+func (fs *FeasibilityState) recheck() {
+	var oldLeftDown []float64
+	for c := 0; c < len(fs.clouds); c++ {
+		if !fs.dirty[c] {
+			continue
+		}
+		fs.dirty[c] = false
+
+		oldLeftDown = append(oldLeftDown[:0], fs.leftDown[c]...)
+		ok := fs.checkCloud(c)
+		if ok != fs.cloudOK[c] {
+			if ok {
+				fs.badClouds--
+			} else {
+				fs.badClouds++
+			}
+			fs.cloudOK[c] = ok
+		}
+
+		for d := c + 1; d < len(fs.leftDown[c]) && d < len(fs.clouds); d++ {
+			if fs.leftDown[c][d] != oldLeftDown[d] {
+				fs.dirty[d] = true
+			}
+		}
+	}
+}
+
+// checkCloud is the check of one cloud in Acceptable
+func (fs *FeasibilityState) checkCloud(c int) bool {
+	var cloudNum int = len(fs.clouds)
+	var alloc model.Resources = fs.clouds[c].Allocatable
+
+	// the TmpAlloc of this cloud is reset to Allocatable
+	var down []float64 = fs.leftDown[c]
+	for d := 0; d < len(down); d++ {
+		down[d] = alloc.NetCondClouds[d].DownBw
+	}
+	for d := 0; d < cloudNum; d++ {
+		fs.upSet[d] = false
+	}
+	// the bandwidth from cloud d to this cloud
+	// clouds before this one have been checked, clouds after this one still have their original TmpAlloc, and the same cloud uses the same link in both directions
+	var upBw func(int) *float64 = func(d int) *float64 {
+		if d == c {
+			return &down[c]
+		}
+		if !fs.upSet[d] {
+			if d < c {
+				fs.up[d] = fs.leftDown[d][c]
+			} else {
+				fs.up[d] = fs.clouds[d].TmpAlloc.NetCondClouds[c].DownBw
+			}
+			fs.upSet[d] = true
+		}
+		return &fs.up[d]
+	}
+
+	// the same order as RunningApps after SimulateDeploy and sort.Sort
+	fs.sortBuf = fs.sortBuf[:0]
+	for i := 0; i < len(fs.runningApps[c]); i++ {
+		fs.sortBuf = append(fs.sortBuf, &fs.runningApps[c][i])
+	}
+	for _, appIdx := range fs.cloudApps[c] {
+		fs.sortBuf = append(fs.sortBuf, &fs.apps[appIdx])
+	}
+	sort.Sort(fs.sortBuf)
+
+	curCPULC := alloc.CPU.LogicalCores
+	curAmounts := alloc.Amounts()
+	for group := range fs.antiAffinity {
+		delete(fs.antiAffinity, group)
+	}
+	for _, deployedApp := range fs.sortBuf {
+		if !CloudMeetApp(fs.clouds[c], *deployedApp) {
+			return false
+		}
+		if deployedApp.AntiAffinityGroup != "" {
+			if _, exist := fs.antiAffinity[deployedApp.AntiAffinityGroup]; exist {
+				return false
+			}
+			fs.antiAffinity[deployedApp.AntiAffinityGroup] = struct{}{}
+		}
+		for _, coLocateIdx := range deployedApp.CoLocateWith {
+			if fs.schedulingResult[coLocateIdx] != c {
+				return false
+			}
+		}
+
+		if deployedApp.IsTask && !fs.clouds[c].ConcurrentTasks() {
+			if !curAmounts.Fits(deployedApp.TaskReq.Amounts()) {
+				return false
+			}
+		} else if deployedApp.IsTask {
+			if !curAmounts.Fits(deployedApp.TaskReq.Amounts()) {
+				return false
+			}
+			curAmounts = curAmounts.Sub(deployedApp.TaskReq.Amounts())
+		} else {
+			curCPULC -= deployedApp.SvcReq.CPUClock / alloc.CPU.BaseClock
+			if curCPULC < 0 || !curAmounts.Fits(deployedApp.SvcReq.Amounts()) {
+				return false
+			}
+			curAmounts = curAmounts.Sub(deployedApp.SvcReq.Amounts())
+		}
+
+		for _, dependence := range deployedApp.Depend {
+			dependentCloudIdx := servingCloud(fs.clouds, fs.replicaSets, fs.schedulingResult, dependence.AppIdx, c)
+			if dependentCloudIdx == cloudNum {
+				return false
+			}
+			if fs.apps[dependence.AppIdx].IsTask {
+				continue
+			}
+			if alloc.NetCondClouds[dependentCloudIdx].RTT > dependence.RTT {
+				return false
+			}
+			if deployedApp.IsTask {
+				if down[dependentCloudIdx] < dependence.DownBw || *upBw(dependentCloudIdx) < dependence.UpBw {
+					return false
+				}
+			} else {
+				down[dependentCloudIdx] -= dependence.DownBw
+				*upBw(dependentCloudIdx) -= dependence.UpBw
+				if down[dependentCloudIdx] < 0 || *upBw(dependentCloudIdx) < 0 {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// insertSorted inserts v into the ascending slice s
+func insertSorted(s []int, v int) []int {
+	i := sort.SearchInts(s, v)
+	s = append(s, 0)
+	copy(s[i+1:], s[i:])
+	s[i] = v
+	return s
+}
+
+// removeSorted removes v from the ascending slice s
+func removeSorted(s []int, v int) []int {
+	i := sort.SearchInts(s, v)
+	if i < len(s) && s[i] == v {
+		s = append(s[:i], s[i+1:]...)
+	}
+	return s
+}
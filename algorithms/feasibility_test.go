@@ -0,0 +1,175 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/model"
+)
+
+// clouds and apps with tight resources, so that both acceptable and unacceptable placements are common
+func forTestTightCloudsApps(r *Random) ([]model.Cloud, []model.Application) {
+	var gib float64 = 1024 * 1024 * 1024
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	for i := 0; i < len(apps); i++ {
+		if apps[i].IsTask {
+			apps[i].TaskReq.Memory = r.RandomFloat64(0, 40) * gib
+		} else {
+			apps[i].SvcReq.Memory = r.RandomFloat64(0, 20) * gib
+			apps[i].SvcReq.CPUClock = r.RandomFloat64(0, 60)
+		}
+	}
+	apps[6].Depend = []model.Dependence{{AppIdx: 2, DownBw: 100, UpBw: 60, RTT: 25}, {AppIdx: 4, DownBw: 80, UpBw: 90, RTT: 40}}
+	apps[7].Depend = []model.Dependence{{AppIdx: 0, DownBw: 50, UpBw: 50, RTT: 100}}
+	for c := 0; c < len(clouds); c++ {
+		for d := 0; d < len(clouds); d++ {
+			clouds[c].Allocatable.NetCondClouds[d].DownBw = r.RandomFloat64(0, 300)
+			clouds[c].TmpAlloc.NetCondClouds[d].DownBw = r.RandomFloat64(0, 300) // Acceptable reads the TmpAlloc of clouds not checked yet
+		}
+	}
+	// the tasks on cloud 2 take up memory and storage at the same time
+	clouds[2].TaskSharing = model.TaskSharingFair
+	// an app that was deployed before this scheduling
+	clouds[1].RunningApps = []model.Application{{SvcReq: model.ServiceResources{CPUClock: 5, Memory: 4 * gib, Storage: gib}, Priority: 5500, Depend: []model.Dependence{{AppIdx: 0, DownBw: 20, UpBw: 20, RTT: 100}}}}
+	// placement constraints
+	clouds[0].Labels = map[string]string{"region": "eu"}
+	clouds[2].Labels = map[string]string{"region": "eu"}
+	clouds[1].Name = "core-1"
+	apps[1].CloudSelector = map[string]string{"region": "eu"}
+	apps[3].ForbiddenClouds = []string{"core-1"}
+	apps[2].AntiAffinityGroup = "replicas"
+	apps[4].AntiAffinityGroup = "replicas"
+	apps[5].CoLocateWith = []int{0}
+	// a scalar resource that cloud 1 does not have
+	clouds[0].Allocatable.Scalars = model.ScalarResources{"licenses": 3}
+	clouds[2].Allocatable.Scalars = model.ScalarResources{"licenses": 2}
+	for i := 0; i < len(apps); i += 2 {
+		if apps[i].IsTask {
+			apps[i].TaskReq.Scalars = model.ScalarResources{"licenses": float64(r.RandomInt(0, 2))}
+		} else {
+			apps[i].SvcReq.Scalars = model.ScalarResources{"licenses": float64(r.RandomInt(0, 2))}
+		}
+	}
+	return clouds, apps
+}
+
+func TestFeasibilityStateSameAsAcceptable(t *testing.T) {
+	var acceptableCount, unacceptableCount int
+	for seed := int64(0); seed < 10; seed++ {
+		r := NewRandom(seed)
+		clouds, apps := forTestTightCloudsApps(r)
+
+		var schedulingResult []int = make([]int, len(apps))
+		for i := 0; i < len(schedulingResult); i++ {
+			schedulingResult[i] = r.RandomInt(0, len(clouds))
+		}
+		state := NewFeasibilityState(clouds, apps, schedulingResult)
+		assert.Equal(t, Acceptable(clouds, apps, schedulingResult), state.Acceptable(), fmt.Sprintf("seed %d: initial result %v", seed, schedulingResult))
+
+		for step := 0; step < 500; step++ {
+			appIdx, cloudIdx := r.RandomInt(0, len(apps)-1), r.RandomInt(0, len(clouds))
+
+			// test a placement without changing the state
+			schedulingResult[appIdx], cloudIdx = cloudIdx, schedulingResult[appIdx]
+			expected := Acceptable(clouds, apps, schedulingResult)
+			schedulingResult[appIdx], cloudIdx = cloudIdx, schedulingResult[appIdx]
+			assert.Equal(t, expected, state.TestPlacement(appIdx, cloudIdx), fmt.Sprintf("seed %d step %d: test app %d on cloud %d", seed, step, appIdx, cloudIdx))
+			assert.Equal(t, schedulingResult, state.SchedulingResult(), fmt.Sprintf("seed %d step %d: TestPlacement changed the state", seed, step))
+
+			// move the app
+			if cloudIdx == len(clouds) {
+				state.Remove(appIdx)
+			} else {
+				state.Add(appIdx, cloudIdx)
+			}
+			schedulingResult[appIdx] = cloudIdx
+			assert.Equal(t, schedulingResult, state.SchedulingResult())
+			assert.Equal(t, Acceptable(clouds, apps, schedulingResult), state.Acceptable(), fmt.Sprintf("seed %d step %d: result %v", seed, step, schedulingResult))
+			if state.Acceptable() {
+				acceptableCount++
+			} else {
+				unacceptableCount++
+			}
+		}
+	}
+	// make sure that the random walk covers both cases
+	assert.Greater(t, acceptableCount, 0, "no acceptable result is visited")
+	assert.Greater(t, unacceptableCount, 0, "no unacceptable result is visited")
+}
+
+func TestFirstFitScheduleSameAsAcceptable(t *testing.T) {
+	// First Fit implemented directly with Acceptable
+	var expectedFirstFit func([]model.Cloud, []model.Application) []int = func(clouds []model.Cloud, apps []model.Application) []int {
+		var schedulingResult []int = make([]int, len(apps))
+		for i := 0; i < len(apps); i++ {
+			schedulingResult[i] = len(clouds)
+		}
+		for i := 0; i < len(apps); i++ {
+			for j := 0; j < len(clouds); j++ {
+				if !CloudMeetApp(clouds[j], apps[i]) {
+					continue
+				}
+				schedulingResult[i] = j
+				if Acceptable(clouds, apps, schedulingResult) {
+					break
+				}
+				schedulingResult[i] = len(clouds)
+			}
+		}
+		return schedulingResult
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		clouds, apps := forTestTightCloudsApps(NewRandom(seed))
+		assert.Equal(t, expectedFirstFit(clouds, apps), FirstFitSchedule(clouds, apps), fmt.Sprintf("seed %d", seed))
+	}
+}
+
+func TestHardDeadline(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	schedulingResult := FirstFitSchedule(clouds, apps)
+	assert.Equal(t, 1.0, DeadlineHitPriRate(clouds, apps, schedulingResult), "no app has a deadline")
+
+	// app 1 is a task that cannot complete in 1 second on any cloud
+	apps[1].Deadline = 1
+	assert.True(t, Acceptable(clouds, apps, schedulingResult), "soft deadlines do not affect acceptability")
+	assert.Equal(t, 0.0, DeadlineHitPriRate(clouds, apps, schedulingResult))
+
+	apps[1].DeadlineMode = model.DeadlineHard
+	assert.False(t, Acceptable(clouds, apps, schedulingResult), "a hard deadline is missed")
+	violations := AcceptableViolations(clouds, apps, schedulingResult)
+	assert.Len(t, violations, 1)
+	assert.Equal(t, model.RejectReasonDeadline, violations[0].Reason)
+	assert.Equal(t, 1, violations[0].AppIdx)
+	assert.Equal(t, 1.0, violations[0].Required)
+	assert.Greater(t, violations[0].Available, 1.0)
+	assert.False(t, NewFeasibilityState(clouds, apps, schedulingResult).Acceptable())
+
+	solution, err := NewFirstFit(clouds, apps).Schedule(clouds, apps)
+	assert.NoError(t, err)
+	assert.True(t, solution.Placements[1].Rejected, "app 1 should be rejected")
+	assert.Equal(t, model.RejectReasonDeadline, solution.Placements[1].RejectReason)
+
+	// random hard deadlines, FeasibilityState still gives the same result as Acceptable
+	r := NewRandom(1)
+	for i := 0; i < len(apps); i++ {
+		apps[i].Deadline, apps[i].MaxStartupTime, apps[i].DeadlineMode = r.RandomFloat64(0, 200), r.RandomFloat64(0, 10), model.DeadlineHard
+	}
+	var acceptableCount, unacceptableCount int
+	for step := 0; step < 200; step++ {
+		for i := 0; i < len(schedulingResult); i++ {
+			schedulingResult[i] = r.RandomInt(0, len(clouds))
+		}
+		fixDependence(clouds, apps, schedulingResult)
+		acceptable := Acceptable(clouds, apps, schedulingResult)
+		assert.Equal(t, acceptable, NewFeasibilityState(clouds, apps, schedulingResult).Acceptable(), fmt.Sprintf("step %d: result %v", step, schedulingResult))
+		if acceptable {
+			acceptableCount++
+		} else {
+			unacceptableCount++
+		}
+	}
+	assert.Greater(t, acceptableCount, 0, "no acceptable result is visited")
+	assert.Greater(t, unacceptableCount, 0, "no unacceptable result is visited")
+}
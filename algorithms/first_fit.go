@@ -1,6 +1,7 @@
 package algorithms
 
 import (
+	"context"
 	"gogeneticwrsp/model"
 )
 
@@ -11,17 +12,35 @@ func NewFirstFit(clouds []model.Cloud, apps []model.Application) *FirstFit {
 	return &FirstFit{}
 }
 
+// ScheduleContext ignores ctx, because First Fit finishes in one pass
+func (ff *FirstFit) ScheduleContext(ctx context.Context, clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
+	return ff.Schedule(clouds, apps)
+}
+
 func (ff *FirstFit) Schedule(clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
 	schedulingResult := FirstFitSchedule(clouds, apps)
-	return model.Solution{SchedulingResult: schedulingResult}, nil
+	solution := DescribeSolution(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+	solution.Algorithm = "First Fit"
+	solution.Params = map[string]interface{}{}
+	return solution, nil
 }
 
 func FirstFitSchedule(clouds []model.Cloud, apps []model.Application) []int {
-	var schedulingResult []int = make([]int, len(apps))
+	var allApps []int = make([]int, len(apps))
+	for i := 0; i < len(apps); i++ {
+		allApps[i] = i
+	}
+	return firstFitPlace(clouds, apps, make([]int, len(apps)), allApps)
+}
+
+// firstFitPlace places the apps in toPlace, which are in ascending order, with First Fit, and the other apps stay on their clouds in schedulingResult.
+// The replicas of a service should be all in toPlace or all out of it. schedulingResult is not modified.
+func firstFitPlace(clouds []model.Cloud, apps []model.Application, schedulingResult []int, toPlace []int) []int {
+	schedulingResult = append([]int(nil), schedulingResult...)
 
 	// set all new applications undeployed, old application to their previous clouds
 	var noMigrate map[int]struct{} = make(map[int]struct{})
-	for i := 0; i < len(apps); i++ {
+	for _, i := range toPlace {
 		if apps[i].IsNew { // new apps are allowed to be rejected
 			schedulingResult[i] = len(clouds)
 		} else { // remaining apps are not allowed to be rejected
@@ -33,24 +52,33 @@ func FirstFitSchedule(clouds []model.Cloud, apps []model.Application) []int {
 	}
 
 	// For every app in order, choose the first cloud that can meet its requirements
-	for i := 0; i < len(apps); i++ {
+	// if a cloud is not acceptable, the app stays rejected (new apps) or on its previous cloud (old apps)
+	var state *FeasibilityState = NewFeasibilityState(clouds, apps, schedulingResult)
+	for _, i := range toPlace {
 		if _, exist := noMigrate[i]; exist {
 			continue
 		}
+		if replicas := state.replicasOf(i); replicas != nil {
+			if replicas[0] == i { // the replicas of a service are placed together with the first one
+				var candidates []int
+				for j := 0; j < len(clouds); j++ {
+					if CloudMeetApp(clouds[j], apps[i]) {
+						candidates = append(candidates, j)
+					}
+				}
+				state.placeReplicas(i, candidates)
+			}
+			continue
+		}
 		for j := 0; j < len(clouds); j++ {
 			if !CloudMeetApp(clouds[j], apps[i]) {
 				continue
 			}
-			schedulingResult[i] = j
-			if Acceptable(clouds, apps, schedulingResult) {
+			if state.TestPlacement(i, j) {
+				state.Add(i, j)
 				break
 			}
-			if apps[i].IsNew {
-				schedulingResult[i] = len(clouds)
-			} else { // old apps cannot be rejected
-				schedulingResult[i] = apps[i].CloudRemainingOn
-			}
 		}
 	}
-	return schedulingResult
+	return state.SchedulingResult()
 }
@@ -0,0 +1,118 @@
+package algorithms
+
+import (
+	"gogeneticwrsp/model"
+	"math"
+)
+
+// FitnessFunc calculates the fitness value of a chromosome for Genetic, a larger value is better, and the value should be >= 0.
+// deployedClouds are the clouds after SimulateDeploy and CalcStartComplTime, and apps have the time attributes calculated by CalcStartComplTime.
+// FitnessFunc is called concurrently by FitnessEvaluator, so it must not modify g or shared states.
+type FitnessFunc func(g *Genetic, deployedClouds []model.Cloud, apps []model.Application, chromosome Chromosome) float64
+
+// PriorityTimeFitness is the fitness of MCASGA, the sum of (RejectExecTime - time) * priority of all accepted apps,
+// where time is the completion time of tasks and the stable time of services
+func PriorityTimeFitness(g *Genetic, deployedClouds []model.Cloud, apps []model.Application, chromosome Chromosome) float64 {
+	var fitnessValue float64
+	// the fitnessValue is based on each application
+	for appIndex := 0; appIndex < len(chromosome); appIndex++ {
+		fitnessValue += priorityTimeOneApp(g, deployedClouds, apps, appIndex, chromosome)
+	}
+	return fitnessValue
+}
+
+// fitness of a single application, the fitness values is >= 0. Optimized time complexity: O(1)
+func priorityTimeOneApp(g *Genetic, clouds []model.Cloud, apps []model.Application, appIdx int, chromosome Chromosome) float64 {
+	if chromosome[appIdx] == len(clouds) {
+		return 0
+	}
+
+	// Task: minimize execution time; Service: maximize execution time
+	thisFitness := (g.RejectExecTime - apps[appIdx].FinishTime()) * float64(apps[appIdx].Priority)
+	if thisFitness < 0 {
+		thisFitness = 0
+	}
+	return thisFitness
+}
+
+// NewDeadlineMissFitness is PriorityTimeFitness with a penalty on lateness, it enforces the soft deadlines of apps (Deadline of tasks and MaxStartupTime of services).
+// For every accepted app that misses its deadline, missPenalty * lateness * priority is subtracted from its fitness.
+// Hard deadlines are enforced by Acceptable, so acceptable chromosomes never miss them.
+func NewDeadlineMissFitness(missPenalty float64) FitnessFunc {
+	return func(g *Genetic, deployedClouds []model.Cloud, apps []model.Application, chromosome Chromosome) float64 {
+		var fitnessValue float64
+		for appIndex := 0; appIndex < len(chromosome); appIndex++ {
+			thisFitness := priorityTimeOneApp(g, deployedClouds, apps, appIndex, chromosome)
+			if chromosome[appIndex] != len(deployedClouds) {
+				thisFitness -= missPenalty * apps[appIndex].Lateness() * float64(apps[appIndex].Priority)
+			}
+			if thisFitness > 0 {
+				fitnessValue += thisFitness
+			}
+		}
+		return fitnessValue
+	}
+}
+
+// NewEnergyAwareFitness is PriorityTimeFitness divided by (1 + weight * energy).
+// energy is the part of Energy added by the accepted apps during g.RejectExecTime, calculated with the power models of the clouds, unit joule.
+// The idle power and the cores busy before the schedule are left out, because they are the same for every scheduling result.
+func NewEnergyAwareFitness(weight float64) FitnessFunc {
+	return func(g *Genetic, deployedClouds []model.Cloud, apps []model.Application, chromosome Chromosome) float64 {
+		// the capacities and the power models of deployedClouds are not changed by the deployment, and apps already have their times calculated
+		var energy float64
+		for cloudIdx, intervals := range appPowerIntervals(deployedClouds, apps, apps, chromosome, g.RejectExecTime) {
+			for _, interval := range intervals {
+				energy += interval.Watts * (interval.To - interval.From) * deployedClouds[cloudIdx].Power.EffectivePUE()
+			}
+		}
+		return PriorityTimeFitness(g, deployedClouds, apps, chromosome) / (1 + weight*math.Max(energy, 0))
+	}
+}
+
+// NewCostAwareFitness is PriorityTimeFitness divided by (1 + weight * cost).
+// cost is calculated with the prices of the clouds in the same way as Cost, and the services run for g.RejectExecTime.
+func NewCostAwareFitness(weight float64) FitnessFunc {
+	return func(g *Genetic, deployedClouds []model.Cloud, apps []model.Application, chromosome Chromosome) float64 {
+		// the prices and the capacities of deployedClouds are not changed by the deployment, and apps already have their times calculated
+		costs, hourlyCosts := appCostsAt(deployedClouds, apps, apps, chromosome)
+		var cost float64
+		for i := 0; i < len(apps); i++ {
+			cost += costs[i] + hourlyCosts[i]*g.RejectExecTime/secondsPerHour
+		}
+		return PriorityTimeFitness(g, deployedClouds, apps, chromosome) / (1 + weight*cost)
+	}
+}
+
+// NewLoadBalanceFitness is PriorityTimeFitness divided by (1 + weight * variance), where variance is the variance of the CPU utilization of all clouds,
+// the CPU utilization of a cloud is the logical cores taken up by its services divided by its allocatable logical cores
+func NewLoadBalanceFitness(weight float64) FitnessFunc {
+	return func(g *Genetic, deployedClouds []model.Cloud, apps []model.Application, chromosome Chromosome) float64 {
+		var utilizations []float64 = make([]float64, len(deployedClouds))
+		for appIndex := 0; appIndex < len(chromosome); appIndex++ {
+			cloudIndex := chromosome[appIndex]
+			if cloudIndex == len(deployedClouds) || apps[appIndex].IsTask {
+				continue
+			}
+			alloc := deployedClouds[cloudIndex].Allocatable.CPU
+			utilizations[cloudIndex] += apps[appIndex].SvcReq.CPUClock / alloc.BaseClock / alloc.LogicalCores
+		}
+		return PriorityTimeFitness(g, deployedClouds, apps, chromosome) / (1 + weight*variance(utilizations))
+	}
+}
+
+// variance of values
+func variance(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum, squareSum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	for _, value := range values {
+		squareSum += (value - mean) * (value - mean)
+	}
+	return squareSum / float64(len(values))
+}
@@ -0,0 +1,96 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/model"
+)
+
+func TestFitnessFuncs(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	g, err := NewGenetic(20, 30, 0.4, 0.05, 10, RandomFitSchedule, OnePointCrossOver, PriorityTimeFitness, true, false, clouds, apps)
+	assert.NoError(t, err)
+	g.RejectExecTime = 100
+	var chromosome Chromosome = Chromosome(FirstFitSchedule(clouds, apps))
+
+	// the expected value of PriorityTimeFitness
+	deployedClouds := SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: chromosome})
+	timeApps := CalcStartComplTime(deployedClouds, model.AppsCopy(apps), chromosome)
+	var base float64
+	for i := 0; i < len(apps); i++ {
+		if chromosome[i] == len(clouds) {
+			continue
+		}
+		thisTime := timeApps[i].StableTime
+		if apps[i].IsTask {
+			thisTime = timeApps[i].TaskCompletionTime
+		}
+		if thisTime < g.RejectExecTime {
+			base += (g.RejectExecTime - thisTime) * float64(apps[i].Priority)
+		}
+	}
+	assert.Greater(t, base, 0.0)
+	assert.InDelta(t, base, g.Fitness(clouds, apps, chromosome), 1e-6)
+
+	// apps that all miss their soft deadlines
+	var lateApps []model.Application = model.AppsCopy(apps)
+	for i := 0; i < len(lateApps); i++ {
+		lateApps[i].Deadline, lateApps[i].MaxStartupTime = 0.001, 0.001
+	}
+	// clouds that charge for every logical core, and clouds whose busy cores draw more power
+	var pricedClouds, poweredClouds []model.Cloud = model.CloudsCopy(clouds), model.CloudsCopy(clouds)
+	for i := 0; i < len(clouds); i++ {
+		pricedClouds[i].Price.CPUHour = 1
+		poweredClouds[i].Power.IdleWatts, poweredClouds[i].Power.PeakWatts = 100, 300
+	}
+
+	testCases := []struct {
+		name        string
+		fitnessFunc FitnessFunc
+		clouds      []model.Cloud       // nil means clouds
+		apps        []model.Application // nil means apps
+		lower       bool                // whether the fitness should be lower than PriorityTimeFitness
+	}{
+		{name: "case 1: no deadline", fitnessFunc: NewDeadlineMissFitness(10), lower: false},
+		{name: "case 2: all deadlines missed", fitnessFunc: NewDeadlineMissFitness(10), apps: lateApps, lower: true},
+		{name: "case 3: no energy weight", fitnessFunc: NewEnergyAwareFitness(0), clouds: poweredClouds, lower: false},
+		{name: "case 4: energy", fitnessFunc: NewEnergyAwareFitness(0.1), clouds: poweredClouds, lower: true},
+		{name: "case 5: free clouds", fitnessFunc: NewCostAwareFitness(1), lower: false},
+		{name: "case 6: cost", fitnessFunc: NewCostAwareFitness(0.1), clouds: pricedClouds, lower: true},
+		{name: "case 7: no load balance weight", fitnessFunc: NewLoadBalanceFitness(0), lower: false},
+		{name: "case 8: load balance", fitnessFunc: NewLoadBalanceFitness(10), lower: true},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		g.FitnessFunc = testCase.fitnessFunc
+		thisClouds, thisApps := clouds, apps
+		if testCase.clouds != nil {
+			thisClouds = testCase.clouds
+		}
+		if testCase.apps != nil {
+			thisApps = testCase.apps
+		}
+		fitness := g.Fitness(thisClouds, thisApps, chromosome)
+		assert.GreaterOrEqual(t, fitness, 0.0, fmt.Sprintf("%s: negative fitness", testCase.name))
+		if testCase.lower {
+			assert.Less(t, fitness, base, testCase.name)
+		} else {
+			assert.InDelta(t, base, fitness, 1e-6, testCase.name)
+		}
+	}
+
+	// the injected fitness function is used by Schedule
+	var called bool
+	g2, err := NewGenetic(20, 30, 0.4, 0.05, 10, RandomFitSchedule, OnePointCrossOver, func(g *Genetic, deployedClouds []model.Cloud, apps []model.Application, chromosome Chromosome) float64 {
+		called = true
+		return PriorityTimeFitness(g, deployedClouds, apps, chromosome)
+	}, true, false, clouds, apps)
+	assert.NoError(t, err)
+	g2.Evaluator = NewFitnessEvaluator(1) // only one goroutine writes called
+	_, err = g2.Schedule(clouds, apps)
+	assert.NoError(t, err)
+	assert.True(t, called, "the injected fitness function is not used")
+}
@@ -1,8 +1,8 @@
 package algorithms
 
 import (
+	"context"
 	"fmt"
-	"github.com/KeepTheBeats/routing-algorithms/random"
 	"github.com/wcharczuk/go-chart"
 	"gogeneticwrsp/model"
 	"log"
@@ -25,6 +25,13 @@ func PopulationCopy(src Population) Population {
 	return dst
 }
 
+// ChromosomeCopy deep copy a chromosome
+func ChromosomeCopy(src Chromosome) Chromosome {
+	var dst Chromosome = make(Chromosome, len(src))
+	copy(dst, src)
+	return dst
+}
+
 type Genetic struct {
 	ChromosomesCount       int
 	IterationCount         int
@@ -44,17 +51,30 @@ type Genetic struct {
 
 	SelectableCloudsForApps [][]int
 
-	InitFunc      func([]model.Cloud, []model.Application) []int        // the function to initialize populations
-	CrossoverFunc func(Chromosome, Chromosome) (Chromosome, Chromosome) // crossover operator
-	BtSelection   bool                                                  // true, use binary tournament selection; false, use roulette-wheel selection
-	CbMutation    bool                                                  // true, use chromosome-based mutation; false, use gene-based mutation
+	InitFunc      func(*Random, []model.Cloud, []model.Application) []int        // the function to initialize populations
+	CrossoverFunc func(*Random, Chromosome, Chromosome) (Chromosome, Chromosome) // crossover operator
+	FitnessFunc   FitnessFunc                                                    // fitness function, e.g., PriorityTimeFitness
+	BtSelection   bool                                                           // true, use binary tournament selection; false, use roulette-wheel selection
+	CbMutation    bool                                                           // true, use chromosome-based mutation; false, use gene-based mutation
 
 	RejectExecTime float64 // We set this time as the start time of rejected services and completion time of rejected tasks unit second
+
+	// multi-objective mode: if Objectives is not empty, the selection uses non-dominated sorting and crowding distance on them instead of Fitness,
+	// and the acceptable non-dominated chromosomes found in all iterations are kept in ParetoFront, at most ChromosomesCount of them
+	Objectives            []Objective
+	ParetoFront           Population
+	ParetoFrontObjectives [][]float64 // the values of Objectives of each chromosome in ParetoFront
+
+	Rand      *Random           // random source of this scheduler, replace it with NewRandom(seed) to replay a run
+	Evaluator *FitnessEvaluator // evaluates the fitness and acceptability of every generation in parallel
 }
 
-func NewGenetic(chromosomesCount int, iterationCount int, crossoverProbability float64, mutationProbability float64, stopNoUpdateIteration int, initFunc func([]model.Cloud, []model.Application) []int, crossoverFunc func(Chromosome, Chromosome) (Chromosome, Chromosome), btSelection bool, cbMutation bool, clouds []model.Cloud, apps []model.Application) *Genetic {
+func NewGenetic(chromosomesCount int, iterationCount int, crossoverProbability float64, mutationProbability float64, stopNoUpdateIteration int, initFunc func(*Random, []model.Cloud, []model.Application) []int, crossoverFunc func(*Random, Chromosome, Chromosome) (Chromosome, Chromosome), fitnessFunc FitnessFunc, btSelection bool, cbMutation bool, clouds []model.Cloud, apps []model.Application) (*Genetic, error) {
 	if err := model.DependencyValid(apps); err != nil {
-		log.Panicf("model.DependencyValid(apps), err: %s", err.Error())
+		return nil, err
+	}
+	if err := replicasExpanded(apps); err != nil {
+		return nil, err
 	}
 
 	selectableCloudsForApps := make([][]int, len(apps))
@@ -108,12 +128,15 @@ func NewGenetic(chromosomesCount int, iterationCount int, crossoverProbability f
 		SelectableCloudsForApps:                selectableCloudsForApps,
 		InitFunc:                               initFunc,
 		CrossoverFunc:                          crossoverFunc,
+		FitnessFunc:                            fitnessFunc,
 		BtSelection:                            btSelection,
 		CbMutation:                             cbMutation,
-	}
+		Rand:                                   NewTimeSeededRandom(),
+		Evaluator:                              NewFitnessEvaluator(0),
+	}, nil
 }
 
-// Fitness calculate the fitness value of this scheduling result, the fitness values is possibly less than 0
+// Fitness calculate the fitness value of this scheduling result with g.FitnessFunc
 func (g *Genetic) Fitness(clouds []model.Cloud, apps []model.Application, chromosome Chromosome) float64 {
 	var deployedClouds []model.Cloud = SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: chromosome})
 	var appsCopy []model.Application = model.AppsCopy(apps)
@@ -127,18 +150,19 @@ func (g *Genetic) Fitness(clouds []model.Cloud, apps []model.Application, chromo
 	//}
 	//time.Sleep(101 * time.Second)
 
-	var fitnessValue float64
-	// the fitnessValue is based on each application
-	for appIndex := 0; appIndex < len(chromosome); appIndex++ {
-		fitnessValue += g.fitnessOneApp(deployedClouds, appsCopy, appIndex, chromosome)
-	}
-
-	return fitnessValue
+	return g.FitnessFunc(g, deployedClouds, appsCopy, chromosome)
 }
 
 // CalcStartComplTime calculate the completion time of all tasks on all clouds, and the start time of all applications
 // slice of Golang is a reference (address/pointer), so we can change the contents in the function
 func CalcStartComplTime(clouds []model.Cloud, apps []model.Application, chromosome Chromosome) []model.Application {
+	// the completion time of concurrent tasks depends on the tasks starting after them, so it needs the event-driven simulation
+	for i := 0; i < len(clouds); i++ {
+		if clouds[i].ConcurrentTasks() {
+			return eventStartComplTime(clouds, apps, chromosome)
+		}
+	}
+
 	// initialization
 	for i := 0; i < len(clouds); i++ {
 		clouds[i].TotalTaskComplTime = 0
@@ -147,7 +171,7 @@ func CalcStartComplTime(clouds []model.Cloud, apps []model.Application, chromoso
 	// save the original order of apps
 	unorderedApps := model.AppsCopy(apps)
 	// traverse apps from high priority to low priority
-	sort.Sort(model.AppSlice(apps))
+	sortByExecutionOrder(clouds, apps, chromosome)
 	for k := 0; k < len(apps); k++ {
 		// In this chromosome, this app is scheduled on this cloud
 		cloudIndex := chromosome[apps[k].AppIdx]
@@ -159,8 +183,9 @@ func CalcStartComplTime(clouds []model.Cloud, apps []model.Application, chromoso
 			if clouds[cloudIndex].RunningApps[i].AppIdx == apps[k].AppIdx {
 				// the start time of every app should be after all its dependent apps
 				// apps is sorted by priority, and an app can only depend on others with higher priorities, so all of this app's dependence in unorderedApps already have the StartTime and TaskCompletionTime
+				// a task being executed already has all its dependence satisfied
 				latestStartTime := clouds[cloudIndex].TotalTaskComplTime
-				for j := 0; j < len(clouds[cloudIndex].RunningApps[i].Depend); j++ {
+				for j := 0; j < len(clouds[cloudIndex].RunningApps[i].Depend) && !(executingOn(clouds[cloudIndex].RunningApps[i], cloudIndex) && clouds[cloudIndex].Preemption != model.PreemptionFree); j++ {
 					if unorderedApps[clouds[cloudIndex].RunningApps[i].Depend[j].AppIdx].IsTask { // should be after the completion time of every dependent task
 						if unorderedApps[clouds[cloudIndex].RunningApps[i].Depend[j].AppIdx].TaskCompletionTime > latestStartTime {
 							latestStartTime = unorderedApps[clouds[cloudIndex].RunningApps[i].Depend[j].AppIdx].TaskCompletionTime
@@ -175,25 +200,7 @@ func CalcStartComplTime(clouds []model.Cloud, apps []model.Application, chromoso
 				clouds[cloudIndex].RunningApps[i].StartTime = latestStartTime
 				unorderedApps[apps[k].AppIdx].StartTime = latestStartTime
 
-				// calculate image pulling time, 1 Byte = 8 bits
-				imagePullTime := (clouds[cloudIndex].RunningApps[i].ImageSize*8)/(clouds[cloudIndex].TmpAlloc.NetCondImage.DownBw*1024*1024) + (clouds[cloudIndex].TmpAlloc.NetCondImage.RTT / 1000) // unit: second
-
-				// An old app with image pulling done on the old cloud, image will already exist
-				if !clouds[cloudIndex].RunningApps[i].IsNew && clouds[cloudIndex].RunningApps[i].ImagePullDone && cloudIndex == clouds[cloudIndex].RunningApps[i].CloudRemainingOn {
-					imagePullTime = 0
-				}
-
-				// calculate time of transmitting data from Architecture Controller to this cloud, 1 Byte = 8 bits
-				dataInputTime := (clouds[cloudIndex].RunningApps[i].InputDataSize*8)/(clouds[cloudIndex].TmpAlloc.NetCondController.DownBw*1024*1024) + (clouds[cloudIndex].TmpAlloc.NetCondController.RTT / 1000) // unit: second
-
-				// calculate the startup time of this application
-				startUpTime := clouds[cloudIndex].RunningApps[i].StartUpCPUCycle / (clouds[cloudIndex].TmpAlloc.CPU.LogicalCores * clouds[cloudIndex].TmpAlloc.CPU.BaseClock * 1024 * 1024 * 1024) // unit: second
-
-				// For an old app already stable on the old cloud, we can simply pause/unpause it through cgroup freezer, no need to input data or start up
-				if !clouds[cloudIndex].RunningApps[i].IsNew && clouds[cloudIndex].RunningApps[i].AlreadyStable && cloudIndex == clouds[cloudIndex].RunningApps[i].CloudRemainingOn {
-					dataInputTime = 0
-					startUpTime = 0
-				}
+				imagePullTime, dataInputTime, startUpTime := deployTimes(clouds[cloudIndex], clouds[cloudIndex].RunningApps[i], cloudIndex, latestStartTime)
 
 				// set image pull done time
 				clouds[cloudIndex].RunningApps[i].ImagePullDoneTime = clouds[cloudIndex].RunningApps[i].StartTime + imagePullTime
@@ -209,7 +216,7 @@ func CalcStartComplTime(clouds []model.Cloud, apps []model.Application, chromoso
 
 				if clouds[cloudIndex].RunningApps[i].IsTask { // Tasks do not take up the resources, but use all remaining resources to finish this task before handling other applications
 					// task execution time
-					execTime := clouds[cloudIndex].RunningApps[i].TaskReq.CPUCycle / (clouds[cloudIndex].TmpAlloc.CPU.LogicalCores * clouds[cloudIndex].TmpAlloc.CPU.BaseClock * 1024 * 1024 * 1024) // unit: second
+					execTime := taskExecTime(clouds[cloudIndex], clouds[cloudIndex].RunningApps[i])
 					// a task should consume the 4 parts of time
 					clouds[cloudIndex].TotalTaskComplTime += imagePullTime + dataInputTime + startUpTime + execTime
 					clouds[cloudIndex].RunningApps[i].TaskCompletionTime = clouds[cloudIndex].TotalTaskComplTime
@@ -243,147 +250,76 @@ func CalcStartComplTime(clouds []model.Cloud, apps []model.Application, chromoso
 	return unorderedApps
 }
 
-// fitness of a single application, the fitness values is >= 0. Optimized time complexity: O(1)
-func (g *Genetic) fitnessOneApp(clouds []model.Cloud, apps []model.Application, appIdx int, chromosome Chromosome) float64 {
-	if chromosome[appIdx] == len(clouds) {
-		return 0
-	}
+// sortByExecutionOrder sorts apps from high priority to low priority,
+// but on a cloud with PreemptionNone or a cloud executing tasks concurrently, the tasks being executed go on before all other apps
+func sortByExecutionOrder(clouds []model.Cloud, apps []model.Application, chromosome Chromosome) {
+	sort.Sort(model.AppSlice(apps))
+	sort.SliceStable(apps, func(i, j int) bool {
+		return startsFirst(clouds, apps[i], chromosome) && !startsFirst(clouds, apps[j], chromosome)
+	})
+}
 
-	var thisTime float64
-	if apps[appIdx].IsTask { // Task: minimize execution time
-		thisTime = apps[appIdx].TaskCompletionTime
-	} else { // Service: maximize execution time
-		thisTime = apps[appIdx].StableTime
+// deployTimes calculates the image pulling time, data input time and startup time of an app that starts on clouds[cloudIndex] at startTime, unit second
+func deployTimes(cloud model.Cloud, app model.Application, cloudIndex int, startTime float64) (imagePullTime, dataInputTime, startUpTime float64) {
+	// calculate image pulling time, 1 Byte = 8 bits
+	imagePullTime = (app.ImageSize*8)/(cloud.TmpAlloc.NetCondImage.DownBw*1024*1024) + (cloud.TmpAlloc.NetCondImage.RTT / 1000) // unit: second
+
+	// An old app with image pulling done on the old cloud, image will already exist
+	if !app.IsNew && app.ImagePullDone && cloudIndex == app.CloudRemainingOn {
+		imagePullTime = 0
 	}
-	thisFitness := (g.RejectExecTime - thisTime) * float64(apps[appIdx].Priority)
-	if thisFitness < 0 {
-		thisFitness = 0
+
+	// calculate time of transmitting data from Architecture Controller to this cloud, 1 Byte = 8 bits
+	dataInputTime = (app.InputDataSize*8)/(cloud.TmpAlloc.NetCondController.DownBw*1024*1024) + (cloud.TmpAlloc.NetCondController.RTT / 1000) // unit: second
+
+	// calculate the startup time of this application
+	startUpTime = app.StartUpCPUCycle / (cloud.TmpAlloc.CPU.LogicalCores * cloud.TmpAlloc.CPU.BaseClock * 1024 * 1024 * 1024) // unit: second
+
+	// For an old app already stable on the old cloud, we can simply pause/unpause it through cgroup freezer, no need to input data or start up
+	if !app.IsNew && app.AlreadyStable && cloudIndex == app.CloudRemainingOn {
+		dataInputTime = 0
+		startUpTime = 0
 	}
-	return thisFitness
-}
 
-// complex fitness of a single application, the fitness values is >= 0, maybe about O(len(apps))
-func (g *Genetic) fitnessOneAppComplex(clouds []model.Cloud, app model.Application, chosenCloudIndex int) float64 {
-	if chosenCloudIndex == len(clouds) {
-		return 0
-	}
-
-	if app.IsTask { // Task: minimize execution time
-		for i := 0; i < len(clouds[chosenCloudIndex].RunningApps); i++ {
-			// find this app in RunningApps of this cloud
-			if clouds[chosenCloudIndex].RunningApps[i].AppIdx == app.AppIdx {
-				thisTaskCompleTime := clouds[chosenCloudIndex].RunningApps[i].TaskCompletionTime
-				// Maxmize (X - ta) * priority (if ta > X, we set ta = X)
-				thisFitness := (g.RejectExecTime - thisTaskCompleTime) * float64(app.Priority)
-				if thisFitness < 0 {
-					thisFitness = 0
-				}
-				return thisFitness
-			}
-		}
-		log.Panicln("Task, cannot find clouds[chosenCloudIndex].RunningApps[i].AppIdx == app.AppIdx")
-	} else { // Service: maximize execution time
-		for i := 0; i < len(clouds[chosenCloudIndex].RunningApps); i++ {
-			// find this app in RunningApps of this cloud
-			if clouds[chosenCloudIndex].RunningApps[i].AppIdx == app.AppIdx {
-				thisSvcStableTime := clouds[chosenCloudIndex].RunningApps[i].StableTime
-				//Maximize (X - tb) *priority (if tb > X, we set tb = X)
-				thisFitness := (g.RejectExecTime - thisSvcStableTime) * float64(app.Priority)
-				if thisFitness < 0 {
-					thisFitness = 0
-				}
-				return thisFitness
-			}
-		}
-		log.Panicln("Service, cannot find clouds[chosenCloudIndex].RunningApps[i].AppIdx == app.AppIdx")
+	// A task being executed and suspended by the apps before it on a preemptive cloud needs to be resumed, its remaining CPU cycles are in TaskReq.CPUCycle
+	if executingOn(app, cloudIndex) && !cloud.ConcurrentTasks() && cloud.Preemption == model.PreemptionSuspend && startTime > 0 {
+		startUpTime = cloud.ResumeTime
 	}
-	log.Panicln("unreachable return 0")
-	return 0
+	return
 }
 
-// old-version fitness of a single application
-func fitnessOneAppOld(clouds []model.Cloud, app model.Application, chosenCloudIndex int) float64 {
-	// The application is rejected, only set a small fitness, because in some aspects being rejected is better than being deployed incorrectly
-	if chosenCloudIndex == len(clouds) {
-		return 0
-	}
-
-	var subFitness []float64
-	var overflow bool
-
-	var cpuFitness float64 // fitness about CPUClock
-	if clouds[chosenCloudIndex].TmpAlloc.CPU.LogicalCores >= 0 {
-		cpuFitness = 1
-	} else {
-		// CPUClock is compressible resource in Kubernetes
-		//overflowRate := clouds[chosenCloudIndex].Capacity.CPUClock / ((0 - clouds[chosenCloudIndex].TmpAlloc.CPUClock) + clouds[chosenCloudIndex].Capacity.CPUClock)
-		cpuFitness = -1
-		overflow = true
-		// even CPUClock is compressible, I think we still should not allow it to overflow
-	}
-	subFitness = append(subFitness, cpuFitness)
-
-	var memoryFitness float64 // fitness about memory
-	if clouds[chosenCloudIndex].TmpAlloc.Memory >= 0 {
-		memoryFitness = 1
-	} else {
-		// Memory is incompressible resource in Kubernetes, but the fitness is used for the selection in evolution
-		// After evolution, we will only output the solution with no overflow resources
-		//memoryFitness = 0
-		//overflowRate := clouds[chosenCloudIndex].Capacity.Memory / ((0 - clouds[chosenCloudIndex].TmpAlloc.Memory) + clouds[chosenCloudIndex].Capacity.Memory)
-		memoryFitness = -1
-		overflow = true
-	}
-	subFitness = append(subFitness, memoryFitness)
-
-	var storageFitness float64 // fitness about storage
-	if clouds[chosenCloudIndex].TmpAlloc.Storage >= 0 {
-		storageFitness = 1
-	} else {
-		// Storage is incompressible resource in Kubernetes, but the fitness is used for the selection in evolution
-		// After evolution, we will only output the solution with no overflow resources
-		//storageFitness = 0
-		//overflowRate := clouds[chosenCloudIndex].Capacity.Storage / ((0 - clouds[chosenCloudIndex].TmpAlloc.Storage) + clouds[chosenCloudIndex].Capacity.Storage)
-		storageFitness = -1
-		overflow = true
-	}
-	subFitness = append(subFitness, storageFitness)
-
-	// if overflow, do not add fitness
-	if overflow {
-		for i := 0; i < len(subFitness); i++ {
-			if subFitness[i] > 0 {
-				subFitness[i] = 0
-			}
-		}
-	}
+// taskExecTime calculates the execution time of a task given all logical cores in cloud.TmpAlloc, limited by the parallelism of the task, unit second
+func taskExecTime(cloud model.Cloud, app model.Application) float64 {
+	return app.TaskReq.CPUCycle / (app.TaskReq.EffectiveCores(cloud.TmpAlloc.CPU.LogicalCores) * cloud.TmpAlloc.CPU.BaseClock * 1024 * 1024 * 1024)
+}
 
-	var fitness float64
-	for i := 0; i < len(subFitness); i++ {
-		fitness += subFitness[i]
-	}
-	// the higher priority, the more important the application, the more its fitness should be scaled up
-	fitness *= float64(app.Priority)
+// executingOn returns whether app is a task that started executing on clouds[cloudIndex] in previous rounds, so it is frozen and unfrozen there instead of restarted
+func executingOn(app model.Application, cloudIndex int) bool {
+	return app.IsTask && !app.IsNew && app.AlreadyStable && cloudIndex == app.CloudRemainingOn
+}
 
-	return fitness
+// startsFirst returns whether app is a task being executed on a cloud with PreemptionNone or a cloud executing tasks concurrently in this chromosome
+func startsFirst(clouds []model.Cloud, app model.Application, chromosome Chromosome) bool {
+	cloudIndex := chromosome[app.AppIdx]
+	return cloudIndex != len(clouds) && (clouds[cloudIndex].Preemption == model.PreemptionNone || clouds[cloudIndex].ConcurrentTasks()) && executingOn(app, cloudIndex)
 }
 
 func (g *Genetic) initialize(clouds []model.Cloud, apps []model.Application) Population {
 	var initPopulation Population
 	// in a population, there are g.ChromosomesCount chromosomes (individuals)
 	for i := 0; i < g.ChromosomesCount; i++ {
-		//var chromosome Chromosome = InitializeUndeployedChromosome(clouds, apps)
-		//var chromosome Chromosome = InitializeAcceptableChromosome(clouds, apps)
-		//var chromosome Chromosome = RandomFitSchedule(clouds, apps)
+		//var chromosome Chromosome = InitializeUndeployedChromosome(g.Rand, clouds, apps)
+		//var chromosome Chromosome = InitializeAcceptableChromosome(g.Rand, clouds, apps)
+		//var chromosome Chromosome = RandomFitSchedule(g.Rand, clouds, apps)
 		//var chromosome Chromosome = FirstFitSchedule(clouds, apps)
-		var chromosome Chromosome = g.InitFunc(clouds, apps)
+		var chromosome Chromosome = g.InitFunc(g.Rand, clouds, apps)
 		initPopulation = append(initPopulation, chromosome)
 	}
 	return initPopulation
 }
 
 // initialize an Undeployed chromosome
-func InitializeUndeployedChromosome(clouds []model.Cloud, apps []model.Application) []int {
+func InitializeUndeployedChromosome(r *Random, clouds []model.Cloud, apps []model.Application) []int {
 	var chromosome Chromosome = make(Chromosome, len(apps))
 	for i := 0; i < len(apps); i++ {
 		chromosome[i] = len(clouds)
@@ -393,7 +329,7 @@ func InitializeUndeployedChromosome(clouds []model.Cloud, apps []model.Applicati
 }
 
 // initialize an acceptable chromosome
-func InitializeAcceptableChromosome(clouds []model.Cloud, apps []model.Application) []int {
+func InitializeAcceptableChromosome(r *Random, clouds []model.Cloud, apps []model.Application) []int {
 	var chromosome Chromosome = make(Chromosome, len(apps))
 	for i := 0; i < len(apps); i++ {
 		chromosome[i] = len(clouds)
@@ -403,27 +339,40 @@ func InitializeAcceptableChromosome(clouds []model.Cloud, apps []model.Applicati
 	for i := 0; i < len(apps); i++ {
 		undeployed[i] = i
 	}
+	var state *FeasibilityState = NewFeasibilityState(clouds, apps, chromosome)
 	for len(undeployed) > 0 {
-		appIndex := random.RandomInt(0, len(undeployed)-1)
+		appIndex := r.RandomInt(0, len(undeployed)-1)
+		if replicas := state.replicasOf(undeployed[appIndex]); replicas != nil {
+			if replicas[0] == undeployed[appIndex] { // the replicas of a service are placed together with the first one
+				var candidates []int
+				for i := 0; i < len(clouds); i++ {
+					if CloudMeetApp(clouds[i], apps[undeployed[appIndex]]) {
+						candidates = append(candidates, i)
+					}
+				}
+				state.placeReplicas(undeployed[appIndex], candidates)
+			}
+			undeployed = append(undeployed[:appIndex], undeployed[appIndex+1:]...)
+			continue
+		}
 		for i := 0; i < len(clouds); i++ {
 			if !CloudMeetApp(clouds[i], apps[undeployed[appIndex]]) {
 				continue
 			}
-			chromosome[undeployed[appIndex]] = i
-			if Acceptable(clouds, apps, chromosome) {
+			if state.TestPlacement(undeployed[appIndex], i) {
+				state.Add(undeployed[appIndex], i)
 				break
 			}
-			chromosome[undeployed[appIndex]] = len(clouds)
 		}
 		undeployed = append(undeployed[:appIndex], undeployed[appIndex+1:]...)
 	}
 
-	return chromosome
+	return state.SchedulingResult()
 }
 
 // When we need to randomly select a cloud for an app, we use this function to limit the range to g.SelectableCloudsForApps
 func (g *Genetic) randomSelect(appIndex int) int {
-	selectedIndex := random.RandomInt(0, len(g.SelectableCloudsForApps[appIndex])-1)
+	selectedIndex := g.Rand.RandomInt(0, len(g.SelectableCloudsForApps[appIndex])-1)
 	gene := g.SelectableCloudsForApps[appIndex][selectedIndex]
 	return gene
 }
@@ -432,10 +381,29 @@ func (g *Genetic) selectionOperator(clouds []model.Cloud, apps []model.Applicati
 	fitnesses := make([]float64, len(population))
 	cumulativeFitnesses := make([]float64, len(population))
 
-	// calculate the fitness of each chromosome in this population
+	// calculate the fitness and acceptability of each chromosome in this population
+	evaluations := g.Evaluator.EvaluatePopulation(population, func(chromosome Chromosome) Evaluation {
+		var objectives []float64
+		if len(g.Objectives) > 0 {
+			objectives = objectiveValues(g.Objectives, clouds, apps, chromosome)
+		}
+		return Evaluation{
+			Fitness:    g.Fitness(clouds, apps, chromosome),
+			Objectives: objectives,
+			Acceptable: Acceptable(clouds, apps, chromosome),
+		}
+	})
+
+	// multi-objective mode: rank the chromosomes by fronts and crowding distance, and keep the non-dominated acceptable ones
+	var ranks []int
+	var distances []float64
+	if len(g.Objectives) > 0 {
+		ranks, distances = rankAndCrowding(evaluations, FastNonDominatedSort(evaluations))
+		g.updateParetoFront(population, evaluations)
+	}
 	var maxFitness, minFitness float64 = -math.MaxFloat64, math.MaxFloat64 // record the max and min for standardization
 	for i := 0; i < len(population); i++ {
-		fitness := g.Fitness(clouds, apps, population[i])
+		fitness := evaluations[i].Fitness
 
 		fitnesses[i] = fitness
 		if fitness > maxFitness {
@@ -467,7 +435,7 @@ func (g *Genetic) selectionOperator(clouds []model.Cloud, apps []model.Applicati
 
 	// make unacceptable chromosomes harder to be selected
 	for i := 0; i < len(fitnesses); i++ {
-		if !Acceptable(clouds, apps, population[i]) {
+		if !evaluations[i].Acceptable {
 			fitnesses[i] /= 3
 		}
 	}
@@ -490,9 +458,17 @@ func (g *Genetic) selectionOperator(clouds []model.Cloud, apps []model.Applicati
 
 		var selectedChromosomeIndex int
 
-		if g.BtSelection {
+		if len(g.Objectives) > 0 {
+			// binary tournament selection with the crowded-comparison operator
+			picked := g.Rand.RandomPickN(tmpForPick, 2)
+			if crowdedLess(ranks, distances, picked[0], picked[1]) {
+				selectedChromosomeIndex = picked[0]
+			} else {
+				selectedChromosomeIndex = picked[1]
+			}
+		} else if g.BtSelection {
 			// binary tournament selection
-			picked := random.RandomPickN(tmpForPick, 2)
+			picked := g.Rand.RandomPickN(tmpForPick, 2)
 			if fitnesses[picked[0]] > fitnesses[picked[1]] {
 				selectedChromosomeIndex = picked[0]
 			} else {
@@ -501,7 +477,7 @@ func (g *Genetic) selectionOperator(clouds []model.Cloud, apps []model.Applicati
 		} else {
 			// roulette-wheel selection
 			// selectionThreshold is a random float64 in [0, biggest cumulative fitness])
-			selectionThreshold := random.RandomFloat64(0, cumulativeFitnesses[len(cumulativeFitnesses)-1])
+			selectionThreshold := g.Rand.RandomFloat64(0, cumulativeFitnesses[len(cumulativeFitnesses)-1])
 			// selected the smallest cumulativeFitnesses that is begger than selectionThreshold
 			selectedChromosomeIndex = sort.SearchFloat64s(cumulativeFitnesses, selectionThreshold)
 			//log.Printf("selectionThreshold %f,selectedChromosomeIndex %d", selectionThreshold, selectedChromosomeIndex)
@@ -513,13 +489,13 @@ func (g *Genetic) selectionOperator(clouds []model.Cloud, apps []model.Applicati
 		newPopulation = append(newPopulation, newChromosome)
 
 		// record the best fitness in this iteration
-		chosenFitness := g.Fitness(clouds, apps, population[selectedChromosomeIndex])
+		chosenFitness := evaluations[selectedChromosomeIndex].Fitness
 		//log.Printf("selectedChromosomeIndex %d, population[selectedChromosomeIndex] %d, chosenFitness %f", selectedChromosomeIndex, population[selectedChromosomeIndex], chosenFitness)
 
 		if chosenFitness > bestFitnessInThisIteration {
 			bestFitnessInThisIteration = chosenFitness
 			bestFitnessInThisIterationIndex = selectedChromosomeIndex
-			if Acceptable(clouds, apps, population[selectedChromosomeIndex]) {
+			if evaluations[selectedChromosomeIndex].Acceptable {
 				bestAcceptableFitnessInThisIteration = chosenFitness
 				bestAcceptableFitnessInThisIterationIndex = selectedChromosomeIndex
 			}
@@ -565,6 +541,42 @@ func (g *Genetic) selectionOperator(clouds []model.Cloud, apps []model.Applicati
 	return newPopulation
 }
 
+// updateParetoFront merges the acceptable chromosomes of population into g.ParetoFront, and keeps the non-dominated ones.
+// If there are more than g.ChromosomesCount of them, the most crowded ones are dropped.
+func (g *Genetic) updateParetoFront(population Population, evaluations []Evaluation) {
+	var candidates Population
+	var candidateEvaluations []Evaluation
+	var seen map[string]struct{} = make(map[string]struct{})
+	for i := 0; i < len(g.ParetoFront); i++ {
+		seen[chromosomeKey(g.ParetoFront[i])] = struct{}{}
+		candidates = append(candidates, g.ParetoFront[i])
+		candidateEvaluations = append(candidateEvaluations, Evaluation{Objectives: g.ParetoFrontObjectives[i], Acceptable: true})
+	}
+	for i := 0; i < len(population); i++ {
+		if !evaluations[i].Acceptable {
+			continue
+		}
+		key := chromosomeKey(population[i])
+		if _, exist := seen[key]; exist {
+			continue
+		}
+		seen[key] = struct{}{}
+		candidates = append(candidates, ChromosomeCopy(population[i]))
+		candidateEvaluations = append(candidateEvaluations, evaluations[i])
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	survivors := elitistSurvivors(candidateEvaluations, FastNonDominatedSort(candidateEvaluations)[:1], g.ChromosomesCount)
+	g.ParetoFront = make(Population, len(survivors))
+	g.ParetoFrontObjectives = make([][]float64, len(survivors))
+	for i, idx := range survivors {
+		g.ParetoFront[i] = candidates[idx]
+		g.ParetoFrontObjectives[i] = candidateEvaluations[idx].Objectives
+	}
+}
+
 func (g *Genetic) crossoverOperator(clouds []model.Cloud, apps []model.Application, population Population) Population {
 	if len(apps) <= 1 { // only with at least 2 genes in a chromosome, can we do crossover
 		return population
@@ -575,7 +587,7 @@ func (g *Genetic) crossoverOperator(clouds []model.Cloud, apps []model.Applicati
 	// traverse all chromosomes in this population, use random to judge whether a chromosome needs crossover
 	var indexesNeedCrossover []int
 	for i := 0; i < len(copyPopulation); i++ {
-		if random.RandomFloat64(0, 1) < g.CrossoverProbability {
+		if g.Rand.RandomFloat64(0, 1) < g.CrossoverProbability {
 			indexesNeedCrossover = append(indexesNeedCrossover, i)
 		}
 	}
@@ -591,12 +603,12 @@ func (g *Genetic) crossoverOperator(clouds []model.Cloud, apps []model.Applicati
 		// delete them from indexesNeedCrossover;
 		// mark them in whetherCrossover
 		// first index
-		first := random.RandomInt(0, len(indexesNeedCrossover)-1)
+		first := g.Rand.RandomInt(0, len(indexesNeedCrossover)-1)
 		firstIndex := indexesNeedCrossover[first]
 		whetherCrossover[firstIndex] = true                                                            // mark
 		indexesNeedCrossover = append(indexesNeedCrossover[:first], indexesNeedCrossover[first+1:]...) // delete
 		// second index
-		second := random.RandomInt(0, len(indexesNeedCrossover)-1)
+		second := g.Rand.RandomInt(0, len(indexesNeedCrossover)-1)
 		secondIndex := indexesNeedCrossover[second]
 		whetherCrossover[secondIndex] = true                                                             // mark
 		indexesNeedCrossover = append(indexesNeedCrossover[:second], indexesNeedCrossover[second+1:]...) // delete
@@ -604,7 +616,7 @@ func (g *Genetic) crossoverOperator(clouds []model.Cloud, apps []model.Applicati
 		firstChromosome := copyPopulation[firstIndex]
 		secondChromosome := copyPopulation[secondIndex]
 
-		newFirstChromosome, newSecondChromosome := g.CrossoverFunc(firstChromosome, secondChromosome)
+		newFirstChromosome, newSecondChromosome := g.CrossoverFunc(g.Rand, firstChromosome, secondChromosome)
 
 		// append the two new chromosomes in newPopulation
 		newPopulation = append(newPopulation, newFirstChromosome, newSecondChromosome)
@@ -630,14 +642,14 @@ func (g *Genetic) mutationOperator(clouds []model.Cloud, apps []model.Applicatio
 	for i := 0; i < len(copyPopulation); i++ {
 		if g.CbMutation {
 			// chromosome-based mutation
-			if random.RandomFloat64(0, 1) < g.MutationProbability {
-				copyPopulation[i] = RandomFitSchedule(clouds, apps)
+			if g.Rand.RandomFloat64(0, 1) < g.MutationProbability {
+				copyPopulation[i] = RandomFitSchedule(g.Rand, clouds, apps)
 			}
 		} else {
 			// gene-based mutation
 			for j := 0; j < len(copyPopulation[i]); j++ {
 				// use random to judge whether a gene needs mutation
-				if random.RandomFloat64(0, 1) < g.MutationProbability {
+				if g.Rand.RandomFloat64(0, 1) < g.MutationProbability {
 					var newGene int = g.randomSelect(j)
 					// make sure that the mutated gene is different with the original one
 					for newGene != len(clouds) && newGene == copyPopulation[i][j] && len(g.SelectableCloudsForApps[j]) > 1 {
@@ -653,7 +665,7 @@ func (g *Genetic) mutationOperator(clouds []model.Cloud, apps []model.Applicatio
 			// After mutation, if the chromosome becomes unacceptable, we discard it, and randomly generate a new acceptable one
 			// This is to control the population mutate to good direction
 			if !Acceptable(clouds, apps, copyPopulation[i]) {
-				copyPopulation[i] = RandomFitSchedule(clouds, apps)
+				copyPopulation[i] = RandomFitSchedule(g.Rand, clouds, apps)
 			}
 		}
 	}
@@ -708,6 +720,31 @@ func fixDependence(clouds []model.Cloud, apps []model.Application, chromosome Ch
 	}
 }
 
+// checkDependenceFixed makes sure that, after fixDependence, no accepted app in the population depends on a rejected app
+func checkDependenceFixed(clouds []model.Cloud, apps []model.Application, population Population, iteration int) error {
+	for i := 0; i < len(population); i++ {
+		for j := 0; j < len(population[i]); j++ {
+			if population[i][j] == len(clouds) {
+				continue
+			}
+			for k := 0; k < len(apps[j].Depend); k++ {
+				if population[i][apps[j].Depend[k].AppIdx] == len(clouds) {
+					return &BrokenInvariantError{Iteration: iteration, Reason: fmt.Sprintf("chromosome %d accepts app %d on cloud %d but rejects its dependence app %d", i, j, population[i][j], apps[j].Depend[k].AppIdx)}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkBestAcceptable makes sure that the best acceptable chromosome until now is still acceptable
+func checkBestAcceptable(clouds []model.Cloud, apps []model.Application, best Chromosome, iteration int) error {
+	if !Acceptable(model.CloudsCopy(clouds), model.AppsCopy(apps), ChromosomeCopy(best)) {
+		return &BrokenInvariantError{Iteration: iteration, Reason: "the best acceptable chromosome until now is not acceptable"}
+	}
+	return nil
+}
+
 func (g *Genetic) initRejectTime(clouds []model.Cloud, apps []model.Application, initPopulation Population) {
 	var nonZeroChroNum int
 	var complTimes []float64
@@ -757,26 +794,18 @@ func (g *Genetic) initRejectTime(clouds []model.Cloud, apps []model.Application,
 }
 
 func (g *Genetic) Schedule(clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
+	return g.ScheduleContext(context.Background(), clouds, apps)
+}
+
+// ScheduleContext checks ctx before the initialization and every iteration, and returns g.BestAcceptableUntilNow as a truncated solution once ctx is done
+func (g *Genetic) ScheduleContext(ctx context.Context, clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
 	// make sure that all time attributes of each app are 0
-	for i := 0; i < len(apps); i++ {
-		if apps[i].StartTime != 0 {
-			log.Panicf("apps[%d].StartTime is %g", i, apps[i].StartTime)
-		}
-		if apps[i].ImagePullDoneTime != 0 {
-			log.Panicf("apps[%d].ImagePullDoneTime is %g", i, apps[i].ImagePullDoneTime)
-		}
-		if apps[i].DataInputDoneTime != 0 {
-			log.Panicf("apps[%d].DataInputDoneTime is %g", i, apps[i].DataInputDoneTime)
-		}
-		if apps[i].StableTime != 0 {
-			log.Panicf("apps[%d].StableTime is %g", i, apps[i].StableTime)
-		}
-		if apps[i].TaskCompletionTime != 0 {
-			log.Panicf("apps[%d].TaskCompletionTime is %g", i, apps[i].TaskCompletionTime)
-		}
-		//apps[i].StartTime = 0
-		//apps[i].ImagePullDoneTime = 0
-		//apps[i].TaskCompletionTime = 0
+	if err := checkTimeState(apps); err != nil {
+		return model.Solution{Seed: g.Rand.Seed}, err
+	}
+	// the initialization evaluates the whole population, which is wasted once ctx is done
+	if ctx.Err() != nil {
+		return model.Solution{Seed: g.Rand.Seed, Truncated: true}, &NoAcceptableSolutionError{Cause: ctx.Err()}
 	}
 
 	// initialize a population
@@ -795,7 +824,14 @@ func (g *Genetic) Schedule(clouds []model.Cloud, apps []model.Application) (mode
 	//}
 
 	// No. 1 iteration to No. g.IterationCount iteration
+	var truncated bool
 	for iteration := 1; iteration <= g.IterationCount; iteration++ {
+		// stop searching if the caller cancels the scheduling or the time budget is used up
+		if ctx.Err() != nil {
+			truncated = true
+			break
+		}
+
 		//log.Printf("---crossover in iteration %d-------\n", iteration)
 		currentPopulation = g.crossoverOperator(clouds, apps, currentPopulation)
 		//for i, chromosome := range currentPopulation {
@@ -815,16 +851,9 @@ func (g *Genetic) Schedule(clouds []model.Cloud, apps []model.Application) (mode
 			//	}
 			//}
 			fixDependence(clouds, apps, currentPopulation[i])
-			for j := 0; j < len(currentPopulation[i]); j++ {
-				if currentPopulation[i][j] == len(clouds) {
-					continue
-				}
-				for k := 0; k < len(apps[j].Depend); k++ {
-					if currentPopulation[i][apps[j].Depend[k].AppIdx] == len(clouds) {
-						log.Panicln(j, k, currentPopulation[i][j], currentPopulation[i][apps[j].Depend[k].AppIdx])
-					}
-				}
-			}
+		}
+		if err := checkDependenceFixed(clouds, apps, currentPopulation, iteration); err != nil {
+			return model.Solution{Seed: g.Rand.Seed}, err
 		}
 
 		//log.Printf("--------mutation in iteration %d-------\n", iteration)
@@ -838,14 +867,8 @@ func (g *Genetic) Schedule(clouds []model.Cloud, apps []model.Application) (mode
 		//	log.Println(i, chromosome)
 		//}
 
-		clouds1 := model.CloudsCopy(clouds)
-		apps1 := model.AppsCopy(apps)
-		solution1 := model.SolutionCopy(model.Solution{
-			SchedulingResult: g.BestAcceptableUntilNow,
-		})
-		//log.Println(Acceptable(clouds1, apps1, solution1.SchedulingResult))
-		if !Acceptable(clouds1, apps1, solution1.SchedulingResult) {
-			log.Panicln()
+		if err := checkBestAcceptable(clouds, apps, g.BestAcceptableUntilNow, iteration); err != nil {
+			return model.Solution{Seed: g.Rand.Seed}, err
 		}
 
 		// if at least one acceptable solution has been found, and if the best fitness until now has not been updated for a certain number of iterations, we think that the solution is already stable enough, and stop the algorithm
@@ -855,10 +878,68 @@ func (g *Genetic) Schedule(clouds []model.Cloud, apps []model.Application) (mode
 	}
 
 	if len(g.BestAcceptableUntilNowUpdateIterations) == 1 {
-		return model.Solution{}, fmt.Errorf("no acceptable solution is found in %d iterations", g.IterationCount)
+		if truncated {
+			return model.Solution{Seed: g.Rand.Seed, Truncated: true}, &NoAcceptableSolutionError{Iterations: len(g.FitnessRecordIterationBestAcceptable) - 1, Cause: ctx.Err()}
+		}
+		return model.Solution{Seed: g.Rand.Seed}, &NoAcceptableSolutionError{Iterations: g.IterationCount}
+	}
+
+	if len(g.Objectives) > 0 {
+		return g.paretoSolution(clouds, apps, truncated)
+	}
+
+	solution := DescribeSolution(clouds, apps, model.Solution{SchedulingResult: g.BestAcceptableUntilNow, Seed: g.Rand.Seed, Truncated: truncated})
+	solution.Fitness = g.FitnessRecordBestAcceptableUntilNow[len(g.FitnessRecordBestAcceptableUntilNow)-1]
+	solution.Algorithm = "MCASGA"
+	solution.Params = g.params()
+	return solution, nil
+}
+
+// paretoSolution returns g.ParetoFront in the solution of the multi-objective mode,
+// and the scheduling result is the point chosen by ChooseByWeights with the same weight for all objectives
+func (g *Genetic) paretoSolution(clouds []model.Cloud, apps []model.Application, truncated bool) (model.Solution, error) {
+	var solution model.Solution = model.Solution{Seed: g.Rand.Seed, Truncated: truncated}
+	for _, objective := range g.Objectives {
+		solution.ObjectiveNames = append(solution.ObjectiveNames, objective.Name)
+	}
+	for i := 0; i < len(g.ParetoFront); i++ {
+		solution.ParetoFront = append(solution.ParetoFront, model.ParetoPoint{
+			SchedulingResult: ChromosomeCopy(g.ParetoFront[i]),
+			Objectives:       append([]float64{}, g.ParetoFrontObjectives[i]...),
+		})
 	}
+	chosen, err := ChooseByWeights(solution, nil)
+	if err != nil {
+		return solution, err
+	}
+	solution.SchedulingResult = chosen.SchedulingResult
+
+	solution = DescribeSolution(clouds, apps, solution)
+	solution.Fitness = g.Fitness(clouds, apps, solution.SchedulingResult)
+	solution.Algorithm = "MCASGA"
+	solution.Params = g.params()
+	return solution, nil
+}
 
-	return model.Solution{SchedulingResult: g.BestAcceptableUntilNow}, nil
+// params returns the parameters recorded in the solution
+func (g *Genetic) params() map[string]interface{} {
+	var params map[string]interface{} = map[string]interface{}{
+		"chromosomesCount":      g.ChromosomesCount,
+		"iterationCount":        g.IterationCount,
+		"crossoverProbability":  g.CrossoverProbability,
+		"mutationProbability":   g.MutationProbability,
+		"stopNoUpdateIteration": g.StopNoUpdateIteration,
+		"btSelection":           g.BtSelection,
+		"cbMutation":            g.CbMutation,
+	}
+	if len(g.Objectives) > 0 {
+		var names []string
+		for _, objective := range g.Objectives {
+			names = append(names, objective.Name)
+		}
+		params["objectives"] = names
+	}
+	return params
 }
 
 // DrawChart draw g.FitnessRecordIterationBest and g.FitnessRecordBestUntilNow on a line chart
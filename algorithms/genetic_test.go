@@ -1,2 +1,496 @@
 package algorithms
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/model"
+)
+
+// small clouds and apps built in memory, so that the tests do not depend on generated files
+func forTestSmallClouds() []model.Cloud {
+	var cores []float64 = []float64{16, 32, 8}
+	var clouds []model.Cloud = make([]model.Cloud, len(cores))
+	for i := 0; i < len(clouds); i++ {
+		clouds[i].Capacity.CPU = model.CPUResource{LogicalCores: cores[i], BaseClock: 2.5}
+		clouds[i].Capacity.Memory = 32 * 1024 * 1024 * 1024
+		clouds[i].Capacity.Storage = 256 * 1024 * 1024 * 1024
+		clouds[i].Capacity.NetCondClouds = make([]model.NetworkCondition, len(cores))
+		for j := 0; j < len(cores); j++ {
+			if i == j {
+				clouds[i].Capacity.NetCondClouds[j] = model.NetworkCondition{RTT: 0, DownBw: 100000}
+			} else {
+				clouds[i].Capacity.NetCondClouds[j] = model.NetworkCondition{RTT: float64(10 * (i + j)), DownBw: 200}
+			}
+		}
+		clouds[i].Capacity.NetCondImage = model.NetworkCondition{RTT: 50, DownBw: 100}
+		clouds[i].Capacity.NetCondController = model.NetworkCondition{RTT: 20, DownBw: 100}
+		clouds[i].Capacity.UpBwImage = 100
+		clouds[i].Capacity.UpBwController = 100
+		clouds[i].Allocatable = model.ResCopy(clouds[i].Capacity)
+		clouds[i].TmpAlloc = model.ResCopy(clouds[i].Capacity)
+	}
+	return clouds
+}
+
+func forTestSmallApps() []model.Application {
+	var apps []model.Application
+	for i := 0; i < 8; i++ {
+		var app model.Application = model.Application{
+			InputDataSize:   float64(1+i) * 1024 * 1024,
+			ImageSize:       float64(10+i) * 1024 * 1024,
+			StartUpCPUCycle: 3 * 1024 * 1024 * 1024,
+			Priority:        uint16(1000 * (8 - i)),
+			AppIdx:          i,
+			IsNew:           true,
+		}
+		if i%2 == 0 {
+			app.SvcReq = model.ServiceResources{CPUClock: float64(4 + i), Memory: 2 * 1024 * 1024 * 1024, Storage: 8 * 1024 * 1024 * 1024}
+		} else {
+			app.IsTask = true
+			app.TaskReq = model.TaskResources{CPUCycle: float64(100+10*i) * 1024 * 1024 * 1024, Memory: 4 * 1024 * 1024 * 1024, Storage: 16 * 1024 * 1024 * 1024}
+		}
+		apps = append(apps, app)
+	}
+	// an app can only depend on apps with higher priorities
+	apps[3].Depend = []model.Dependence{{AppIdx: 0, DownBw: 10, UpBw: 10, RTT: 100}}
+	apps[6].Depend = []model.Dependence{{AppIdx: 2, DownBw: 10, UpBw: 10, RTT: 100}, {AppIdx: 5, RTT: 100000}}
+	return apps
+}
+
+func TestScheduleReproducibleWithSeed(t *testing.T) {
+	var seed int64 = 20221123
+	testCases := []struct {
+		name      string
+		algorithm func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm
+	}{
+		{
+			name: "Genetic",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				g, _ := NewGenetic(20, 30, 0.4, 0.05, 10, RandomFitSchedule, OnePointCrossOver, PriorityTimeFitness, true, false, clouds, apps)
+				g.Rand = NewRandom(seed)
+				return g
+			},
+		},
+		{
+			name: "HAGA",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				h, _ := NewHAGA(2, 0.6, 20, 30, 0.6, 0.7, 10, clouds, apps)
+				h.Rand = NewRandom(seed)
+				return h
+			},
+		},
+		{
+			name: "NSGAII",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				n, _ := NewNSGAII(20, 30, 1, 0.25, 10, clouds, apps)
+				n.Rand = NewRandom(seed)
+				return n
+			},
+		},
+		{
+			name: "RandomFit",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				rf := NewRandomFit(clouds, apps)
+				rf.Rand = NewRandom(seed)
+				return rf
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		first, err := testCase.algorithm(clouds, apps).Schedule(clouds, apps)
+		assert.NoError(t, err, fmt.Sprintf("%s: first run error", testCase.name))
+		second, err := testCase.algorithm(clouds, apps).Schedule(clouds, apps)
+		assert.NoError(t, err, fmt.Sprintf("%s: second run error", testCase.name))
+		assert.Equal(t, first, second, fmt.Sprintf("%s: runs with the same seed are different", testCase.name))
+		assert.Equal(t, seed, first.Seed, fmt.Sprintf("%s: seed is not recorded", testCase.name))
+	}
+}
+
+func TestScheduleContextStopsSearching(t *testing.T) {
+	// a huge number of iterations that can only be finished if the context is ignored
+	var iterationCount int = 1000000
+	testCases := []struct {
+		name      string
+		algorithm func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm
+	}{
+		{
+			name: "Genetic",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				g, _ := NewGenetic(20, iterationCount, 0.4, 0.05, iterationCount, RandomFitSchedule, OnePointCrossOver, PriorityTimeFitness, true, false, clouds, apps)
+				return g
+			},
+		},
+		{
+			name: "HAGA",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				h, _ := NewHAGA(2, 0.6, 20, iterationCount, 0.6, 0.7, iterationCount, clouds, apps)
+				return h
+			},
+		},
+		{
+			name: "NSGAII",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				n, _ := NewNSGAII(20, iterationCount, 1, 0.25, iterationCount, clouds, apps)
+				return n
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		start := time.Now()
+		solution, err := testCase.algorithm(clouds, apps).ScheduleContext(ctx, clouds, apps)
+		cancel()
+		assert.Less(t, time.Since(start), 10*time.Second, fmt.Sprintf("%s: the deadline is not honoured", testCase.name))
+		assert.True(t, solution.Truncated, fmt.Sprintf("%s: the solution is not marked as truncated", testCase.name))
+		if err != nil {
+			assert.True(t, errors.Is(err, ErrNoAcceptableSolution), fmt.Sprintf("%s: unexpected error %v", testCase.name, err))
+			assert.True(t, errors.Is(err, context.DeadlineExceeded), fmt.Sprintf("%s: unexpected error %v", testCase.name, err))
+			continue
+		}
+		assert.True(t, Acceptable(model.CloudsCopy(clouds), model.AppsCopy(apps), solution.SchedulingResult), fmt.Sprintf("%s: the truncated solution is not acceptable", testCase.name))
+	}
+}
+
+func TestScheduleContextNotTruncated(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	g, err := NewGenetic(20, 30, 0.4, 0.05, 10, RandomFitSchedule, OnePointCrossOver, PriorityTimeFitness, true, false, clouds, apps)
+	assert.NoError(t, err)
+	solution, err := g.ScheduleContext(context.Background(), clouds, apps)
+	assert.NoError(t, err)
+	assert.False(t, solution.Truncated)
+
+	solution, err = NewFirstFit(clouds, apps).ScheduleContext(context.Background(), clouds, apps)
+	assert.NoError(t, err)
+	assert.False(t, solution.Truncated)
+}
+
+func TestTypedErrors(t *testing.T) {
+	var invalidDependApps []model.Application = forTestSmallApps()
+	invalidDependApps[1].Depend = []model.Dependence{{AppIdx: 4}} // app 1 has a higher priority than app 4
+	var outOfRangeApps []model.Application = forTestSmallApps()
+	outOfRangeApps[7].Depend = []model.Dependence{{AppIdx: 8}}
+	var dirtyApps []model.Application = forTestSmallApps()
+	dirtyApps[2].StableTime = 1.5
+
+	constructors := []struct {
+		name      string
+		algorithm func(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error)
+	}{
+		{
+			name: "Genetic",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error) {
+				return NewGenetic(20, 30, 0.4, 0.05, 10, RandomFitSchedule, OnePointCrossOver, PriorityTimeFitness, true, false, clouds, apps)
+			},
+		},
+		{
+			name: "HAGA",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error) {
+				return NewHAGA(2, 0.6, 20, 30, 0.6, 0.7, 10, clouds, apps)
+			},
+		},
+		{
+			name: "NSGAII",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error) {
+				return NewNSGAII(20, 30, 1, 0.25, 10, clouds, apps)
+			},
+		},
+	}
+	for _, constructor := range constructors {
+		t.Logf("test: %s", constructor.name)
+		clouds := forTestSmallClouds()
+
+		_, err := constructor.algorithm(clouds, invalidDependApps)
+		var depErr *model.InvalidDependencyError
+		if assert.True(t, errors.As(err, &depErr), fmt.Sprintf("%s: %v is not an InvalidDependencyError", constructor.name, err)) {
+			assert.True(t, errors.Is(err, model.ErrInvalidDependency))
+			assert.Equal(t, 1, depErr.AppIdx)
+			assert.Equal(t, 4, depErr.DependAppIdx)
+		}
+
+		_, err = constructor.algorithm(clouds, outOfRangeApps)
+		assert.True(t, errors.Is(err, model.ErrInvalidDependency), fmt.Sprintf("%s: out of range dependency, error %v", constructor.name, err))
+
+		// the constructor does not check time attributes, Schedule does
+		algorithm, err := constructor.algorithm(clouds, dirtyApps)
+		assert.NoError(t, err)
+		_, err = algorithm.Schedule(clouds, dirtyApps)
+		var timeErr *DirtyTimeStateError
+		if assert.True(t, errors.As(err, &timeErr), fmt.Sprintf("%s: %v is not a DirtyTimeStateError", constructor.name, err)) {
+			assert.True(t, errors.Is(err, ErrDirtyTimeState))
+			assert.Equal(t, 2, timeErr.AppIdx)
+			assert.Equal(t, "StableTime", timeErr.Field)
+		}
+	}
+
+	_, err := CalcRemainingApps(forTestSmallClouds(), forTestSmallClouds()[:2], 1)
+	assert.True(t, errors.Is(err, ErrCloudsMismatch), fmt.Sprintf("CalcRemainingApps: unexpected error %v", err))
+}
+
+func TestBrokenInvariant(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	var rejected int = len(clouds)
+
+	// app 3 depends on app 0, so accepting app 3 and rejecting app 0 breaks the invariant left by fixDependence
+	var chromosome Chromosome = Chromosome{rejected, rejected, rejected, 0, rejected, rejected, rejected, rejected}
+	err := checkDependenceFixed(clouds, apps, Population{chromosome}, 5)
+	var invariantErr *BrokenInvariantError
+	if assert.True(t, errors.As(err, &invariantErr), fmt.Sprintf("%v is not a BrokenInvariantError", err)) {
+		assert.True(t, errors.Is(err, ErrBrokenInvariant))
+		assert.Equal(t, 5, invariantErr.Iteration)
+	}
+	fixDependence(clouds, apps, chromosome)
+	assert.NoError(t, checkDependenceFixed(clouds, apps, Population{chromosome}, 5))
+
+	// all apps on the 8-core cloud 2 do not fit
+	assert.True(t, errors.Is(checkBestAcceptable(clouds, apps, Chromosome{2, 2, 2, 2, 2, 2, 2, 2}, 1), ErrBrokenInvariant))
+	assert.NoError(t, checkBestAcceptable(clouds, apps, chromosome, 1))
+}
+
+func TestScheduleStructuredResult(t *testing.T) {
+	testCases := []struct {
+		name      string
+		algorithm func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm
+	}{
+		{
+			name: "MCASGA",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				g, _ := NewGenetic(20, 30, 0.4, 0.05, 10, RandomFitSchedule, OnePointCrossOver, PriorityTimeFitness, true, false, clouds, apps)
+				return g
+			},
+		},
+		{
+			name: "HAGA",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				h, _ := NewHAGA(2, 0.6, 20, 30, 0.6, 0.7, 10, clouds, apps)
+				return h
+			},
+		},
+		{
+			name: "NSGAII",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				n, _ := NewNSGAII(20, 30, 1, 0.25, 10, clouds, apps)
+				return n
+			},
+		},
+		{
+			name: "First Fit",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				return NewFirstFit(clouds, apps)
+			},
+		},
+		{
+			name: "Random Fit",
+			algorithm: func(clouds []model.Cloud, apps []model.Application) SchedulingAlgorithm {
+				return NewRandomFit(clouds, apps)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		apps[0].SvcReq.Memory = 64 * 1024 * 1024 * 1024 // larger than every cloud, so app 0 and app 3 depending on it must be rejected
+		solution, err := testCase.algorithm(clouds, apps).Schedule(clouds, apps)
+		assert.NoError(t, err, fmt.Sprintf("%s: schedule error", testCase.name))
+		assert.Equal(t, testCase.name, solution.Algorithm)
+		assert.NotNil(t, solution.Params, fmt.Sprintf("%s: params are not recorded", testCase.name))
+		assert.Len(t, solution.Placements, len(apps), fmt.Sprintf("%s: wrong number of placements", testCase.name))
+		assert.Len(t, solution.CloudMakespans, len(clouds), fmt.Sprintf("%s: wrong number of makespans", testCase.name))
+
+		var makespans []float64 = make([]float64, len(clouds))
+		for i, placement := range solution.Placements {
+			assert.Equal(t, i, placement.AppIdx)
+			if solution.SchedulingResult[i] == len(clouds) {
+				assert.True(t, placement.Rejected, fmt.Sprintf("%s: app %d should be rejected", testCase.name, i))
+				assert.Equal(t, -1, placement.CloudIdx)
+				assert.NotEqual(t, model.RejectReasonNone, placement.RejectReason, fmt.Sprintf("%s: app %d has no reject reason", testCase.name, i))
+				continue
+			}
+			assert.False(t, placement.Rejected, fmt.Sprintf("%s: app %d should be accepted", testCase.name, i))
+			assert.Equal(t, solution.SchedulingResult[i], placement.CloudIdx)
+			assert.Greater(t, placement.StableTime, 0.0, fmt.Sprintf("%s: app %d has no stable time", testCase.name, i))
+			finishTime := placement.StableTime
+			if apps[i].IsTask {
+				assert.GreaterOrEqual(t, placement.TaskCompletionTime, placement.StableTime, fmt.Sprintf("%s: task %d completes before it is stable", testCase.name, i))
+				finishTime = placement.TaskCompletionTime
+			}
+			if finishTime > makespans[placement.CloudIdx] {
+				makespans[placement.CloudIdx] = finishTime
+			}
+		}
+		assert.Equal(t, makespans, solution.CloudMakespans, fmt.Sprintf("%s: wrong makespans", testCase.name))
+		assert.Equal(t, model.RejectReasonMemory, solution.Placements[0].RejectReason, fmt.Sprintf("%s: wrong reason of app 0", testCase.name))
+		assert.Equal(t, model.RejectReasonDependencyRejected, solution.Placements[3].RejectReason, fmt.Sprintf("%s: wrong reason of app 3", testCase.name))
+	}
+}
+
+func TestHAGAMergedFitness(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	h, err := NewHAGA(4, 0.6, 20, 30, 0.6, 0.7, 10, clouds, apps)
+	assert.NoError(t, err)
+	solution, err := h.Schedule(clouds, apps)
+	assert.NoError(t, err)
+
+	// the fitness depends only on the merged result, not on how the apps were grouped
+	for _, groupNum := range []int{1, 2} {
+		other, err := NewHAGA(groupNum, 0.6, 20, 30, 0.6, 0.7, 10, clouds, apps)
+		assert.NoError(t, err)
+		assert.Equal(t, solution.Fitness, other.mergedFitness(clouds, apps, solution.SchedulingResult), fmt.Sprintf("fitness with %d groups", groupNum))
+	}
+}
+
+func TestPreemptiveExecution(t *testing.T) {
+	// app 0 is a task being executed on cloud 0 since the last round, it needs 1 second with all cores of cloud 0
+	// app 1 is a new task with a higher priority
+	var forTestApps func() []model.Application = func() []model.Application {
+		return []model.Application{
+			{IsTask: true, TaskReq: model.TaskResources{CPUCycle: 40 * 1024 * 1024 * 1024}, Priority: 100, AppIdx: 0, IsNew: false, CloudRemainingOn: 0, ImagePullDone: true, AlreadyStable: true},
+			{IsTask: true, TaskReq: model.TaskResources{CPUCycle: 80 * 1024 * 1024 * 1024}, Priority: 1000, AppIdx: 1, IsNew: true, ImageSize: 1024 * 1024, InputDataSize: 1024 * 1024},
+		}
+	}
+	var schedulingResult []int = []int{0, 0}
+
+	testCases := []struct {
+		name       string
+		preemption model.Preemption
+		resumeTime float64
+		firstApp   int     // the app executed first on cloud 0
+		resumeCost float64 // the time to resume app 0 if it is suspended
+	}{
+		{name: "case 1: default, priority order without resume cost", preemption: model.PreemptionFree, resumeTime: 0.5, firstApp: 1, resumeCost: 0},
+		{name: "case 2: non-preemptive", preemption: model.PreemptionNone, firstApp: 0},
+		{name: "case 3: preemptive", preemption: model.PreemptionSuspend, resumeTime: 0.5, firstApp: 1, resumeCost: 0.5},
+		{name: "case 4: preemptive without resume cost", preemption: model.PreemptionSuspend, resumeTime: 0, firstApp: 1, resumeCost: 0},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds()[:1], forTestApps()
+		clouds[0].Preemption, clouds[0].ResumeTime = testCase.preemption, testCase.resumeTime
+
+		deployedClouds := SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+		timeApps := CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+		secondApp := 1 - testCase.firstApp
+
+		assert.Equal(t, 0.0, timeApps[testCase.firstApp].StartTime, fmt.Sprintf("%s: start time of the first app", testCase.name))
+		assert.InDelta(t, timeApps[testCase.firstApp].TaskCompletionTime, timeApps[secondApp].StartTime, 1e-9, fmt.Sprintf("%s: start time of the second app", testCase.name))
+		if testCase.firstApp == 0 {
+			assert.InDelta(t, 1.0, timeApps[0].TaskCompletionTime, 1e-9, fmt.Sprintf("%s: the executing task is not resumed", testCase.name))
+		} else {
+			assert.InDelta(t, testCase.resumeCost, timeApps[0].StableTime-timeApps[0].StartTime, 1e-9, fmt.Sprintf("%s: resume time", testCase.name))
+			assert.InDelta(t, timeApps[0].StableTime+1, timeApps[0].TaskCompletionTime, 1e-9, fmt.Sprintf("%s: completion time", testCase.name))
+		}
+
+		// the executed CPU cycles are tracked in the next round
+		var timeSinceLastDeploy float64 = timeApps[0].StableTime + 0.25
+		remainingApps, err := CalcRemainingApps(clouds, deployedClouds, timeSinceLastDeploy)
+		assert.NoError(t, err, testCase.name)
+		var found bool
+		for _, remainingApp := range remainingApps {
+			if remainingApp.Priority != apps[0].Priority {
+				continue
+			}
+			found = true
+			assert.InDelta(t, 0.25*apps[0].TaskReq.CPUCycle, remainingApp.ExecutedCPUCycle, 1, fmt.Sprintf("%s: executed CPU cycles", testCase.name))
+			assert.InDelta(t, 0.75*apps[0].TaskReq.CPUCycle, remainingApp.TaskReq.CPUCycle, 1, fmt.Sprintf("%s: remaining CPU cycles", testCase.name))
+		}
+		assert.True(t, found, fmt.Sprintf("%s: the executing task is not remaining", testCase.name))
+	}
+}
+
+func TestTaskParallelism(t *testing.T) {
+	var gib float64 = 1024 * 1024 * 1024
+	// each task needs 1 second with all 16 cores of cloud 0, and 0.07 second (image pulling RTT and data input RTT) before being stable
+	var schedulingResult []int = []int{0, 0}
+
+	testCases := []struct {
+		name         string
+		taskSharing  model.TaskSharing
+		reqs         []model.TaskResources
+		complTimes   []float64 // TaskCompletionTime of app 0 and app 1
+		executedRate float64   // the rate of executed CPU cycles of app 0 after 1 second
+	}{
+		{name: "case 1: no limit", reqs: []model.TaskResources{{}, {}}, complTimes: []float64{1.07, 2.14}, executedRate: 0.93},
+		{name: "case 2: MaxCores", reqs: []model.TaskResources{{MaxCores: 4}, {}}, complTimes: []float64{4.07, 5.14}, executedRate: 0.93 / 4},
+		{name: "case 3: SerialFraction", reqs: []model.TaskResources{{SerialFraction: 0.5}, {}}, complTimes: []float64{8.57, 9.64}, executedRate: 0.93 / 8.5},
+		{name: "case 4: MaxCores of concurrent tasks", taskSharing: model.TaskSharingFair, reqs: []model.TaskResources{{MaxCores: 4}, {}}, complTimes: []float64{0.14 + (1-0.07/4)/0.25, 0.14 + 1/0.75}, executedRate: 0.07/4 + 0.86/4},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds := forTestSmallClouds()[:1]
+		clouds[0].TaskSharing = testCase.taskSharing
+		var apps []model.Application
+		for i, req := range testCase.reqs {
+			req.CPUCycle = 40 * gib
+			apps = append(apps, model.Application{IsTask: true, TaskReq: req, Priority: uint16(2000 - 1000*i), AppIdx: i, IsNew: true})
+		}
+
+		deployedClouds := SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+		timeApps := CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+		for i := 0; i < len(apps); i++ {
+			assert.InDelta(t, testCase.complTimes[i], timeApps[i].TaskCompletionTime, 1e-6, fmt.Sprintf("%s: completion time of app %d", testCase.name, i))
+		}
+
+		remainingApps, err := CalcRemainingApps(clouds, deployedClouds, 1)
+		assert.NoError(t, err, testCase.name)
+		assert.InDelta(t, testCase.executedRate*apps[0].TaskReq.CPUCycle, remainingApps[0].ExecutedCPUCycle, 1e-6*gib, fmt.Sprintf("%s: executed CPU cycles", testCase.name))
+	}
+}
+
+func TestHAGACancelledKeepsRemainingApps(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	// the apps accepted by First Fit are already running on their clouds
+	for appIdx, cloudIdx := range FirstFitSchedule(clouds, apps) {
+		if cloudIdx != len(clouds) {
+			apps[appIdx].IsNew = false
+			apps[appIdx].CloudRemainingOn = cloudIdx
+		}
+	}
+	h, err := NewHAGA(2, 0.6, 20, 30, 0.6, 0.7, 10, clouds, apps)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	solution, err := h.ScheduleContext(ctx, model.CloudsCopy(clouds), model.AppsCopy(apps))
+	assert.NoError(t, err)
+	assert.True(t, solution.Truncated)
+	assert.True(t, Acceptable(model.CloudsCopy(clouds), model.AppsCopy(apps), solution.SchedulingResult), "the solution is not acceptable")
+	for i := 0; i < len(apps); i++ {
+		if !apps[i].IsNew {
+			assert.Equal(t, apps[i].CloudRemainingOn, solution.SchedulingResult[i], fmt.Sprintf("remaining app %d should stay on its cloud", i))
+		}
+	}
+}
+
+func TestScheduleContextAlreadyDone(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var evaluated bool
+	g, err := NewGenetic(20, 30, 0.4, 0.05, 10, RandomFitSchedule, OnePointCrossOver, func(g *Genetic, deployedClouds []model.Cloud, apps []model.Application, chromosome Chromosome) float64 {
+		evaluated = true
+		return PriorityTimeFitness(g, deployedClouds, apps, chromosome)
+	}, true, false, clouds, apps)
+	assert.NoError(t, err)
+	g.Evaluator = NewFitnessEvaluator(1) // only one goroutine writes evaluated
+	n, err := NewNSGAII(20, 30, 1, 0.25, 10, clouds, apps)
+	assert.NoError(t, err)
+
+	for _, algorithm := range []SchedulingAlgorithm{g, n} {
+		solution, err := algorithm.ScheduleContext(ctx, model.CloudsCopy(clouds), model.AppsCopy(apps))
+		assert.True(t, solution.Truncated)
+		assert.True(t, errors.Is(err, ErrNoAcceptableSolution), fmt.Sprintf("error should be ErrNoAcceptableSolution, but it is %v", err))
+		assert.True(t, errors.Is(err, context.Canceled), fmt.Sprintf("error should be context.Canceled, but it is %v", err))
+	}
+	assert.False(t, evaluated, "the population should not be evaluated after ctx is done")
+	assert.Empty(t, n.FitnessRecordIterationBest, "the population should not be evaluated after ctx is done")
+}
@@ -1,8 +1,8 @@
 package algorithms
 
 import (
+	"context"
 	"fmt"
-	"github.com/KeepTheBeats/routing-algorithms/random"
 	"gogeneticwrsp/model"
 	"log"
 	"math"
@@ -40,11 +40,17 @@ type HAGA struct {
 
 	SchedulingResult []int        // The apps are divided into groups, so we should record the current scheduling result
 	CloudPheBind     []idxPheBind // the bind from the cloud index to the pheromone on clouds
+
+	Rand      *Random           // random source of this scheduler, replace it with NewRandom(seed) to replay a run
+	Evaluator *FitnessEvaluator // evaluates the fitness and acceptability of every generation in parallel
 }
 
-func NewHAGA(groupNum int, vmGamma float64, chromosomesCount int, iterationCount int, crossoverProbability float64, mutationProbability float64, stopNoUpdateIteration int, clouds []model.Cloud, apps []model.Application) *HAGA {
+func NewHAGA(groupNum int, vmGamma float64, chromosomesCount int, iterationCount int, crossoverProbability float64, mutationProbability float64, stopNoUpdateIteration int, clouds []model.Cloud, apps []model.Application) (*HAGA, error) {
 	if err := model.DependencyValid(apps); err != nil {
-		log.Panicf("model.DependencyValid(apps), err: %s", err.Error())
+		return nil, err
+	}
+	if err := replicasExpanded(apps); err != nil {
+		return nil, err
 	}
 
 	return &HAGA{
@@ -58,27 +64,22 @@ func NewHAGA(groupNum int, vmGamma float64, chromosomesCount int, iterationCount
 		CrossoverProbability:  crossoverProbability,
 		MutationProbability:   mutationProbability,
 		StopNoUpdateIteration: stopNoUpdateIteration,
-	}
+
+		Rand:      NewTimeSeededRandom(),
+		Evaluator: NewFitnessEvaluator(0),
+	}, nil
 }
 
 func (h *HAGA) Schedule(clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
+	return h.ScheduleContext(context.Background(), clouds, apps)
+}
+
+// ScheduleContext passes ctx to the scheduling of every group. After ctx is done, the current group keeps its best acceptable result if it has found one,
+// and the other groups not scheduled yet are placed with First Fit, so that the remaining apps stay on their clouds.
+func (h *HAGA) ScheduleContext(ctx context.Context, clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
 	// make sure that all time attributes of each app are 0
-	for i := 0; i < len(apps); i++ {
-		if apps[i].StartTime != 0 {
-			log.Panicf("apps[%d].StartTime is %g", i, apps[i].StartTime)
-		}
-		if apps[i].ImagePullDoneTime != 0 {
-			log.Panicf("apps[%d].ImagePullDoneTime is %g", i, apps[i].ImagePullDoneTime)
-		}
-		if apps[i].DataInputDoneTime != 0 {
-			log.Panicf("apps[%d].DataInputDoneTime is %g", i, apps[i].DataInputDoneTime)
-		}
-		if apps[i].StableTime != 0 {
-			log.Panicf("apps[%d].StableTime is %g", i, apps[i].StableTime)
-		}
-		if apps[i].TaskCompletionTime != 0 {
-			log.Panicf("apps[%d].TaskCompletionTime is %g", i, apps[i].TaskCompletionTime)
-		}
+	if err := checkTimeState(apps); err != nil {
+		return model.Solution{Seed: h.Rand.Seed}, err
 	}
 
 	// initialize the scheduling result
@@ -94,6 +95,9 @@ func (h *HAGA) Schedule(clouds []model.Cloud, apps []model.Application) (model.S
 	var thisGroup []int
 	var chosenApps map[int]struct{} = make(map[int]struct{})
 
+	// the replicas of a service are in the same group, because they are placed together
+	var replicaSets [][]int = model.ReplicaSets(apps)
+
 	// return the input idx and all apps have dependence relationship with idx
 	var addDepApps func(int) []int = func(idx int) []int {
 		var depApps []int = []int{idx}
@@ -106,7 +110,7 @@ func (h *HAGA) Schedule(clouds []model.Cloud, apps []model.Application) (model.S
 						continue
 					}
 					// add apps that depend on it or are depended on by it
-					if model.CheckDepend(apps, idx, j) || model.CheckDepend(apps, j, idx) {
+					if model.CheckDepend(apps, idx, j) || model.CheckDepend(apps, j, idx) || (replicaSets != nil && replicaSets[idx] != nil && replicaSets[j] != nil && replicaSets[idx][0] == replicaSets[j][0]) {
 						depApps = append(depApps, j)
 						chosenApps[j] = struct{}{}
 					}
@@ -146,7 +150,13 @@ func (h *HAGA) Schedule(clouds []model.Cloud, apps []model.Application) (model.S
 
 	var appGroup, cloudGroup []int
 	var cloudGroupSize int
+	var truncated bool
+	var unscheduled []int // the apps of the groups not scheduled before ctx is done
 	for i := 0; i < len(groups); i++ {
+		if truncated {
+			unscheduled = append(unscheduled, groups[i]...)
+			continue
+		}
 		appGroup = groups[i]
 		cloudGroup = []int{}
 		if i == 0 {
@@ -165,9 +175,15 @@ func (h *HAGA) Schedule(clouds []model.Cloud, apps []model.Application) (model.S
 		log.Println("To schedule group:", appGroup)
 		log.Println("on the clouds:", cloudGroup)
 
-		thisResult, err := h.scheduleGroup(appGroup, cloudGroup, clouds, apps)
+		var thisResult []int
+		var err error
+		thisResult, truncated, err = h.scheduleGroup(ctx, appGroup, cloudGroup, clouds, apps)
 		if err != nil {
-			log.Panicf("scheduleGroup error: %s", err.Error())
+			return model.Solution{Seed: h.Rand.Seed}, fmt.Errorf("schedule group %d %v: %w", i, appGroup, err)
+		}
+		if thisResult == nil { // ctx was done before an acceptable result of this group was found
+			unscheduled = append(unscheduled, appGroup...)
+			continue
 		}
 
 		// combine thisResult into schedulingResult
@@ -175,8 +191,46 @@ func (h *HAGA) Schedule(clouds []model.Cloud, apps []model.Application) (model.S
 		h.SchedulingResult = h.mergeResults(h.SchedulingResult, thisResult, appGroup)
 
 	}
+	if len(unscheduled) > 0 {
+		sort.Ints(unscheduled)
+		h.SchedulingResult = firstFitPlace(clouds, apps, h.SchedulingResult, unscheduled)
+	}
 
-	return model.Solution{SchedulingResult: h.SchedulingResult}, nil
+	solution := DescribeSolution(clouds, apps, model.Solution{SchedulingResult: h.SchedulingResult, Seed: h.Rand.Seed, Truncated: truncated})
+	solution.Fitness = h.mergedFitness(clouds, apps, h.SchedulingResult)
+	solution.Algorithm = "HAGA"
+	solution.Params = h.params()
+	return solution, nil
+}
+
+// mergedFitness evaluates the merged scheduling result of all groups once, as one group of all apps on all clouds,
+// so that every app is scored against the same reject time instead of the reject time of its own group
+func (h *HAGA) mergedFitness(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+	var allApps, allClouds []int
+	allAppsMap, allCloudsMap := make(map[int]struct{}), make(map[int]struct{})
+	for i := 0; i < len(apps); i++ {
+		allApps = append(allApps, i)
+		allAppsMap[i] = struct{}{}
+	}
+	for i := 0; i < len(clouds); i++ {
+		allClouds = append(allClouds, i)
+		allCloudsMap[i] = struct{}{}
+	}
+	h.initRejectFitness(allAppsMap, allCloudsMap, allApps, allClouds, clouds, apps, Population{schedulingResult})
+	return h.Fitness(allAppsMap, allCloudsMap, allApps, allClouds, clouds, apps, schedulingResult)
+}
+
+// params returns the parameters recorded in the solution
+func (h *HAGA) params() map[string]interface{} {
+	return map[string]interface{}{
+		"groupNum":              h.GroupNum,
+		"vmGamma":               h.VMGamma,
+		"chromosomesCount":      h.ChromosomesCount,
+		"iterationCount":        h.IterationCount,
+		"crossoverProbability":  h.CrossoverProbability,
+		"mutationProbability":   h.MutationProbability,
+		"stopNoUpdateIteration": h.StopNoUpdateIteration,
+	}
 }
 
 // bind a cloud index to its pheromone level
@@ -281,8 +335,9 @@ func (h *HAGA) addPheromone(appGroupMap, cloudGroupMap map[int]struct{}, appGrou
 	}
 }
 
-// schedule a group of applications on some selected clouds
-func (h *HAGA) scheduleGroup(appGroup, cloudGroup []int, clouds []model.Cloud, apps []model.Application) ([]int, error) {
+// schedule a group of applications on some selected clouds, the returned bool means that ctx stopped the scheduling of this group,
+// and the returned result is nil if it stopped before an acceptable result was found
+func (h *HAGA) scheduleGroup(ctx context.Context, appGroup, cloudGroup []int, clouds []model.Cloud, apps []model.Application) ([]int, bool, error) {
 	appGroupMap := make(map[int]struct{})
 	cloudGroupMap := make(map[int]struct{})
 	for i := 0; i < len(appGroup); i++ {
@@ -300,7 +355,9 @@ func (h *HAGA) scheduleGroup(appGroup, cloudGroup []int, clouds []model.Cloud, a
 		}
 		selectableCloudsForApps[i] = append(selectableCloudsForApps[i], len(clouds))
 		for j := 0; j < len(cloudGroup); j++ {
-			selectableCloudsForApps[i] = append(selectableCloudsForApps[i], cloudGroup[j])
+			if CloudMeetApp(clouds[cloudGroup[j]], apps[i]) {
+				selectableCloudsForApps[i] = append(selectableCloudsForApps[i], cloudGroup[j])
+			}
 		}
 		// increase the possibility of rejecting
 		originalLen := len(selectableCloudsForApps[i]) - 1 // how many selectable clouds except for "rejecting"
@@ -326,8 +383,13 @@ func (h *HAGA) scheduleGroup(appGroup, cloudGroup []int, clouds []model.Cloud, a
 	h.BestUntilNow = bestUntilNow
 	h.BestAcceptableUntilNow = bestAcceptableUntilNow
 
-	h.MutationStartingPos = random.RandomInt(0, len(appGroup)-1)
-	h.MutationEndingPos = random.RandomInt(0, len(appGroup)-1)
+	// the initialization evaluates the whole population, which is wasted once ctx is done
+	if ctx.Err() != nil {
+		return nil, true, nil
+	}
+
+	h.MutationStartingPos = h.Rand.RandomInt(0, len(appGroup)-1)
+	h.MutationEndingPos = h.Rand.RandomInt(0, len(appGroup)-1)
 
 	// initialize a population
 	var initPopulation Population = h.initialize(appGroupMap, cloudGroupMap, appGroup, cloudGroup, clouds, apps)
@@ -341,20 +403,21 @@ func (h *HAGA) scheduleGroup(appGroup, cloudGroup []int, clouds []model.Cloud, a
 
 	// No. 1 iteration to No. g.IterationCount iteration
 	for iteration := 1; iteration <= h.IterationCount; iteration++ {
+		// stop searching if the caller cancels the scheduling or the time budget is used up
+		if ctx.Err() != nil {
+			if len(h.BestAcceptableUntilNowUpdateIterations) == 1 {
+				return nil, true, nil
+			}
+			return h.BestAcceptableUntilNow, true, nil
+		}
+
 		currentPopulation = h.crossoverOperator(appGroupMap, cloudGroupMap, appGroup, cloudGroup, clouds, apps, currentPopulation)
 
 		for i := 0; i < len(currentPopulation); i++ {
 			fixDependence(clouds, apps, currentPopulation[i])
-			for j := 0; j < len(currentPopulation[i]); j++ {
-				if currentPopulation[i][j] == len(clouds) {
-					continue
-				}
-				for k := 0; k < len(apps[j].Depend); k++ {
-					if currentPopulation[i][apps[j].Depend[k].AppIdx] == len(clouds) {
-						log.Panicln(j, k, currentPopulation[i][j], currentPopulation[i][apps[j].Depend[k].AppIdx])
-					}
-				}
-			}
+		}
+		if err := checkDependenceFixed(clouds, apps, currentPopulation, iteration); err != nil {
+			return []int{}, false, err
 		}
 
 		// the description in the paper is not clear, so I implement the mutation according to my understanding
@@ -370,14 +433,8 @@ func (h *HAGA) scheduleGroup(appGroup, cloudGroup []int, clouds []model.Cloud, a
 
 		currentPopulation = h.selectionOperator(appGroupMap, cloudGroupMap, appGroup, cloudGroup, clouds, apps, currentPopulation)
 
-		clouds1 := model.CloudsCopy(clouds)
-		apps1 := model.AppsCopy(apps)
-		solution1 := model.SolutionCopy(model.Solution{
-			SchedulingResult: h.BestAcceptableUntilNow,
-		})
-		//log.Println(Acceptable(clouds1, apps1, solution1.SchedulingResult))
-		if !Acceptable(clouds1, apps1, solution1.SchedulingResult) {
-			log.Panicln()
+		if err := checkBestAcceptable(clouds, apps, h.BestAcceptableUntilNow, iteration); err != nil {
+			return []int{}, false, err
 		}
 
 		// if at least one acceptable solution has been found, and if the best fitness until now has not been updated for a certain number of iterations, we think that the solution is already stable enough, and stop the algorithm
@@ -388,12 +445,12 @@ func (h *HAGA) scheduleGroup(appGroup, cloudGroup []int, clouds []model.Cloud, a
 	}
 
 	if len(h.BestAcceptableUntilNowUpdateIterations) == 1 {
-		return []int{}, fmt.Errorf("no acceptable solution is found in %d iterations", h.IterationCount)
+		return []int{}, false, &NoAcceptableSolutionError{Iterations: h.IterationCount}
 	}
 
 	h.addPheromone(appGroupMap, cloudGroupMap, appGroup, cloudGroup, clouds, apps)
 
-	return h.BestAcceptableUntilNow, nil
+	return h.BestAcceptableUntilNow, false, nil
 }
 
 func (h *HAGA) initialize(appGroupMap map[int]struct{}, cloudGroupMap map[int]struct{}, appGroup, cloudGroup []int, clouds []model.Cloud, apps []model.Application) Population {
@@ -431,8 +488,10 @@ func (h *HAGA) randomFitSchedule(appGroupMap map[int]struct{}, cloudGroupMap map
 		}
 	}
 
+	// the apps in other groups keep their results in h.SchedulingResult
+	var state *FeasibilityState = NewFeasibilityState(clouds, apps, h.mergeResults(h.SchedulingResult, schedulingResult, appGroup))
 	for len(undeployed) > 0 {
-		appIndex := random.RandomInt(0, len(undeployed)-1) // appIndex in undeployed
+		appIndex := h.Rand.RandomInt(0, len(undeployed)-1) // appIndex in undeployed
 
 		// traverse clouds in random order
 		var untried []int
@@ -442,22 +501,30 @@ func (h *HAGA) randomFitSchedule(appGroupMap map[int]struct{}, cloudGroupMap map
 			}
 		}
 
+		if replicas := state.replicasOf(undeployed[appIndex]); replicas != nil {
+			if replicas[0] == undeployed[appIndex] && state.placeReplicas(undeployed[appIndex], randomCandidates(h.Rand, clouds, apps[undeployed[appIndex]], untried)) { // the replicas of a service are placed together with the first one
+				var stateResult []int = state.SchedulingResult()
+				for _, replicaIdx := range replicas {
+					schedulingResult[replicaIdx] = stateResult[replicaIdx]
+				}
+			}
+			undeployed = append(undeployed[:appIndex], undeployed[appIndex+1:]...)
+			continue
+		}
+
 		for len(untried) > 0 {
-			cloudIndex := random.RandomInt(0, len(untried)-1) // cloudIndex in untried
+			cloudIndex := h.Rand.RandomInt(0, len(untried)-1) // cloudIndex in untried
 			if !CloudMeetApp(clouds[untried[cloudIndex]], apps[undeployed[appIndex]]) {
 				untried = append(untried[:cloudIndex], untried[cloudIndex+1:]...)
 				continue
 			}
-			schedulingResult[undeployed[appIndex]] = untried[cloudIndex]
-
-			mergedResult := h.mergeResults(h.SchedulingResult, schedulingResult, appGroup)
-
-			if Acceptable(clouds, apps, mergedResult) {
+			if state.TestPlacement(undeployed[appIndex], untried[cloudIndex]) {
+				state.Add(undeployed[appIndex], untried[cloudIndex])
+				schedulingResult[undeployed[appIndex]] = untried[cloudIndex]
 				untried = append(untried[:cloudIndex], untried[cloudIndex+1:]...)
 				break
 			}
 
-			schedulingResult[undeployed[appIndex]] = len(clouds)
 			untried = append(untried[:cloudIndex], untried[cloudIndex+1:]...)
 		}
 
@@ -471,10 +538,16 @@ func (h *HAGA) selectionOperator(appGroupMap, cloudGroupMap map[int]struct{}, ap
 	fitnesses := make([]float64, len(population))
 	cumulativeFitnesses := make([]float64, len(population))
 
-	// calculate the fitness of each chromosome in this population
+	// calculate the fitness and acceptability of each chromosome in this population
+	evaluations := h.Evaluator.EvaluatePopulation(population, func(chromosome Chromosome) Evaluation {
+		return Evaluation{
+			Fitness:    h.Fitness(appGroupMap, cloudGroupMap, appGroup, cloudGroup, clouds, apps, chromosome),
+			Acceptable: Acceptable(clouds, apps, chromosome),
+		}
+	})
 	var maxFitness, minFitness float64 = -math.MaxFloat64, math.MaxFloat64 // record the max and min for standardization
-	for i, chromosome := range population {
-		fitness := h.Fitness(appGroupMap, cloudGroupMap, appGroup, cloudGroup, clouds, apps, chromosome)
+	for i := 0; i < len(population); i++ {
+		fitness := evaluations[i].Fitness
 
 		fitnesses[i] = fitness
 		if fitness > maxFitness {
@@ -497,7 +570,7 @@ func (h *HAGA) selectionOperator(appGroupMap, cloudGroupMap map[int]struct{}, ap
 
 	// make unacceptable chromosomes harder to be selected
 	for i := 0; i < len(fitnesses); i++ {
-		if !Acceptable(clouds, apps, population[i]) {
+		if !evaluations[i].Acceptable {
 			fitnesses[i] /= 3
 		}
 	}
@@ -520,7 +593,7 @@ func (h *HAGA) selectionOperator(appGroupMap, cloudGroupMap map[int]struct{}, ap
 
 		// roulette-wheel selection
 		// selectionThreshold is a random float64 in [0, biggest cumulative fitness])
-		selectionThreshold := random.RandomFloat64(0, cumulativeFitnesses[len(cumulativeFitnesses)-1])
+		selectionThreshold := h.Rand.RandomFloat64(0, cumulativeFitnesses[len(cumulativeFitnesses)-1])
 		// selected the smallest cumulativeFitnesses that is begger than selectionThreshold
 		selectedChromosomeIndex = sort.SearchFloat64s(cumulativeFitnesses, selectionThreshold)
 		//log.Printf("selectionThreshold %f,selectedChromosomeIndex %d", selectionThreshold, selectedChromosomeIndex)
@@ -531,13 +604,13 @@ func (h *HAGA) selectionOperator(appGroupMap, cloudGroupMap map[int]struct{}, ap
 		newPopulation = append(newPopulation, newChromosome)
 
 		// record the best fitness in this iteration
-		chosenFitness := h.Fitness(appGroupMap, cloudGroupMap, appGroup, cloudGroup, clouds, apps, population[selectedChromosomeIndex])
+		chosenFitness := evaluations[selectedChromosomeIndex].Fitness
 		//log.Printf("selectedChromosomeIndex %d, population[selectedChromosomeIndex] %d, chosenFitness %f", selectedChromosomeIndex, population[selectedChromosomeIndex], chosenFitness)
 
 		if chosenFitness > bestFitnessInThisIteration {
 			bestFitnessInThisIteration = chosenFitness
 			bestFitnessInThisIterationIndex = selectedChromosomeIndex
-			if Acceptable(clouds, apps, population[selectedChromosomeIndex]) {
+			if evaluations[selectedChromosomeIndex].Acceptable {
 				bestAcceptableFitnessInThisIteration = chosenFitness
 				bestAcceptableFitnessInThisIterationIndex = selectedChromosomeIndex
 			}
@@ -625,7 +698,7 @@ func (h *HAGA) calculateTaskTime(appGroupMap, cloudGroupMap map[int]struct{}, ap
 			clouds[cloudIndex].RunningApps[i].StartTime = latestStartTime
 			unorderedApps[appIdx].StartTime = latestStartTime
 
-			execTime := clouds[cloudIndex].RunningApps[i].TaskReq.CPUCycle / (clouds[cloudIndex].TmpAlloc.CPU.LogicalCores * clouds[cloudIndex].TmpAlloc.CPU.BaseClock * 1024 * 1024 * 1024) // unit: second
+			execTime := taskExecTime(clouds[cloudIndex], clouds[cloudIndex].RunningApps[i])
 
 			clouds[cloudIndex].TotalTaskComplTime += execTime
 			clouds[cloudIndex].RunningApps[i].TaskCompletionTime = clouds[cloudIndex].TotalTaskComplTime
@@ -692,7 +765,7 @@ func (h *HAGA) crossoverOperator(appGroupMap, cloudGroupMap map[int]struct{}, ap
 	// traverse all chromosomes in this population, use random to judge whether a chromosome needs crossover
 	var indexesNeedCrossover []int
 	for i := 0; i < len(copyPopulation); i++ {
-		if random.RandomFloat64(0, 1) < h.CrossoverProbability {
+		if h.Rand.RandomFloat64(0, 1) < h.CrossoverProbability {
 			indexesNeedCrossover = append(indexesNeedCrossover, i)
 		}
 	}
@@ -706,12 +779,12 @@ func (h *HAGA) crossoverOperator(appGroupMap, cloudGroupMap map[int]struct{}, ap
 		// delete them from indexesNeedCrossover;
 		// mark them in whetherCrossover
 		// first index
-		first := random.RandomInt(0, len(indexesNeedCrossover)-1)
+		first := h.Rand.RandomInt(0, len(indexesNeedCrossover)-1)
 		firstIndex := indexesNeedCrossover[first]
 		whetherCrossover[firstIndex] = true                                                            // mark
 		indexesNeedCrossover = append(indexesNeedCrossover[:first], indexesNeedCrossover[first+1:]...) // delete
 		// second index
-		second := random.RandomInt(0, len(indexesNeedCrossover)-1)
+		second := h.Rand.RandomInt(0, len(indexesNeedCrossover)-1)
 		secondIndex := indexesNeedCrossover[second]
 		whetherCrossover[secondIndex] = true                                                             // mark
 		indexesNeedCrossover = append(indexesNeedCrossover[:second], indexesNeedCrossover[second+1:]...) // delete
@@ -719,7 +792,7 @@ func (h *HAGA) crossoverOperator(appGroupMap, cloudGroupMap map[int]struct{}, ap
 		firstChromosome := copyPopulation[firstIndex]
 		secondChromosome := copyPopulation[secondIndex]
 
-		newFirstChromosome, newSecondChromosome := TwoPointCrossOver(firstChromosome, secondChromosome)
+		newFirstChromosome, newSecondChromosome := TwoPointCrossOver(h.Rand, firstChromosome, secondChromosome)
 
 		// append the two new chromosomes in newPopulation
 		newPopulation = append(newPopulation, newFirstChromosome, newSecondChromosome)
@@ -739,7 +812,7 @@ func (h *HAGA) crossoverOperator(appGroupMap, cloudGroupMap map[int]struct{}, ap
 func (h *HAGA) mutationOperator(appGroupMap, cloudGroupMap map[int]struct{}, appGroup, cloudGroup []int, clouds []model.Cloud, apps []model.Application, population Population) Population {
 	var copyPopulation Population = PopulationCopy(population)
 	for i := 0; i < len(copyPopulation); i++ {
-		if random.RandomFloat64(0, 1) < h.MutationProbability {
+		if h.Rand.RandomFloat64(0, 1) < h.MutationProbability {
 			// the description in the paper is not clear, so I implement the mutation to my understanding
 			pos1 := appGroup[h.MutationStartingPos]
 			pos2 := appGroup[h.MutationEndingPos]
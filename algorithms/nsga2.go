@@ -1,8 +1,7 @@
 package algorithms
 
 import (
-	"fmt"
-	"github.com/KeepTheBeats/routing-algorithms/random"
+	"context"
 	"github.com/wcharczuk/go-chart"
 	"gogeneticwrsp/model"
 	"log"
@@ -10,6 +9,7 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 )
 
 type NSGAII struct {
@@ -31,13 +31,29 @@ type NSGAII struct {
 
 	SelectableCloudsForApps [][]int
 
+	ParetoFront        Population      // the acceptable chromosomes on the first front after scheduling, the whole front instead of one chromosome
+	ParetoFrontFitness []NSGAIIFitness // the objectives of each chromosome in ParetoFront
+
+	ParetoFrontUpdateIterations []float64 // the iterations in which the objectives on the acceptable first front of the population changed, used for the stop condition
+	paretoFrontKey              string    // the objectives on the acceptable first front of the last iteration
+
 	RejectRepairTime      float64 // We set this as the RepairTime of rejected applications
 	RejectLatencyOverhead float64 // We set this as the LatencyOverhead of rejected applications
+
+	Rand      *Random           // random source of this scheduler, replace it with NewRandom(seed) to replay a run
+	Evaluator *FitnessEvaluator // evaluates the fitness and acceptability of every generation in parallel
+
+	// optional objectives sorted together with repairTime and latencyOverhead, e.g. ObjectiveCost.
+	// They change the fronts, the crowding distance and the returned scheduling result, but not the weighted sum of PrintFitness in the chart.
+	ExtraObjectives []Objective
 }
 
-func NewNSGAII(chromosomesCount int, iterationCount int, crossoverProbability float64, mutationProbability float64, stopNoUpdateIteration int, clouds []model.Cloud, apps []model.Application) *NSGAII {
+func NewNSGAII(chromosomesCount int, iterationCount int, crossoverProbability float64, mutationProbability float64, stopNoUpdateIteration int, clouds []model.Cloud, apps []model.Application) (*NSGAII, error) {
 	if err := model.DependencyValid(apps); err != nil {
-		log.Panicf("model.DependencyValid(apps), err: %s", err.Error())
+		return nil, err
+	}
+	if err := replicasExpanded(apps); err != nil {
+		return nil, err
 	}
 
 	selectableCloudsForApps := make([][]int, len(apps))
@@ -89,101 +105,145 @@ func NewNSGAII(chromosomesCount int, iterationCount int, crossoverProbability fl
 		BestUntilNowUpdateIterations:           []float64{-1}, // We define that the first BestUntilNow is set in the No. -1 iteration
 		BestAcceptableUntilNowUpdateIterations: []float64{-1},
 		SelectableCloudsForApps:                selectableCloudsForApps,
-	}
+		Rand:                                   NewTimeSeededRandom(),
+		Evaluator:                              NewFitnessEvaluator(0),
+	}, nil
 }
 
 func (n *NSGAII) Schedule(clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
+	return n.ScheduleContext(context.Background(), clouds, apps)
+}
+
+// ScheduleContext checks ctx before the initialization and every iteration, and returns the front found until then as a truncated solution once ctx is done.
+// The scheduling result of the solution is the point on the Pareto front chosen by ChooseByWeights with the same weight for all objectives.
+func (n *NSGAII) ScheduleContext(ctx context.Context, clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
 	// make sure that all time attributes of each app are 0
-	for i := 0; i < len(apps); i++ {
-		if apps[i].StartTime != 0 {
-			log.Panicf("apps[%d].StartTime is %g", i, apps[i].StartTime)
-		}
-		if apps[i].ImagePullDoneTime != 0 {
-			log.Panicf("apps[%d].ImagePullDoneTime is %g", i, apps[i].ImagePullDoneTime)
-		}
-		if apps[i].DataInputDoneTime != 0 {
-			log.Panicf("apps[%d].DataInputDoneTime is %g", i, apps[i].DataInputDoneTime)
-		}
-		if apps[i].StableTime != 0 {
-			log.Panicf("apps[%d].StableTime is %g", i, apps[i].StableTime)
-		}
-		if apps[i].TaskCompletionTime != 0 {
-			log.Panicf("apps[%d].TaskCompletionTime is %g", i, apps[i].TaskCompletionTime)
-		}
+	if err := checkTimeState(apps); err != nil {
+		return model.Solution{Seed: n.Rand.Seed}, err
+	}
+	// the initialization evaluates the whole population, which is wasted once ctx is done
+	if ctx.Err() != nil {
+		return model.Solution{Seed: n.Rand.Seed, Truncated: true}, &NoAcceptableSolutionError{Cause: ctx.Err()}
 	}
 
 	// initialize a population
-	var initPopulation Population = n.initialize(clouds, apps)
-	//for i, chromosome := range initPopulation {
+	var population Population = n.initialize(clouds, apps)
+	//for i, chromosome := range population {
 	//	log.Println(i, chromosome, len(chromosome))
 	//}
 
-	n.initRejectFitness(clouds, apps, initPopulation)
+	n.initRejectFitness(clouds, apps, population)
 	log.Println("n.RejectRepairTime:", n.RejectRepairTime, "n.RejectLatencyOverhead:", n.RejectLatencyOverhead)
 
-	currentPopulation := n.selectionOperator(clouds, apps, initPopulation) // Iteration No. 0
+	// Iteration No. 0
+	evaluations := n.evaluate(clouds, apps, population)
+	ranks, distances := rankAndCrowding(evaluations, FastNonDominatedSort(evaluations))
+	n.recordIteration(population, evaluations)
+	n.recordParetoFront(evaluations)
 
 	// No. 1 iteration to No. g.IterationCount iteration
+	var truncated bool
 	for iteration := 1; iteration <= n.IterationCount; iteration++ {
+		// stop searching if the caller cancels the scheduling or the time budget is used up
+		if ctx.Err() != nil {
+			truncated = true
+			break
+		}
+
+		//log.Printf("--------mating selection in iteration %d-------\n", iteration)
+		offspring := n.matingSelection(population, ranks, distances)
+
 		//log.Printf("---crossover in iteration %d-------\n", iteration)
-		currentPopulation = n.crossoverOperator(clouds, apps, currentPopulation)
-		//for i, chromosome := range currentPopulation {
+		offspring = n.crossoverOperator(clouds, apps, offspring)
+		//for i, chromosome := range offspring {
 		//	log.Println(i, chromosome)
 		//}
 
-		for i := 0; i < len(currentPopulation); i++ {
-			fixDependence(clouds, apps, currentPopulation[i])
-			for j := 0; j < len(currentPopulation[i]); j++ {
-				if currentPopulation[i][j] == len(clouds) {
-					continue
-				}
-				for k := 0; k < len(apps[j].Depend); k++ {
-					if currentPopulation[i][apps[j].Depend[k].AppIdx] == len(clouds) {
-						log.Panicln(j, k, currentPopulation[i][j], currentPopulation[i][apps[j].Depend[k].AppIdx])
-					}
-				}
-			}
+		for i := 0; i < len(offspring); i++ {
+			fixDependence(clouds, apps, offspring[i])
+		}
+		if err := checkDependenceFixed(clouds, apps, offspring, iteration); err != nil {
+			return model.Solution{Seed: n.Rand.Seed}, err
 		}
 
 		//log.Printf("--------mutation in iteration %d-------\n", iteration)
-		currentPopulation = n.mutationOperator(clouds, apps, currentPopulation)
-		//for i, chromosome := range currentPopulation {
-		//	log.Println(i, chromosome)
-		//}
-		//log.Printf("--------selection in iteration %d-------\n", iteration)
-		currentPopulation = n.selectionOperator(clouds, apps, currentPopulation)
-		//for i, chromosome := range currentPopulation {
+		offspring = n.mutationOperator(clouds, apps, offspring)
+		//for i, chromosome := range offspring {
 		//	log.Println(i, chromosome)
 		//}
 
-		clouds1 := model.CloudsCopy(clouds)
-		apps1 := model.AppsCopy(apps)
-		solution1 := model.SolutionCopy(model.Solution{
-			SchedulingResult: n.BestAcceptableUntilNow,
-		})
-		//log.Println(Acceptable(clouds1, apps1, solution1.SchedulingResult))
-		if !Acceptable(clouds1, apps1, solution1.SchedulingResult) {
-			log.Panicln()
+		//log.Printf("--------survivor selection in iteration %d-------\n", iteration)
+		population, evaluations = n.survivorSelection(population, evaluations, offspring, n.evaluate(clouds, apps, offspring))
+		ranks, distances = rankAndCrowding(evaluations, FastNonDominatedSort(evaluations))
+		n.recordIteration(population, evaluations)
+		n.recordParetoFront(evaluations)
+
+		if err := checkBestAcceptable(clouds, apps, n.BestAcceptableUntilNow, iteration); err != nil {
+			return model.Solution{Seed: n.Rand.Seed}, err
 		}
 
-		// if at least one acceptable solution has been found, and if the best fitness until now has not been updated for a certain number of iterations, we think that the solution is already stable enough, and stop the algorithm
-		if len(n.BestAcceptableUntilNowUpdateIterations) > 1 && float64(iteration)-n.BestUntilNowUpdateIterations[len(n.BestUntilNowUpdateIterations)-1] > float64(n.StopNoUpdateIteration) {
+		// if at least one acceptable solution has been found, and if the acceptable first front has not changed for a certain number of iterations, we think that the solution is already stable enough, and stop the algorithm
+		if len(n.ParetoFrontUpdateIterations) > 0 && float64(iteration)-n.ParetoFrontUpdateIterations[len(n.ParetoFrontUpdateIterations)-1] > float64(n.StopNoUpdateIteration) {
 			break
 		}
 	}
 
 	if len(n.BestAcceptableUntilNowUpdateIterations) == 1 {
-		return model.Solution{}, fmt.Errorf("no acceptable solution is found in %d iterations", n.IterationCount)
+		if truncated {
+			return model.Solution{Seed: n.Rand.Seed, Truncated: true}, &NoAcceptableSolutionError{Iterations: len(n.FitnessRecordIterationBestAcceptable) - 1, Cause: ctx.Err()}
+		}
+		return model.Solution{Seed: n.Rand.Seed}, &NoAcceptableSolutionError{Iterations: n.IterationCount}
+	}
+
+	n.updateParetoFront(clouds, apps, population, evaluations)
+	var solution model.Solution = model.Solution{Seed: n.Rand.Seed, Truncated: truncated}
+	solution.ObjectiveNames = []string{"repairTime", "latencyOverhead"}
+	for _, objective := range n.ExtraObjectives {
+		solution.ObjectiveNames = append(solution.ObjectiveNames, objective.Name)
+	}
+	for i := 0; i < len(n.ParetoFront); i++ {
+		solution.ParetoFront = append(solution.ParetoFront, model.ParetoPoint{
+			SchedulingResult: ChromosomeCopy(n.ParetoFront[i]),
+			Objectives:       n.ParetoFrontFitness[i].Objectives(),
+		})
+	}
+	chosen, err := ChooseByWeights(solution, nil)
+	if err != nil {
+		return solution, err
 	}
+	solution.SchedulingResult = chosen.SchedulingResult
+
+	solution = DescribeSolution(clouds, apps, solution)
+	solution.Fitness = n.Fitness(clouds, apps, solution.SchedulingResult).PrintFitness()
+	solution.Algorithm = "NSGAII"
+	solution.Params = n.params()
+	return solution, nil
+}
 
-	return model.Solution{SchedulingResult: n.BestAcceptableUntilNow}, nil
+// params returns the parameters recorded in the solution
+func (n *NSGAII) params() map[string]interface{} {
+	var params map[string]interface{} = map[string]interface{}{
+		"chromosomesCount":      n.ChromosomesCount,
+		"iterationCount":        n.IterationCount,
+		"crossoverProbability":  n.CrossoverProbability,
+		"mutationProbability":   n.MutationProbability,
+		"stopNoUpdateIteration": n.StopNoUpdateIteration,
+	}
+	if len(n.ExtraObjectives) > 0 {
+		var names []string
+		for _, objective := range n.ExtraObjectives {
+			names = append(names, objective.Name)
+		}
+		params["extraObjectives"] = names
+	}
+	return params
 }
 
 func (n *NSGAII) initialize(clouds []model.Cloud, apps []model.Application) Population {
 	var initPopulation Population
 	// in a population, there are g.ChromosomesCount chromosomes (individuals)
 	for i := 0; i < n.ChromosomesCount; i++ {
-		var chromosome Chromosome = RandomFitSchedule(clouds, apps)
+		var chromosome Chromosome = RandomFitSchedule(n.Rand, clouds, apps)
 		initPopulation = append(initPopulation, chromosome)
 	}
 	return initPopulation
@@ -197,42 +257,68 @@ func (n *NSGAII) averageFitness(clouds []model.Cloud, apps []model.Application,
 	return sumFitness / float64(len(population))
 }
 
-func (n *NSGAII) selectionOperator(clouds []model.Cloud, apps []model.Application, population Population) Population {
-	fitnesses := make([]NSGAIIFitness, len(population))
-	for i, chromosome := range population {
-		fitnesses[i] = n.Fitness(clouds, apps, chromosome)
-	}
-
-	tmpForPick := make([]int, len(fitnesses))
-	var newPopulation Population = make(Population, len(population))
-	var bestFitnessInThisIteration float64 = -1
-	var bestFitnessInThisIterationIndex int
-	var bestAcceptableFitnessInThisIteration float64 = -1
-	var bestAcceptableFitnessInThisIterationIndex int
+// evaluate calculates the objectives and the acceptability of every chromosome in population
+func (n *NSGAII) evaluate(clouds []model.Cloud, apps []model.Application, population Population) []Evaluation {
+	return n.Evaluator.EvaluatePopulation(population, func(chromosome Chromosome) Evaluation {
+		nf := n.Fitness(clouds, apps, chromosome)
+		if len(n.ExtraObjectives) > 0 {
+			nf.Extra = objectiveValues(n.ExtraObjectives, clouds, apps, chromosome)
+		}
+		return Evaluation{
+			Fitness:    nf.PrintFitness(),
+			Objectives: nf.Objectives(),
+			Acceptable: Acceptable(clouds, apps, chromosome),
+		}
+	})
+}
 
+// matingSelection chooses the parents of the offspring with binary tournament on the crowded-comparison operator
+func (n *NSGAII) matingSelection(population Population, ranks []int, distances []float64) Population {
+	tmpForPick := make([]int, len(population))
+	var matingPool Population = make(Population, n.ChromosomesCount)
 	for i := 0; i < n.ChromosomesCount; i++ {
-		picked := random.RandomPickN(tmpForPick, 2)
+		picked := n.Rand.RandomPickN(tmpForPick, 2)
 		var selectedChromosomeIndex int
-		if fitnesses[picked[0]].NfLess(fitnesses[picked[1]]) {
+		if crowdedLess(ranks, distances, picked[0], picked[1]) {
 			selectedChromosomeIndex = picked[0]
 		} else {
 			selectedChromosomeIndex = picked[1]
 		}
-		newChromosome := make(Chromosome, len(population[selectedChromosomeIndex]))
-		copy(newChromosome, population[selectedChromosomeIndex])
-		newPopulation[i] = newChromosome
-
-		chosenFitness := n.Fitness(clouds, apps, newChromosome).PrintFitness()
-
-		if bestFitnessInThisIteration < 0 || chosenFitness < bestFitnessInThisIteration {
-			bestFitnessInThisIteration = chosenFitness
-			bestFitnessInThisIterationIndex = selectedChromosomeIndex
-			if Acceptable(clouds, apps, population[selectedChromosomeIndex]) {
-				bestAcceptableFitnessInThisIteration = chosenFitness
-				bestAcceptableFitnessInThisIterationIndex = selectedChromosomeIndex
-			}
-		}
+		matingPool[i] = ChromosomeCopy(population[selectedChromosomeIndex])
+	}
+	return matingPool
+}
 
+// survivorSelection merges the parents and the offspring, and keeps the best n.ChromosomesCount chromosomes as the next population ((μ+λ) elitism)
+func (n *NSGAII) survivorSelection(parents Population, parentEvaluations []Evaluation, offspring Population, offspringEvaluations []Evaluation) (Population, []Evaluation) {
+	var combined Population = append(append(Population{}, parents...), offspring...)
+	var combinedEvaluations []Evaluation = append(append([]Evaluation{}, parentEvaluations...), offspringEvaluations...)
+
+	survivors := elitistSurvivors(combinedEvaluations, FastNonDominatedSort(combinedEvaluations), n.ChromosomesCount)
+	var newPopulation Population = make(Population, len(survivors))
+	var newEvaluations []Evaluation = make([]Evaluation, len(survivors))
+	for i, idx := range survivors {
+		newPopulation[i] = ChromosomeCopy(combined[idx])
+		newEvaluations[i] = combinedEvaluations[idx]
+	}
+	return newPopulation, newEvaluations
+}
+
+// recordIteration records the best scalar fitness (PrintFitness) of the population of this iteration, which is used for the chart
+func (n *NSGAII) recordIteration(population Population, evaluations []Evaluation) {
+	var bestFitnessInThisIteration float64 = -1
+	var bestFitnessInThisIterationIndex int
+	var bestAcceptableFitnessInThisIteration float64 = -1
+	var bestAcceptableFitnessInThisIterationIndex int
+	for i := 0; i < len(population); i++ {
+		if bestFitnessInThisIteration < 0 || evaluations[i].Fitness < bestFitnessInThisIteration {
+			bestFitnessInThisIteration = evaluations[i].Fitness
+			bestFitnessInThisIterationIndex = i
+		}
+		if evaluations[i].Acceptable && (bestAcceptableFitnessInThisIteration < 0 || evaluations[i].Fitness < bestAcceptableFitnessInThisIteration) {
+			bestAcceptableFitnessInThisIteration = evaluations[i].Fitness
+			bestAcceptableFitnessInThisIterationIndex = i
+		}
 	}
 
 	// acceptable and non-acceptable
@@ -250,14 +336,76 @@ func (n *NSGAII) selectionOperator(clouds []model.Cloud, apps []model.Applicatio
 		n.FitnessRecordBestAcceptableUntilNow = append(n.FitnessRecordBestAcceptableUntilNow, bestAcceptableFitnessInThisIteration)
 		n.BestAcceptableUntilNowUpdateIterations = append(n.BestAcceptableUntilNowUpdateIterations, float64(len(n.FitnessRecordIterationBestAcceptable)-1))
 	}
-	//log.Println("Average New Population:", n.averageFitness(clouds, apps, newPopulation))
+}
 
-	return newPopulation
+// recordParetoFront records the iteration if the objectives on the acceptable first front of the population differ from the last iteration
+func (n *NSGAII) recordParetoFront(evaluations []Evaluation) {
+	var points []string
+	var seen map[string]struct{} = make(map[string]struct{})
+	for _, idx := range acceptableFront(evaluations) {
+		var b strings.Builder
+		for _, objective := range evaluations[idx].Objectives {
+			b.WriteString(strconv.FormatFloat(objective, 'g', -1, 64))
+			b.WriteByte(',')
+		}
+		if _, exist := seen[b.String()]; exist {
+			continue
+		}
+		seen[b.String()] = struct{}{}
+		points = append(points, b.String())
+	}
+	sort.Strings(points)
+	key := strings.Join(points, ";")
+	if key != n.paretoFrontKey {
+		n.paretoFrontKey = key
+		n.ParetoFrontUpdateIterations = append(n.ParetoFrontUpdateIterations, float64(len(n.FitnessRecordIterationBest)-1))
+	}
+}
+
+// acceptableFront returns the indices of the acceptable evaluations that are not dominated by any other acceptable evaluation
+func acceptableFront(evaluations []Evaluation) []int {
+	var acceptableIndices []int
+	var acceptableEvaluations []Evaluation
+	for i := 0; i < len(evaluations); i++ {
+		if evaluations[i].Acceptable {
+			acceptableIndices = append(acceptableIndices, i)
+			acceptableEvaluations = append(acceptableEvaluations, evaluations[i])
+		}
+	}
+	if len(acceptableEvaluations) == 0 {
+		return nil
+	}
+	var front []int
+	for _, idx := range FastNonDominatedSort(acceptableEvaluations)[0] {
+		front = append(front, acceptableIndices[idx])
+	}
+	return front
+}
+
+// updateParetoFront sets n.ParetoFront to the distinct chromosomes in the acceptable first front of the final population.
+// n.BestAcceptableUntilNow is added as a candidate, so that the front is not empty once an acceptable chromosome has been found.
+func (n *NSGAII) updateParetoFront(clouds []model.Cloud, apps []model.Application, population Population, evaluations []Evaluation) {
+	var candidates Population = append(Population{ChromosomeCopy(n.BestAcceptableUntilNow)}, population...)
+	var candidateEvaluations []Evaluation = append(n.evaluate(clouds, apps, candidates[:1]), evaluations...)
+
+	n.ParetoFront = nil
+	n.ParetoFrontFitness = nil
+	var seen map[string]struct{} = make(map[string]struct{})
+	for _, idx := range acceptableFront(candidateEvaluations) {
+		key := chromosomeKey(candidates[idx])
+		if _, exist := seen[key]; exist {
+			continue
+		}
+		seen[key] = struct{}{}
+		n.ParetoFront = append(n.ParetoFront, ChromosomeCopy(candidates[idx]))
+		n.ParetoFrontFitness = append(n.ParetoFrontFitness, NSGAIIFitness{RepairTime: candidateEvaluations[idx].Objectives[0], LatencyOverhead: candidateEvaluations[idx].Objectives[1], Extra: candidateEvaluations[idx].Objectives[2:]})
+	}
 }
 
 type NSGAIIFitness struct {
 	RepairTime      float64
 	LatencyOverhead float64
+	Extra           []float64 // the values of NSGAII.ExtraObjectives
 }
 
 // uniform weighted sum of the paper
@@ -265,6 +413,11 @@ func (nf NSGAIIFitness) PrintFitness() float64 {
 	return 0.5*0.5*nf.RepairTime + 0.5*0.5*nf.LatencyOverhead
 }
 
+// Objectives returns the two objectives of NSGA-II followed by the extra ones, all are minimized
+func (nf NSGAIIFitness) Objectives() []float64 {
+	return append([]float64{nf.RepairTime, nf.LatencyOverhead}, nf.Extra...)
+}
+
 func (nf NSGAIIFitness) NfLess(cmp NSGAIIFitness) bool {
 	return nf.PrintFitness() < cmp.PrintFitness()
 }
@@ -376,27 +529,24 @@ func (n *NSGAII) Fitness(clouds []model.Cloud, apps []model.Application, chromos
 	//time.Sleep(101 * time.Second)
 
 	var repairTime, latencyOverhead float64
+	var replicaSets [][]int = model.ReplicaSets(apps)
 
 	// the fitnessValue is based on each application
 	for appIndex := 0; appIndex < len(chromosome); appIndex++ {
-		thirRepairTime, thisLatencyOverhead := n.fitnessOneApp(deployedClouds, appsCopy, appIndex, chromosome)
+		thirRepairTime, thisLatencyOverhead := n.fitnessOneApp(deployedClouds, appsCopy, replicaSets, appIndex, chromosome)
 		repairTime += thirRepairTime
 		latencyOverhead += thisLatencyOverhead
 	}
 
-	return NSGAIIFitness{repairTime, latencyOverhead}
+	return NSGAIIFitness{RepairTime: repairTime, LatencyOverhead: latencyOverhead}
 }
 
-func (n *NSGAII) fitnessOneApp(clouds []model.Cloud, apps []model.Application, appIdx int, chromosome Chromosome) (float64, float64) {
+func (n *NSGAII) fitnessOneApp(clouds []model.Cloud, apps []model.Application, replicaSets [][]int, appIdx int, chromosome Chromosome) (float64, float64) {
 	if chromosome[appIdx] == len(clouds) || apps[appIdx].IsTask { // NSGA-II only considers services
 		return n.RejectRepairTime, n.RejectLatencyOverhead
 	}
 	var repairTime float64 = apps[appIdx].StableTime - (apps[appIdx].DataInputDoneTime - apps[appIdx].ImagePullDoneTime) // NSGA-II does not consider data input time
-	var latencyOverhead float64
-	for i := 0; i < len(apps[appIdx].Depend); i++ {
-		depIdx := apps[appIdx].Depend[i].AppIdx
-		latencyOverhead += clouds[chromosome[appIdx]].Allocatable.NetCondClouds[chromosome[depIdx]].RTT
-	}
+	var latencyOverhead float64 = latencyOverheadOneApp(clouds, apps, replicaSets, appIdx, chromosome)
 	// reject ones should have the biggest fitness
 	if repairTime > n.RejectRepairTime {
 		repairTime = n.RejectRepairTime
@@ -408,27 +558,36 @@ func (n *NSGAII) fitnessOneApp(clouds []model.Cloud, apps []model.Application, a
 }
 
 // used for initialization of RejectRepairTime and RejectLatencyOverhead
-func (n *NSGAII) fitnessOneAppInit(clouds []model.Cloud, apps []model.Application, appIdx int, chromosome Chromosome) (float64, float64) {
+func (n *NSGAII) fitnessOneAppInit(clouds []model.Cloud, apps []model.Application, replicaSets [][]int, appIdx int, chromosome Chromosome) (float64, float64) {
 	if chromosome[appIdx] == len(clouds) {
 		return -1, -1
 	}
 	var repairTime float64 = apps[appIdx].StableTime - (apps[appIdx].DataInputDoneTime - apps[appIdx].ImagePullDoneTime) // NSGA-II does not consider data input time
+	return repairTime, latencyOverheadOneApp(clouds, apps, replicaSets, appIdx, chromosome)
+}
+
+// latencyOverheadOneApp is the sum of the RTT from the cloud of apps[appIdx] to the clouds serving its dependent apps, a replicated service is served by its nearest replica
+func latencyOverheadOneApp(clouds []model.Cloud, apps []model.Application, replicaSets [][]int, appIdx int, chromosome Chromosome) float64 {
 	var latencyOverhead float64
 	for i := 0; i < len(apps[appIdx].Depend); i++ {
-		depIdx := apps[appIdx].Depend[i].AppIdx
-		latencyOverhead += clouds[chromosome[appIdx]].Allocatable.NetCondClouds[chromosome[depIdx]].RTT
+		dependCloudIdx := servingCloud(clouds, replicaSets, chromosome, apps[appIdx].Depend[i].AppIdx, chromosome[appIdx])
+		if dependCloudIdx == len(clouds) {
+			continue
+		}
+		latencyOverhead += clouds[chromosome[appIdx]].Allocatable.NetCondClouds[dependCloudIdx].RTT
 	}
-	return repairTime, latencyOverhead
+	return latencyOverhead
 }
 
 func (n *NSGAII) initRejectFitness(clouds []model.Cloud, apps []model.Application, initPopulation Population) {
 	var repairTimes, latencyOverheads []float64
+	var replicaSets [][]int = model.ReplicaSets(apps)
 	for _, chromosome := range initPopulation {
 		deployedClouds := SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: chromosome})
 		appsCopy := model.AppsCopy(apps)
 		appsCopy = NSGAIICalcStartComplTime(deployedClouds, appsCopy, chromosome)
 		for i := 0; i < len(apps); i++ {
-			thisRepairTime, thisLatencyOverhead := n.fitnessOneAppInit(deployedClouds, appsCopy, i, chromosome)
+			thisRepairTime, thisLatencyOverhead := n.fitnessOneAppInit(deployedClouds, appsCopy, replicaSets, i, chromosome)
 			if thisRepairTime >= 0 {
 				repairTimes = append(repairTimes, thisRepairTime)
 				latencyOverheads = append(latencyOverheads, thisLatencyOverhead)
@@ -477,7 +636,7 @@ func (n *NSGAII) crossoverOperator(clouds []model.Cloud, apps []model.Applicatio
 	// traverse all chromosomes in this population, use random to judge whether a chromosome needs crossover
 	var indexesNeedCrossover []int
 	for i := 0; i < len(copyPopulation); i++ {
-		if random.RandomFloat64(0, 1) < n.CrossoverProbability {
+		if n.Rand.RandomFloat64(0, 1) < n.CrossoverProbability {
 			indexesNeedCrossover = append(indexesNeedCrossover, i)
 		}
 	}
@@ -491,12 +650,12 @@ func (n *NSGAII) crossoverOperator(clouds []model.Cloud, apps []model.Applicatio
 		// delete them from indexesNeedCrossover;
 		// mark them in whetherCrossover
 		// first index
-		first := random.RandomInt(0, len(indexesNeedCrossover)-1)
+		first := n.Rand.RandomInt(0, len(indexesNeedCrossover)-1)
 		firstIndex := indexesNeedCrossover[first]
 		whetherCrossover[firstIndex] = true                                                            // mark
 		indexesNeedCrossover = append(indexesNeedCrossover[:first], indexesNeedCrossover[first+1:]...) // delete
 		// second index
-		second := random.RandomInt(0, len(indexesNeedCrossover)-1)
+		second := n.Rand.RandomInt(0, len(indexesNeedCrossover)-1)
 		secondIndex := indexesNeedCrossover[second]
 		whetherCrossover[secondIndex] = true                                                             // mark
 		indexesNeedCrossover = append(indexesNeedCrossover[:second], indexesNeedCrossover[second+1:]...) // delete
@@ -504,7 +663,7 @@ func (n *NSGAII) crossoverOperator(clouds []model.Cloud, apps []model.Applicatio
 		firstChromosome := copyPopulation[firstIndex]
 		secondChromosome := copyPopulation[secondIndex]
 
-		firstNew, secondNew := TwoPointCrossOver(firstChromosome, secondChromosome)
+		firstNew, secondNew := TwoPointCrossOver(n.Rand, firstChromosome, secondChromosome)
 
 		// append the two new chromosomes in newPopulation
 		newPopulation = append(newPopulation, firstNew, secondNew)
@@ -526,8 +685,8 @@ func (n *NSGAII) mutationOperator(clouds []model.Cloud, apps []model.Application
 	// avoid changing the original population, maybe not needed but for security
 	var copyPopulation Population = PopulationCopy(population)
 	for i := 0; i < len(copyPopulation); i++ {
-		if random.RandomFloat64(0, 1) < n.MutationProbability {
-			copyPopulation[i] = RandomFitSchedule(clouds, apps)
+		if n.Rand.RandomFloat64(0, 1) < n.MutationProbability {
+			copyPopulation[i] = RandomFitSchedule(n.Rand, clouds, apps)
 		}
 	}
 	return copyPopulation
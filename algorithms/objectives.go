@@ -0,0 +1,232 @@
+package algorithms
+
+import (
+	"fmt"
+	"gogeneticwrsp/model"
+	"math"
+)
+
+// Objective is one objective of the multi-objective mode of Genetic, Value is minimized.
+// Value is called concurrently by FitnessEvaluator, so it must not modify clouds or apps.
+type Objective struct {
+	Name  string
+	Value func(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64
+}
+
+// ObjectiveRejectedPriorityRate is the rate of the priority of rejected apps in the priority of all apps, from AcceptedPriority
+var ObjectiveRejectedPriorityRate Objective = Objective{
+	Name: "rejectedPriorityRate",
+	Value: func(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+		totalPriority := TotalPriority(clouds, apps, schedulingResult)
+		if totalPriority == 0 {
+			return 0
+		}
+		return 1 - float64(AcceptedPriority(clouds, apps, schedulingResult))/float64(totalPriority)
+	},
+}
+
+// ObjectiveMakespan is the time when the last accepted app becomes stable (service) or completes (task) on all clouds, unit second
+var ObjectiveMakespan Objective = Objective{
+	Name: "makespan",
+	Value: func(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+		var deployedClouds []model.Cloud = SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+		var timeApps []model.Application = CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+		var makespan float64
+		for appIdx := 0; appIdx < len(schedulingResult); appIdx++ {
+			if schedulingResult[appIdx] == len(clouds) {
+				continue
+			}
+			makespan = math.Max(makespan, timeApps[appIdx].FinishTime())
+		}
+		return makespan
+	},
+}
+
+// ObjectiveCPUIdleRate is CPUIdleRate
+var ObjectiveCPUIdleRate Objective = Objective{
+	Name:  "cpuIdleRate",
+	Value: CPUIdleRate,
+}
+
+// ObjectiveGPUIdleRate is GPUIdleRate
+var ObjectiveGPUIdleRate Objective = Objective{
+	Name:  "gpuIdleRate",
+	Value: GPUIdleRate,
+}
+
+// ObjectiveScalarIdleRate returns the objective of ScalarIdleRate of the scalar resource name
+func ObjectiveScalarIdleRate(name string) Objective {
+	return Objective{
+		Name: "scalarIdleRate:" + name,
+		Value: func(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+			return ScalarIdleRate(clouds, apps, schedulingResult, name)
+		},
+	}
+}
+
+// ObjectiveCost returns the objective of Cost when the services run for hours hours
+func ObjectiveCost(hours float64) Objective {
+	return Objective{
+		Name: "cost",
+		Value: func(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+			return Cost(clouds, apps, schedulingResult, hours)
+		},
+	}
+}
+
+// ObjectiveEnergy returns the objective of Energy during period seconds
+func ObjectiveEnergy(period float64) Objective {
+	return Objective{
+		Name: "energy",
+		Value: func(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+			return Energy(clouds, apps, schedulingResult, period)
+		},
+	}
+}
+
+// ObjectiveCarbon returns the objective of Carbon during period seconds from start on the carbon intensity curves
+func ObjectiveCarbon(start, period float64) Objective {
+	return Objective{
+		Name: "carbon",
+		Value: func(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+			return Carbon(clouds, apps, schedulingResult, start, period)
+		},
+	}
+}
+
+// ObjectiveBwIdleRate is BwIdleRate
+var ObjectiveBwIdleRate Objective = Objective{
+	Name:  "bwIdleRate",
+	Value: BwIdleRate,
+}
+
+// ObjectiveInterCloudLatency is the sum of the RTT between the clouds of every accepted app and the clouds of its dependent services (the nearest replicas of replicated services), unit millisecond.
+// Like in checkAcceptable, apps do not communicate with the tasks they depend on, so these dependences are not counted.
+var ObjectiveInterCloudLatency Objective = Objective{
+	Name: "interCloudLatency",
+	Value: func(clouds []model.Cloud, apps []model.Application, schedulingResult []int) float64 {
+		var latency float64
+		var replicaSets [][]int = model.ReplicaSets(apps)
+		for appIdx := 0; appIdx < len(schedulingResult); appIdx++ {
+			if schedulingResult[appIdx] == len(clouds) {
+				continue
+			}
+			for _, dependence := range apps[appIdx].Depend {
+				if apps[dependence.AppIdx].IsTask {
+					continue
+				}
+				servingCloudIdx := servingCloud(clouds, replicaSets, schedulingResult, dependence.AppIdx, schedulingResult[appIdx])
+				if servingCloudIdx == len(clouds) {
+					continue
+				}
+				latency += clouds[schedulingResult[appIdx]].Allocatable.NetCondClouds[servingCloudIdx].RTT
+			}
+		}
+		return latency
+	},
+}
+
+// objectiveValues calculates the values of objectives for a scheduling result
+func objectiveValues(objectives []Objective, clouds []model.Cloud, apps []model.Application, schedulingResult []int) []float64 {
+	var values []float64 = make([]float64, len(objectives))
+	for i := 0; i < len(objectives); i++ {
+		values[i] = objectives[i].Value(clouds, apps, schedulingResult)
+	}
+	return values
+}
+
+// objectiveIndex finds the index of an objective in solution.ObjectiveNames
+func objectiveIndex(solution model.Solution, name string) (int, error) {
+	for i := 0; i < len(solution.ObjectiveNames); i++ {
+		if solution.ObjectiveNames[i] == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: %q, the objectives are %v", ErrUnknownObjective, name, solution.ObjectiveNames)
+}
+
+// ChooseByWeights returns the point on solution.ParetoFront with the smallest weighted sum of objectives.
+// Every objective is normalized to [0, 1] on the front before weighting, and the objectives not in weights have weight 0.
+// If weights is empty, all objectives have the same weight.
+func ChooseByWeights(solution model.Solution, weights map[string]float64) (model.ParetoPoint, error) {
+	if len(solution.ParetoFront) == 0 {
+		return model.ParetoPoint{}, ErrEmptyParetoFront
+	}
+	var weightOf []float64 = make([]float64, len(solution.ObjectiveNames))
+	if len(weights) == 0 {
+		for i := 0; i < len(weightOf); i++ {
+			weightOf[i] = 1
+		}
+	}
+	for name, weight := range weights {
+		idx, err := objectiveIndex(solution, name)
+		if err != nil {
+			return model.ParetoPoint{}, err
+		}
+		weightOf[idx] = weight
+	}
+
+	// the range of every objective on the front
+	var minValues, maxValues []float64 = make([]float64, len(weightOf)), make([]float64, len(weightOf))
+	for m := 0; m < len(weightOf); m++ {
+		minValues[m], maxValues[m] = math.Inf(1), math.Inf(-1)
+		for _, point := range solution.ParetoFront {
+			minValues[m] = math.Min(minValues[m], point.Objectives[m])
+			maxValues[m] = math.Max(maxValues[m], point.Objectives[m])
+		}
+	}
+
+	var bestIdx int
+	var bestSum float64 = math.Inf(1)
+	for i, point := range solution.ParetoFront {
+		var sum float64
+		for m := 0; m < len(weightOf); m++ {
+			if maxValues[m] > minValues[m] {
+				sum += weightOf[m] * (point.Objectives[m] - minValues[m]) / (maxValues[m] - minValues[m])
+			}
+		}
+		if sum < bestSum {
+			bestSum = sum
+			bestIdx = i
+		}
+	}
+	return model.SolutionCopy(model.Solution{ParetoFront: solution.ParetoFront[bestIdx : bestIdx+1]}).ParetoFront[0], nil
+}
+
+// ChooseByConstraints returns the point on solution.ParetoFront with the smallest value of the objective minimize,
+// among the points whose objectives are not larger than upperBounds
+func ChooseByConstraints(solution model.Solution, minimize string, upperBounds map[string]float64) (model.ParetoPoint, error) {
+	minimizeIdx, err := objectiveIndex(solution, minimize)
+	if err != nil {
+		return model.ParetoPoint{}, err
+	}
+	var boundOf []float64 = make([]float64, len(solution.ObjectiveNames))
+	for i := 0; i < len(boundOf); i++ {
+		boundOf[i] = math.Inf(1)
+	}
+	for name, bound := range upperBounds {
+		idx, err := objectiveIndex(solution, name)
+		if err != nil {
+			return model.ParetoPoint{}, err
+		}
+		boundOf[idx] = bound
+	}
+
+	var bestIdx int = -1
+	for i, point := range solution.ParetoFront {
+		var satisfied bool = true
+		for m := 0; m < len(boundOf); m++ {
+			if point.Objectives[m] > boundOf[m] {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied && (bestIdx < 0 || point.Objectives[minimizeIdx] < solution.ParetoFront[bestIdx].Objectives[minimizeIdx]) {
+			bestIdx = i
+		}
+	}
+	if bestIdx < 0 {
+		return model.ParetoPoint{}, fmt.Errorf("%w: no point within %v", ErrEmptyParetoFront, upperBounds)
+	}
+	return model.SolutionCopy(model.Solution{ParetoFront: solution.ParetoFront[bestIdx : bestIdx+1]}).ParetoFront[0], nil
+}
@@ -0,0 +1,147 @@
+package algorithms
+
+import (
+	"math"
+	"sort"
+)
+
+// Dominates returns whether objectives a dominate objectives b, all objectives are minimized.
+// a dominates b if a is not worse than b in any objective and better than b in at least one objective.
+func Dominates(a, b []float64) bool {
+	var better bool
+	for i := 0; i < len(a); i++ {
+		if a[i] > b[i] {
+			return false
+		}
+		if a[i] < b[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+// constrainedDominates is the constrained-domination of NSGA-II: an acceptable chromosome dominates an unacceptable one,
+// and two chromosomes with the same acceptability are compared by Dominates
+func constrainedDominates(a, b Evaluation) bool {
+	if a.Acceptable != b.Acceptable {
+		return a.Acceptable
+	}
+	return Dominates(a.Objectives, b.Objectives)
+}
+
+// FastNonDominatedSort sorts evaluations into fronts with the fast non-dominated sorting of NSGA-II.
+// fronts[0] is the first (Pareto) front, every front contains the indexes of evaluations in ascending order.
+// Unacceptable chromosomes are always in later fronts than acceptable ones.
+func FastNonDominatedSort(evaluations []Evaluation) [][]int {
+	var dominatedBy [][]int = make([][]int, len(evaluations)) // dominatedBy[p] are the chromosomes dominated by p
+	var dominationCount []int = make([]int, len(evaluations)) // number of chromosomes that dominate p
+	var fronts [][]int
+	var currentFront []int
+	for p := 0; p < len(evaluations); p++ {
+		for q := 0; q < len(evaluations); q++ {
+			if p == q {
+				continue
+			}
+			if constrainedDominates(evaluations[p], evaluations[q]) {
+				dominatedBy[p] = append(dominatedBy[p], q)
+			} else if constrainedDominates(evaluations[q], evaluations[p]) {
+				dominationCount[p]++
+			}
+		}
+		if dominationCount[p] == 0 {
+			currentFront = append(currentFront, p)
+		}
+	}
+
+	for len(currentFront) > 0 {
+		fronts = append(fronts, currentFront)
+		var nextFront []int
+		for _, p := range currentFront {
+			for _, q := range dominatedBy[p] {
+				dominationCount[q]--
+				if dominationCount[q] == 0 {
+					nextFront = append(nextFront, q)
+				}
+			}
+		}
+		sort.Ints(nextFront)
+		currentFront = nextFront
+	}
+	return fronts
+}
+
+// CrowdingDistance calculates the crowding distance of every chromosome in a front, in the order of front.
+// The chromosomes on the boundary of any objective get +Inf, so they are always preferred.
+func CrowdingDistance(evaluations []Evaluation, front []int) []float64 {
+	var distances []float64 = make([]float64, len(front))
+	if len(front) == 0 {
+		return distances
+	}
+	var order []int = make([]int, len(front)) // positions in front
+	for m := 0; m < len(evaluations[front[0]].Objectives); m++ {
+		for i := 0; i < len(order); i++ {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return evaluations[front[order[i]]].Objectives[m] < evaluations[front[order[j]]].Objectives[m]
+		})
+		minValue := evaluations[front[order[0]]].Objectives[m]
+		maxValue := evaluations[front[order[len(order)-1]]].Objectives[m]
+		distances[order[0]] = math.Inf(1)
+		distances[order[len(order)-1]] = math.Inf(1)
+		if maxValue == minValue {
+			continue
+		}
+		for i := 1; i < len(order)-1; i++ {
+			distances[order[i]] += (evaluations[front[order[i+1]]].Objectives[m] - evaluations[front[order[i-1]]].Objectives[m]) / (maxValue - minValue)
+		}
+	}
+	return distances
+}
+
+// crowdedLess is the crowded-comparison operator of NSGA-II, a lower rank is better, and in the same rank a larger crowding distance is better
+func crowdedLess(ranks []int, distances []float64, i, j int) bool {
+	if ranks[i] != ranks[j] {
+		return ranks[i] < ranks[j]
+	}
+	return distances[i] > distances[j]
+}
+
+// rankAndCrowding returns the front rank and the crowding distance of every chromosome
+func rankAndCrowding(evaluations []Evaluation, fronts [][]int) ([]int, []float64) {
+	var ranks []int = make([]int, len(evaluations))
+	var distances []float64 = make([]float64, len(evaluations))
+	for rank, front := range fronts {
+		frontDistances := CrowdingDistance(evaluations, front)
+		for i, idx := range front {
+			ranks[idx] = rank
+			distances[idx] = frontDistances[i]
+		}
+	}
+	return ranks, distances
+}
+
+// elitistSurvivors is the (μ+λ) elitist selection of NSGA-II, it keeps the best survivorNum chromosomes front by front,
+// and the last front that does not fit is cut by crowding distance. It returns the indexes of the survivors.
+func elitistSurvivors(evaluations []Evaluation, fronts [][]int, survivorNum int) []int {
+	var survivors []int
+	for _, front := range fronts {
+		if len(survivors)+len(front) <= survivorNum {
+			survivors = append(survivors, front...)
+			continue
+		}
+		distances := CrowdingDistance(evaluations, front)
+		var order []int = make([]int, len(front))
+		for i := 0; i < len(order); i++ {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return distances[order[i]] > distances[order[j]]
+		})
+		for i := 0; len(survivors) < survivorNum; i++ {
+			survivors = append(survivors, front[order[i]])
+		}
+		break
+	}
+	return survivors
+}
@@ -0,0 +1,257 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/model"
+)
+
+func TestFastNonDominatedSort(t *testing.T) {
+	testCases := []struct {
+		name           string
+		evaluations    []Evaluation
+		expectedFronts [][]int
+	}{
+		{
+			name: "case 1: three fronts",
+			evaluations: []Evaluation{
+				{Objectives: []float64{1, 5}, Acceptable: true},
+				{Objectives: []float64{2, 2}, Acceptable: true},
+				{Objectives: []float64{3, 3}, Acceptable: true},
+				{Objectives: []float64{5, 1}, Acceptable: true},
+				{Objectives: []float64{4, 4}, Acceptable: true},
+			},
+			expectedFronts: [][]int{{0, 1, 3}, {2}, {4}},
+		},
+		{
+			name: "case 2: equal objectives do not dominate each other",
+			evaluations: []Evaluation{
+				{Objectives: []float64{2, 2}, Acceptable: true},
+				{Objectives: []float64{2, 2}, Acceptable: true},
+				{Objectives: []float64{2, 3}, Acceptable: true},
+			},
+			expectedFronts: [][]int{{0, 1}, {2}},
+		},
+		{
+			name: "case 3: unacceptable ones are behind acceptable ones",
+			evaluations: []Evaluation{
+				{Objectives: []float64{1, 1}, Acceptable: false},
+				{Objectives: []float64{9, 9}, Acceptable: true},
+				{Objectives: []float64{2, 0}, Acceptable: false},
+				{Objectives: []float64{3, 3}, Acceptable: false},
+			},
+			expectedFronts: [][]int{{1}, {0, 2}, {3}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		assert.Equal(t, testCase.expectedFronts, FastNonDominatedSort(testCase.evaluations), fmt.Sprintf("%s: wrong fronts", testCase.name))
+	}
+}
+
+func TestCrowdingDistance(t *testing.T) {
+	var evaluations []Evaluation = []Evaluation{
+		{Objectives: []float64{1, 5}},
+		{Objectives: []float64{2, 3}},
+		{Objectives: []float64{4, 2}},
+		{Objectives: []float64{5, 1}},
+	}
+	distances := CrowdingDistance(evaluations, []int{0, 1, 2, 3})
+	assert.True(t, math.IsInf(distances[0], 1), "boundary should be +Inf")
+	assert.True(t, math.IsInf(distances[3], 1), "boundary should be +Inf")
+	assert.InDelta(t, (4-1)/4.0+(5-2)/4.0, distances[1], 1e-9)
+	assert.InDelta(t, (5-2)/4.0+(3-1)/4.0, distances[2], 1e-9)
+
+	// the survivors are cut by crowding distance in the last front
+	survivors := elitistSurvivors(evaluations, [][]int{{0, 1, 2, 3}}, 3)
+	assert.Equal(t, []int{0, 3, 1}, survivors)
+}
+
+func TestNSGAIIParetoFront(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	n, err := NewNSGAII(20, 30, 1, 0.25, 10, clouds, apps)
+	assert.NoError(t, err)
+	n.Rand = NewRandom(7)
+	solution, err := n.Schedule(clouds, apps)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"repairTime", "latencyOverhead"}, solution.ObjectiveNames)
+	assert.NotEmpty(t, solution.ParetoFront, "the Pareto front is empty")
+	assert.Len(t, n.ParetoFrontFitness, len(n.ParetoFront))
+
+	var containsSolution bool
+	for i, point := range solution.ParetoFront {
+		assert.True(t, Acceptable(clouds, apps, point.SchedulingResult), fmt.Sprintf("point %d is not acceptable", i))
+		assert.Equal(t, n.Fitness(clouds, apps, point.SchedulingResult).Objectives(), point.Objectives, fmt.Sprintf("point %d has wrong objectives", i))
+		for j, other := range solution.ParetoFront {
+			assert.False(t, Dominates(other.Objectives, point.Objectives), fmt.Sprintf("point %d is dominated by point %d", i, j))
+		}
+		if fmt.Sprint(point.SchedulingResult) == fmt.Sprint(solution.SchedulingResult) {
+			containsSolution = true
+		}
+	}
+	assert.True(t, containsSolution, "the returned scheduling result is not on the Pareto front")
+	assert.Equal(t, n.Fitness(clouds, apps, solution.SchedulingResult).PrintFitness(), solution.Fitness)
+
+	// the search stops once the acceptable first front has not changed for StopNoUpdateIteration iterations
+	lastIteration := float64(len(n.FitnessRecordIterationBest) - 1)
+	lastFrontUpdate := n.ParetoFrontUpdateIterations[len(n.ParetoFrontUpdateIterations)-1]
+	if lastIteration < float64(n.IterationCount) {
+		assert.Equal(t, float64(n.StopNoUpdateIteration+1), lastIteration-lastFrontUpdate)
+	}
+	assert.LessOrEqual(t, lastIteration-lastFrontUpdate, float64(n.StopNoUpdateIteration+1))
+}
+
+func TestNSGAIIExtraObjectives(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	for i := 0; i < len(clouds); i++ {
+		clouds[i].Price = model.CloudPrice{CPUHour: float64(3 - i), MemoryGBHour: 0.01}
+	}
+	n, err := NewNSGAII(20, 30, 1, 0.25, 10, clouds, apps)
+	assert.NoError(t, err)
+	n.Rand = NewRandom(7)
+	n.ExtraObjectives = []Objective{ObjectiveCost(24)}
+	solution, err := n.Schedule(clouds, apps)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"repairTime", "latencyOverhead", "cost"}, solution.ObjectiveNames)
+	assert.Equal(t, []string{"cost"}, solution.Params["extraObjectives"])
+	assert.NotEmpty(t, solution.ParetoFront, "the Pareto front is empty")
+	for i, point := range solution.ParetoFront {
+		assert.Len(t, point.Objectives, 3, fmt.Sprintf("point %d has wrong number of objectives", i))
+		assert.InDelta(t, Cost(clouds, apps, point.SchedulingResult, 24), point.Objectives[2], 1e-9, fmt.Sprintf("point %d has wrong cost", i))
+		for j, other := range solution.ParetoFront {
+			assert.False(t, Dominates(other.Objectives, point.Objectives), fmt.Sprintf("point %d is dominated by point %d", i, j))
+		}
+	}
+
+	// the returned scheduling result is chosen from the front, so it is not dominated even with the extra objective
+	chosen, err := ChooseByWeights(solution, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprint(chosen.SchedulingResult), fmt.Sprint(solution.SchedulingResult))
+}
+
+func TestGeneticMultiObjective(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	g, err := NewGenetic(20, 30, 0.4, 0.05, 10, RandomFitSchedule, OnePointCrossOver, PriorityTimeFitness, true, false, clouds, apps)
+	assert.NoError(t, err)
+	g.Rand = NewRandom(3)
+	g.Objectives = []Objective{ObjectiveRejectedPriorityRate, ObjectiveMakespan, ObjectiveInterCloudLatency}
+	solution, err := g.Schedule(clouds, apps)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"rejectedPriorityRate", "makespan", "interCloudLatency"}, solution.ObjectiveNames)
+	assert.Equal(t, []string{"rejectedPriorityRate", "makespan", "interCloudLatency"}, solution.Params["objectives"])
+	assert.NotEmpty(t, solution.ParetoFront, "the Pareto front is empty")
+	assert.LessOrEqual(t, len(solution.ParetoFront), g.ChromosomesCount)
+
+	var containsSolution bool
+	for i, point := range solution.ParetoFront {
+		assert.True(t, Acceptable(clouds, apps, point.SchedulingResult), fmt.Sprintf("point %d is not acceptable", i))
+		assert.Equal(t, objectiveValues(g.Objectives, clouds, apps, point.SchedulingResult), point.Objectives, fmt.Sprintf("point %d has wrong objectives", i))
+		for j, other := range solution.ParetoFront {
+			assert.False(t, Dominates(other.Objectives, point.Objectives), fmt.Sprintf("point %d is dominated by point %d", i, j))
+		}
+		if fmt.Sprint(point.SchedulingResult) == fmt.Sprint(solution.SchedulingResult) {
+			containsSolution = true
+		}
+	}
+	assert.True(t, containsSolution, "the returned scheduling result is not on the Pareto front")
+	assert.Len(t, solution.Placements, len(apps))
+}
+
+func TestChooseParetoPoint(t *testing.T) {
+	var solution model.Solution = model.Solution{
+		ObjectiveNames: []string{"rejectedPriorityRate", "makespan"},
+		ParetoFront: []model.ParetoPoint{
+			{SchedulingResult: []int{0, 0}, Objectives: []float64{0, 100}},
+			{SchedulingResult: []int{0, 1}, Objectives: []float64{0.2, 40}},
+			{SchedulingResult: []int{2, 1}, Objectives: []float64{0.5, 10}},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		choose        func() (model.ParetoPoint, error)
+		expectedIdx   int
+		expectedError error
+	}{
+		{
+			name: "case 1: equal weights",
+			choose: func() (model.ParetoPoint, error) {
+				return ChooseByWeights(solution, nil)
+			},
+			expectedIdx: 1,
+		},
+		{
+			name: "case 2: only acceptance",
+			choose: func() (model.ParetoPoint, error) {
+				return ChooseByWeights(solution, map[string]float64{"rejectedPriorityRate": 1})
+			},
+			expectedIdx: 0,
+		},
+		{
+			name: "case 3: mostly makespan",
+			choose: func() (model.ParetoPoint, error) {
+				return ChooseByWeights(solution, map[string]float64{"rejectedPriorityRate": 1, "makespan": 3})
+			},
+			expectedIdx: 2,
+		},
+		{
+			name: "case 4: shortest makespan with at most 30% rejected",
+			choose: func() (model.ParetoPoint, error) {
+				return ChooseByConstraints(solution, "makespan", map[string]float64{"rejectedPriorityRate": 0.3})
+			},
+			expectedIdx: 1,
+		},
+		{
+			name: "case 5: no point within the constraints",
+			choose: func() (model.ParetoPoint, error) {
+				return ChooseByConstraints(solution, "makespan", map[string]float64{"rejectedPriorityRate": 0.1, "makespan": 50})
+			},
+			expectedError: ErrEmptyParetoFront,
+		},
+		{
+			name: "case 6: unknown objective",
+			choose: func() (model.ParetoPoint, error) {
+				return ChooseByWeights(solution, map[string]float64{"cost": 1})
+			},
+			expectedError: ErrUnknownObjective,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		point, err := testCase.choose()
+		if testCase.expectedError != nil {
+			assert.True(t, errors.Is(err, testCase.expectedError), fmt.Sprintf("%s: unexpected error %v", testCase.name, err))
+			continue
+		}
+		assert.NoError(t, err, testCase.name)
+		assert.Equal(t, solution.ParetoFront[testCase.expectedIdx], point, testCase.name)
+	}
+}
+
+func TestObjectiveInterCloudLatency(t *testing.T) {
+	// app 3 depends on service 0, and app 6 depends on service 2 and task 5, the RTT between clouds i and j is 10 * (i + j)
+	testCases := []struct {
+		name             string
+		schedulingResult []int
+		expected         float64
+	}{
+		{name: "case 1: all on the same cloud", schedulingResult: []int{0, 0, 0, 0, 0, 0, 0, 0}, expected: 0},
+		{name: "case 2: dependent task on another cloud", schedulingResult: []int{0, 0, 0, 0, 0, 1, 0, 0}, expected: 0},
+		{name: "case 3: dependent service on another cloud", schedulingResult: []int{0, 0, 2, 0, 0, 1, 0, 0}, expected: 20},
+		{name: "case 4: both dependences across clouds", schedulingResult: []int{1, 0, 2, 0, 0, 1, 0, 0}, expected: 30},
+		{name: "case 5: rejected app", schedulingResult: []int{0, 0, 2, 0, 0, 1, 3, 0}, expected: 0},
+	}
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		assert.Equal(t, testCase.expected, ObjectiveInterCloudLatency.Value(clouds, apps, testCase.schedulingResult), testCase.name)
+	}
+}
@@ -0,0 +1,55 @@
+package algorithms
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Random is a seedable random source owned by one scheduler.
+// Every random operator of a scheduler draws from its own Random, so a run can be replayed by constructing the scheduler with the same seed.
+// The methods have the same semantics as the helpers in github.com/KeepTheBeats/routing-algorithms/random.
+type Random struct {
+	Seed int64 // the seed that this source was created with, recorded in the scheduling result
+	r    *rand.Rand
+}
+
+// NewRandom creates a random source with the given seed
+func NewRandom(seed int64) *Random {
+	return &Random{
+		Seed: seed,
+		r:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// NewTimeSeededRandom creates a random source seeded with the current time, used as the default of all schedulers
+func NewTimeSeededRandom() *Random {
+	return NewRandom(time.Now().UnixNano())
+}
+
+// RandomInt generate int in [start,end]
+func (r *Random) RandomInt(start, end int) int {
+	return r.r.Intn(end-start+1) + start
+}
+
+// RandomFloat64 generate float64 in [start,end)
+func (r *Random) RandomFloat64(start, end float64) float64 {
+	return r.r.Float64()*(end-start) + start
+}
+
+// RandomPickN pick m indexes from the slice a
+func (r *Random) RandomPickN(a []int, m int) []int {
+	if m > len(a) {
+		return []int{}
+	}
+	indexes := make([]int, len(a))
+	for i := 0; i < len(indexes); i++ {
+		indexes[i] = i
+	}
+	var result []int
+	for i := 0; i < m; i++ {
+		picked := r.RandomInt(0, len(indexes)-1)
+		result = append(result, indexes[picked])
+		indexes = append(indexes[:picked], indexes[picked+1:]...)
+	}
+	return result
+}
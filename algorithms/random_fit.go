@@ -1,23 +1,34 @@
 package algorithms
 
 import (
-	"github.com/KeepTheBeats/routing-algorithms/random"
+	"context"
 	"gogeneticwrsp/model"
 )
 
 type RandomFit struct {
+	Rand *Random // random source of this scheduler, replace it with NewRandom(seed) to replay a run
 }
 
 func NewRandomFit(clouds []model.Cloud, apps []model.Application) *RandomFit {
-	return &RandomFit{}
+	return &RandomFit{
+		Rand: NewTimeSeededRandom(),
+	}
+}
+
+// ScheduleContext ignores ctx, because Random Fit finishes in one pass
+func (rf *RandomFit) ScheduleContext(ctx context.Context, clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
+	return rf.Schedule(clouds, apps)
 }
 
 func (rf *RandomFit) Schedule(clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
-	schedulingResult := RandomFitSchedule(clouds, apps)
-	return model.Solution{SchedulingResult: schedulingResult}, nil
+	schedulingResult := RandomFitSchedule(rf.Rand, clouds, apps)
+	solution := DescribeSolution(clouds, apps, model.Solution{SchedulingResult: schedulingResult, Seed: rf.Rand.Seed})
+	solution.Algorithm = "Random Fit"
+	solution.Params = map[string]interface{}{}
+	return solution, nil
 }
 
-func RandomFitSchedule(clouds []model.Cloud, apps []model.Application) []int {
+func RandomFitSchedule(r *Random, clouds []model.Cloud, apps []model.Application) []int {
 	var schedulingResult []int = make([]int, len(apps))
 
 	// set all new applications undeployed, old application to their previous clouds
@@ -39,8 +50,10 @@ func RandomFitSchedule(clouds []model.Cloud, apps []model.Application) []int {
 		undeployed[i] = i // record the original index of undeployed apps
 	}
 
+	// if a cloud is not acceptable, the app stays rejected (new apps) or on its previous cloud (old apps)
+	var state *FeasibilityState = NewFeasibilityState(clouds, apps, schedulingResult)
 	for len(undeployed) > 0 {
-		appIndex := random.RandomInt(0, len(undeployed)-1)      // appIndex in undeployed
+		appIndex := r.RandomInt(0, len(undeployed)-1)           // appIndex in undeployed
 		if _, exist := noMigrate[undeployed[appIndex]]; exist { // executing tasks and their dependent apps cannot be migrated
 			undeployed = append(undeployed[:appIndex], undeployed[appIndex+1:]...)
 			continue
@@ -52,28 +65,30 @@ func RandomFitSchedule(clouds []model.Cloud, apps []model.Application) []int {
 			untried[i] = i // record the original index of untried clouds
 		}
 
+		if replicas := state.replicasOf(undeployed[appIndex]); replicas != nil {
+			if replicas[0] == undeployed[appIndex] { // the replicas of a service are placed together with the first one
+				state.placeReplicas(undeployed[appIndex], randomCandidates(r, clouds, apps[undeployed[appIndex]], untried))
+			}
+			undeployed = append(undeployed[:appIndex], undeployed[appIndex+1:]...)
+			continue
+		}
+
 		for len(untried) > 0 {
-			cloudIndex := random.RandomInt(0, len(untried)-1) // cloudIndex in untried
+			cloudIndex := r.RandomInt(0, len(untried)-1) // cloudIndex in untried
 			if !CloudMeetApp(clouds[untried[cloudIndex]], apps[undeployed[appIndex]]) {
 				untried = append(untried[:cloudIndex], untried[cloudIndex+1:]...)
 				continue
 			}
-			schedulingResult[undeployed[appIndex]] = untried[cloudIndex]
-
-			if Acceptable(clouds, apps, schedulingResult) {
+			if state.TestPlacement(undeployed[appIndex], untried[cloudIndex]) {
+				state.Add(undeployed[appIndex], untried[cloudIndex])
 				untried = append(untried[:cloudIndex], untried[cloudIndex+1:]...)
 				break
 			}
-			if apps[undeployed[appIndex]].IsNew {
-				schedulingResult[undeployed[appIndex]] = len(clouds)
-			} else { // old apps cannot be rejected
-				schedulingResult[undeployed[appIndex]] = apps[undeployed[appIndex]].CloudRemainingOn
-			}
 			untried = append(untried[:cloudIndex], untried[cloudIndex+1:]...)
 		}
 
 		undeployed = append(undeployed[:appIndex], undeployed[appIndex+1:]...)
 	}
 
-	return schedulingResult
+	return state.SchedulingResult()
 }
@@ -0,0 +1,413 @@
+package algorithms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gogeneticwrsp/model"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strings"
+)
+
+// SchedulerParams are the typed parameters of a registered scheduler, the "params" of a SchedulerConfig are decoded into them
+type SchedulerParams interface {
+	// New constructs a scheduler with these parameters for clouds and apps
+	New(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error)
+}
+
+// SchedulerConfig selects a registered scheduler by name, e.g., {"algorithm": "mcasga", "params": {"iterationCount": 1000}}.
+// The parameters not in Params keep their default values.
+type SchedulerConfig struct {
+	Name      string                 `json:"name,omitempty" yaml:"name,omitempty"` // optional label of this scheduler in experiments, Algorithm is used if it is empty
+	Algorithm string                 `json:"algorithm" yaml:"algorithm"`
+	Params    map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// Label returns Name, or Algorithm if Name is empty
+func (sc SchedulerConfig) Label() string {
+	if sc.Name != "" {
+		return sc.Name
+	}
+	return sc.Algorithm
+}
+
+// schedulerRegistry maps the lowercase names of schedulers to the functions returning their default parameters
+var schedulerRegistry map[string]func() SchedulerParams = make(map[string]func() SchedulerParams)
+
+// RegisterScheduler registers a scheduler under name, which is case-insensitive. defaults returns a pointer to new parameters with the default values.
+// It panics if name is already registered.
+func RegisterScheduler(name string, defaults func() SchedulerParams) {
+	name = strings.ToLower(name)
+	if _, exist := schedulerRegistry[name]; exist {
+		panic(fmt.Sprintf("scheduler %q is registered twice", name))
+	}
+	schedulerRegistry[name] = defaults
+}
+
+// SchedulerNames returns the names of all registered schedulers in order
+func SchedulerNames() []string {
+	var names []string
+	for name := range schedulerRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultSchedulerParams returns the default parameters of the scheduler registered under name
+func DefaultSchedulerParams(name string) (SchedulerParams, error) {
+	defaults, exist := schedulerRegistry[strings.ToLower(name)]
+	if !exist {
+		return nil, fmt.Errorf("%w: %q, the schedulers are %v", ErrUnknownScheduler, name, SchedulerNames())
+	}
+	return defaults(), nil
+}
+
+// NewScheduler constructs the scheduler selected by config for clouds and apps, the replicas in apps should have been expanded with model.ExpandReplicas
+func NewScheduler(config SchedulerConfig, clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error) {
+	params, err := config.DecodeParams()
+	if err != nil {
+		return nil, err
+	}
+	if err := replicasExpanded(apps); err != nil {
+		return nil, err
+	}
+	return params.New(clouds, apps)
+}
+
+// DecodeParams returns the default parameters of the selected scheduler overridden by sc.Params, an unknown parameter is an error
+func (sc SchedulerConfig) DecodeParams() (SchedulerParams, error) {
+	params, err := DefaultSchedulerParams(sc.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if len(sc.Params) == 0 {
+		return params, nil
+	}
+	// both JSON and YAML decode Params into generic maps, so they are decoded into the typed parameters through JSON
+	data, err := json.Marshal(sc.Params)
+	if err != nil {
+		return nil, fmt.Errorf("%w of %s: %s", ErrInvalidParams, sc.Algorithm, err.Error())
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return nil, fmt.Errorf("%w of %s: %s", ErrInvalidParams, sc.Algorithm, err.Error())
+	}
+	return params, nil
+}
+
+// ParseSchedulerConfigs parses a JSON or YAML document of one SchedulerConfig or a list of them
+func ParseSchedulerConfigs(data []byte) ([]SchedulerConfig, error) {
+	var configs []SchedulerConfig
+	if err := yaml.Unmarshal(data, &configs); err == nil {
+		return configs, nil
+	}
+	var config SchedulerConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidParams, err.Error())
+	}
+	return []SchedulerConfig{config}, nil
+}
+
+// SearchParams are the parameters shared by the genetic schedulers
+type SearchParams struct {
+	Seed    *int64 `json:"seed,omitempty" yaml:"seed,omitempty"` // seed of the random source, a time seed is used if it is nil
+	Workers int    `json:"workers" yaml:"workers"`               // number of goroutines evaluating the fitness, 0 means runtime.NumCPU()
+}
+
+// apply sets the random source and the evaluator of a scheduler
+func (sp SearchParams) apply(rand **Random, evaluator **FitnessEvaluator) {
+	if sp.Seed != nil {
+		*rand = NewRandom(*sp.Seed)
+	}
+	*evaluator = NewFitnessEvaluator(sp.Workers)
+}
+
+// FitnessConfig selects a FitnessFunc of Genetic by name, the other fields are only used by the fitness functions with parameters
+type FitnessConfig struct {
+	Name        string  `json:"name" yaml:"name"`                                   // priorityTime, deadlineMiss, energyAware, costAware or loadBalance
+	MissPenalty float64 `json:"missPenalty,omitempty" yaml:"missPenalty,omitempty"` // deadlineMiss
+	Weight      float64 `json:"weight,omitempty" yaml:"weight,omitempty"`           // energyAware, costAware and loadBalance
+}
+
+// FitnessFunc returns the selected fitness function, energyAware and costAware take the power models and the prices from the clouds being scheduled
+func (fc FitnessConfig) FitnessFunc() (FitnessFunc, error) {
+	switch fc.Name {
+	case "", "priorityTime":
+		return PriorityTimeFitness, nil
+	case "deadlineMiss":
+		return NewDeadlineMissFitness(fc.MissPenalty), nil
+	case "energyAware":
+		return NewEnergyAwareFitness(fc.Weight), nil
+	case "costAware":
+		return NewCostAwareFitness(fc.Weight), nil
+	case "loadBalance":
+		return NewLoadBalanceFitness(fc.Weight), nil
+	}
+	return nil, fmt.Errorf("%w: unknown fitness %q", ErrInvalidParams, fc.Name)
+}
+
+// ObjectiveConfig selects an Objective by name, the other fields are only used by the objectives with parameters
+type ObjectiveConfig struct {
+	Name     string  `json:"name" yaml:"name"`
+	Resource string  `json:"resource,omitempty" yaml:"resource,omitempty"` // scalarIdleRate
+	Hours    float64 `json:"hours,omitempty" yaml:"hours,omitempty"`       // cost
+	Start    float64 `json:"start,omitempty" yaml:"start,omitempty"`       // carbon
+	Period   float64 `json:"period,omitempty" yaml:"period,omitempty"`     // energy and carbon
+}
+
+// Objective returns the selected objective
+func (oc ObjectiveConfig) Objective() (Objective, error) {
+	switch oc.Name {
+	case ObjectiveRejectedPriorityRate.Name:
+		return ObjectiveRejectedPriorityRate, nil
+	case ObjectiveMakespan.Name:
+		return ObjectiveMakespan, nil
+	case ObjectiveCPUIdleRate.Name:
+		return ObjectiveCPUIdleRate, nil
+	case ObjectiveGPUIdleRate.Name:
+		return ObjectiveGPUIdleRate, nil
+	case ObjectiveBwIdleRate.Name:
+		return ObjectiveBwIdleRate, nil
+	case ObjectiveInterCloudLatency.Name:
+		return ObjectiveInterCloudLatency, nil
+	case "scalarIdleRate":
+		return ObjectiveScalarIdleRate(oc.Resource), nil
+	case "cost":
+		return ObjectiveCost(oc.Hours), nil
+	case "energy":
+		return ObjectiveEnergy(oc.Period), nil
+	case "carbon":
+		return ObjectiveCarbon(oc.Start, oc.Period), nil
+	}
+	return Objective{}, fmt.Errorf("%w: unknown objective %q", ErrInvalidParams, oc.Name)
+}
+
+// objectivesOf returns the objectives selected by configs
+func objectivesOf(configs []ObjectiveConfig) ([]Objective, error) {
+	var objectives []Objective
+	for _, config := range configs {
+		objective, err := config.Objective()
+		if err != nil {
+			return nil, err
+		}
+		objectives = append(objectives, objective)
+	}
+	return objectives, nil
+}
+
+// validateSearch checks the parameters shared by the genetic schedulers, the returned error wraps ErrInvalidParams
+func validateSearch(chromosomesCount, iterationCount int, crossoverProbability, mutationProbability float64, stopNoUpdateIteration int) error {
+	// the selection and the crossover pick 2 different chromosomes
+	if chromosomesCount < 2 {
+		return fmt.Errorf("%w: chromosomesCount is %d, it should be at least 2", ErrInvalidParams, chromosomesCount)
+	}
+	if iterationCount <= 0 {
+		return fmt.Errorf("%w: iterationCount is %d, it should be positive", ErrInvalidParams, iterationCount)
+	}
+	if stopNoUpdateIteration < 0 {
+		return fmt.Errorf("%w: stopNoUpdateIteration is %d, it should not be negative", ErrInvalidParams, stopNoUpdateIteration)
+	}
+	if !(crossoverProbability >= 0 && crossoverProbability <= 1) {
+		return fmt.Errorf("%w: crossoverProbability is %g, out of [0, 1]", ErrInvalidParams, crossoverProbability)
+	}
+	if !(mutationProbability >= 0 && mutationProbability <= 1) {
+		return fmt.Errorf("%w: mutationProbability is %g, out of [0, 1]", ErrInvalidParams, mutationProbability)
+	}
+	return nil
+}
+
+// GeneticParams are the parameters of Genetic, registered as "mcasga"
+type GeneticParams struct {
+	ChromosomesCount      int               `json:"chromosomesCount" yaml:"chromosomesCount"`
+	IterationCount        int               `json:"iterationCount" yaml:"iterationCount"`
+	CrossoverProbability  float64           `json:"crossoverProbability" yaml:"crossoverProbability"`
+	MutationProbability   float64           `json:"mutationProbability" yaml:"mutationProbability"`
+	StopNoUpdateIteration int               `json:"stopNoUpdateIteration" yaml:"stopNoUpdateIteration"`
+	Init                  string            `json:"init" yaml:"init"`           // randomFit, undeployed or acceptable
+	Crossover             string            `json:"crossover" yaml:"crossover"` // onePoint or twoPoint
+	Fitness               FitnessConfig     `json:"fitness" yaml:"fitness"`
+	BtSelection           bool              `json:"btSelection" yaml:"btSelection"`
+	CbMutation            bool              `json:"cbMutation" yaml:"cbMutation"`
+	Objectives            []ObjectiveConfig `json:"objectives,omitempty" yaml:"objectives,omitempty"` // the multi-objective mode if it is not empty
+	SearchParams          `yaml:",inline"`
+}
+
+// DefaultGeneticParams returns the parameters of MCASGA used in the experiments
+func DefaultGeneticParams() GeneticParams {
+	return GeneticParams{
+		ChromosomesCount:      200,
+		IterationCount:        5000,
+		CrossoverProbability:  0.4,
+		MutationProbability:   0.003,
+		StopNoUpdateIteration: 250,
+		Init:                  "randomFit",
+		Crossover:             "onePoint",
+		Fitness:               FitnessConfig{Name: "priorityTime"},
+		BtSelection:           true,
+		CbMutation:            false,
+	}
+}
+
+func (p *GeneticParams) validate() error {
+	return validateSearch(p.ChromosomesCount, p.IterationCount, p.CrossoverProbability, p.MutationProbability, p.StopNoUpdateIteration)
+}
+
+func (p *GeneticParams) New(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	var initFunc func(*Random, []model.Cloud, []model.Application) []int
+	switch p.Init {
+	case "", "randomFit":
+		initFunc = RandomFitSchedule
+	case "undeployed":
+		initFunc = InitializeUndeployedChromosome
+	case "acceptable":
+		initFunc = InitializeAcceptableChromosome
+	default:
+		return nil, fmt.Errorf("%w: unknown init %q", ErrInvalidParams, p.Init)
+	}
+	var crossoverFunc func(*Random, Chromosome, Chromosome) (Chromosome, Chromosome)
+	switch p.Crossover {
+	case "", "onePoint":
+		crossoverFunc = OnePointCrossOver
+	case "twoPoint":
+		crossoverFunc = TwoPointCrossOver
+	default:
+		return nil, fmt.Errorf("%w: unknown crossover %q", ErrInvalidParams, p.Crossover)
+	}
+	fitnessFunc, err := p.Fitness.FitnessFunc()
+	if err != nil {
+		return nil, err
+	}
+	objectives, err := objectivesOf(p.Objectives)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := NewGenetic(p.ChromosomesCount, p.IterationCount, p.CrossoverProbability, p.MutationProbability, p.StopNoUpdateIteration, initFunc, crossoverFunc, fitnessFunc, p.BtSelection, p.CbMutation, clouds, apps)
+	if err != nil {
+		return nil, err
+	}
+	g.Objectives = objectives
+	p.SearchParams.apply(&g.Rand, &g.Evaluator)
+	return g, nil
+}
+
+// HAGAParams are the parameters of HAGA, registered as "haga"
+type HAGAParams struct {
+	GroupNum              int     `json:"groupNum" yaml:"groupNum"`
+	VMGamma               float64 `json:"vmGamma" yaml:"vmGamma"`
+	ChromosomesCount      int     `json:"chromosomesCount" yaml:"chromosomesCount"`
+	IterationCount        int     `json:"iterationCount" yaml:"iterationCount"`
+	CrossoverProbability  float64 `json:"crossoverProbability" yaml:"crossoverProbability"`
+	MutationProbability   float64 `json:"mutationProbability" yaml:"mutationProbability"`
+	StopNoUpdateIteration int     `json:"stopNoUpdateIteration" yaml:"stopNoUpdateIteration"`
+	SearchParams          `yaml:",inline"`
+}
+
+// DefaultHAGAParams returns the parameters of HAGA used in the experiments
+func DefaultHAGAParams() HAGAParams {
+	return HAGAParams{
+		GroupNum:              10,
+		VMGamma:               0.6,
+		ChromosomesCount:      200,
+		IterationCount:        5000,
+		CrossoverProbability:  0.6,
+		MutationProbability:   0.7,
+		StopNoUpdateIteration: 250,
+	}
+}
+
+func (p *HAGAParams) validate() error {
+	if p.GroupNum <= 0 {
+		return fmt.Errorf("%w: groupNum is %d, it should be positive", ErrInvalidParams, p.GroupNum)
+	}
+	return validateSearch(p.ChromosomesCount, p.IterationCount, p.CrossoverProbability, p.MutationProbability, p.StopNoUpdateIteration)
+}
+
+func (p *HAGAParams) New(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	h, err := NewHAGA(p.GroupNum, p.VMGamma, p.ChromosomesCount, p.IterationCount, p.CrossoverProbability, p.MutationProbability, p.StopNoUpdateIteration, clouds, apps)
+	if err != nil {
+		return nil, err
+	}
+	p.SearchParams.apply(&h.Rand, &h.Evaluator)
+	return h, nil
+}
+
+// NSGAIIParams are the parameters of NSGAII, registered as "nsga2"
+type NSGAIIParams struct {
+	ChromosomesCount      int               `json:"chromosomesCount" yaml:"chromosomesCount"`
+	IterationCount        int               `json:"iterationCount" yaml:"iterationCount"`
+	CrossoverProbability  float64           `json:"crossoverProbability" yaml:"crossoverProbability"`
+	MutationProbability   float64           `json:"mutationProbability" yaml:"mutationProbability"`
+	StopNoUpdateIteration int               `json:"stopNoUpdateIteration" yaml:"stopNoUpdateIteration"`
+	ExtraObjectives       []ObjectiveConfig `json:"extraObjectives,omitempty" yaml:"extraObjectives,omitempty"`
+	SearchParams          `yaml:",inline"`
+}
+
+// DefaultNSGAIIParams returns the parameters of NSGA-II used in the experiments
+func DefaultNSGAIIParams() NSGAIIParams {
+	return NSGAIIParams{
+		ChromosomesCount:      200,
+		IterationCount:        5000,
+		CrossoverProbability:  1,
+		MutationProbability:   0.25,
+		StopNoUpdateIteration: 250,
+	}
+}
+
+func (p *NSGAIIParams) validate() error {
+	return validateSearch(p.ChromosomesCount, p.IterationCount, p.CrossoverProbability, p.MutationProbability, p.StopNoUpdateIteration)
+}
+
+func (p *NSGAIIParams) New(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	extraObjectives, err := objectivesOf(p.ExtraObjectives)
+	if err != nil {
+		return nil, err
+	}
+	n, err := NewNSGAII(p.ChromosomesCount, p.IterationCount, p.CrossoverProbability, p.MutationProbability, p.StopNoUpdateIteration, clouds, apps)
+	if err != nil {
+		return nil, err
+	}
+	n.ExtraObjectives = extraObjectives
+	p.SearchParams.apply(&n.Rand, &n.Evaluator)
+	return n, nil
+}
+
+// FirstFitParams are the parameters of FirstFit, registered as "firstfit", it has none
+type FirstFitParams struct{}
+
+func (p *FirstFitParams) New(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error) {
+	return NewFirstFit(clouds, apps), nil
+}
+
+// RandomFitParams are the parameters of RandomFit, registered as "randomfit"
+type RandomFitParams struct {
+	Seed *int64 `json:"seed,omitempty" yaml:"seed,omitempty"` // seed of the random source, a time seed is used if it is nil
+}
+
+func (p *RandomFitParams) New(clouds []model.Cloud, apps []model.Application) (SchedulingAlgorithm, error) {
+	rf := NewRandomFit(clouds, apps)
+	if p.Seed != nil {
+		rf.Rand = NewRandom(*p.Seed)
+	}
+	return rf, nil
+}
+
+func init() {
+	RegisterScheduler("mcasga", func() SchedulerParams { p := DefaultGeneticParams(); return &p })
+	RegisterScheduler("haga", func() SchedulerParams { p := DefaultHAGAParams(); return &p })
+	RegisterScheduler("nsga2", func() SchedulerParams { p := DefaultNSGAIIParams(); return &p })
+	RegisterScheduler("firstfit", func() SchedulerParams { return &FirstFitParams{} })
+	RegisterScheduler("randomfit", func() SchedulerParams { return &RandomFitParams{} })
+}
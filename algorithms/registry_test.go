@@ -0,0 +1,167 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerRegistry(t *testing.T) {
+	assert.Equal(t, []string{"firstfit", "haga", "mcasga", "nsga2", "randomfit"}, SchedulerNames())
+
+	testCases := []struct {
+		name     string
+		config   SchedulerConfig
+		check    func(t *testing.T, algorithm SchedulingAlgorithm)
+		expected error
+	}{
+		{
+			name:   "case 1: defaults",
+			config: SchedulerConfig{Algorithm: "mcasga"},
+			check: func(t *testing.T, algorithm SchedulingAlgorithm) {
+				g := algorithm.(*Genetic)
+				assert.Equal(t, 200, g.ChromosomesCount)
+				assert.Equal(t, 0.003, g.MutationProbability)
+				assert.True(t, g.BtSelection)
+				assert.Empty(t, g.Objectives)
+			},
+		},
+		{
+			name: "case 2: overridden params",
+			config: SchedulerConfig{Algorithm: "MCASGA", Params: map[string]interface{}{
+				"iterationCount": 10,
+				"crossover":      "twoPoint",
+				"seed":           42,
+				"workers":        2,
+				"objectives":     []interface{}{map[string]interface{}{"name": "makespan"}, map[string]interface{}{"name": "cost", "hours": 24}},
+			}},
+			check: func(t *testing.T, algorithm SchedulingAlgorithm) {
+				g := algorithm.(*Genetic)
+				assert.Equal(t, 10, g.IterationCount)
+				assert.Equal(t, 200, g.ChromosomesCount, "the params not in the config should keep their defaults")
+				assert.Equal(t, int64(42), g.Rand.Seed)
+				assert.Equal(t, 2, g.Evaluator.WorkerNum)
+				assert.Equal(t, "makespan", g.Objectives[0].Name)
+				assert.Equal(t, "cost", g.Objectives[1].Name)
+			},
+		},
+		{
+			name:   "case 3: nsga2 with extra objectives",
+			config: SchedulerConfig{Algorithm: "nsga2", Params: map[string]interface{}{"extraObjectives": []interface{}{map[string]interface{}{"name": "carbon", "period": 3600}}}},
+			check: func(t *testing.T, algorithm SchedulingAlgorithm) {
+				n := algorithm.(*NSGAII)
+				assert.Equal(t, 0.25, n.MutationProbability)
+				assert.Equal(t, "carbon", n.ExtraObjectives[0].Name)
+			},
+		},
+		{
+			name:   "case 4: haga",
+			config: SchedulerConfig{Algorithm: "haga", Params: map[string]interface{}{"groupNum": 2}},
+			check: func(t *testing.T, algorithm SchedulingAlgorithm) {
+				assert.Equal(t, 2, algorithm.(*HAGA).GroupNum)
+			},
+		},
+		{
+			name:   "case 5: random fit with a seed",
+			config: SchedulerConfig{Algorithm: "randomfit", Params: map[string]interface{}{"seed": 7}},
+			check: func(t *testing.T, algorithm SchedulingAlgorithm) {
+				assert.Equal(t, int64(7), algorithm.(*RandomFit).Rand.Seed)
+			},
+		},
+		{
+			name:     "case 6: unknown scheduler",
+			config:   SchedulerConfig{Algorithm: "simulatedAnnealing"},
+			expected: ErrUnknownScheduler,
+		},
+		{
+			name:     "case 7: unknown param",
+			config:   SchedulerConfig{Algorithm: "firstfit", Params: map[string]interface{}{"iterationCount": 10}},
+			expected: ErrInvalidParams,
+		},
+		{
+			name:     "case 8: param of a wrong type",
+			config:   SchedulerConfig{Algorithm: "haga", Params: map[string]interface{}{"groupNum": "two"}},
+			expected: ErrInvalidParams,
+		},
+		{
+			name:     "case 9: unknown fitness",
+			config:   SchedulerConfig{Algorithm: "mcasga", Params: map[string]interface{}{"fitness": map[string]interface{}{"name": "happiness"}}},
+			expected: ErrInvalidParams,
+		},
+		{
+			name:     "case 10: one chromosome",
+			config:   SchedulerConfig{Algorithm: "nsga2", Params: map[string]interface{}{"chromosomesCount": 1}},
+			expected: ErrInvalidParams,
+		},
+		{
+			name:     "case 11: no iteration",
+			config:   SchedulerConfig{Algorithm: "mcasga", Params: map[string]interface{}{"iterationCount": 0}},
+			expected: ErrInvalidParams,
+		},
+		{
+			name:     "case 12: no group",
+			config:   SchedulerConfig{Algorithm: "haga", Params: map[string]interface{}{"groupNum": 0}},
+			expected: ErrInvalidParams,
+		},
+		{
+			name:     "case 13: negative stopNoUpdateIteration",
+			config:   SchedulerConfig{Algorithm: "haga", Params: map[string]interface{}{"stopNoUpdateIteration": -1}},
+			expected: ErrInvalidParams,
+		},
+		{
+			name:     "case 14: crossover probability above 1",
+			config:   SchedulerConfig{Algorithm: "nsga2", Params: map[string]interface{}{"crossoverProbability": 1.5}},
+			expected: ErrInvalidParams,
+		},
+		{
+			name:     "case 15: negative mutation probability",
+			config:   SchedulerConfig{Algorithm: "mcasga", Params: map[string]interface{}{"mutationProbability": -0.1}},
+			expected: ErrInvalidParams,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		algorithm, err := NewScheduler(testCase.config, clouds, apps)
+		if testCase.expected != nil {
+			assert.True(t, errors.Is(err, testCase.expected), fmt.Sprintf("%s: error should be %v, but it is %v", testCase.name, testCase.expected, err))
+			continue
+		}
+		assert.NoError(t, err, fmt.Sprintf("%s: unexpected error", testCase.name))
+		testCase.check(t, algorithm)
+	}
+}
+
+func TestParseSchedulerConfigs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected []SchedulerConfig
+	}{
+		{
+			name:     "case 1: one JSON config",
+			data:     `{"algorithm": "mcasga", "params": {"iterationCount": 100}}`,
+			expected: []SchedulerConfig{{Algorithm: "mcasga", Params: map[string]interface{}{"iterationCount": 100}}},
+		},
+		{
+			name:     "case 2: a JSON list",
+			data:     `[{"algorithm": "firstfit"}, {"name": "rf", "algorithm": "randomfit", "params": {"seed": 1}}]`,
+			expected: []SchedulerConfig{{Algorithm: "firstfit"}, {Name: "rf", Algorithm: "randomfit", Params: map[string]interface{}{"seed": 1}}},
+		},
+		{
+			name:     "case 3: a YAML list",
+			data:     "- algorithm: nsga2\n  params:\n    mutationProbability: 0.1\n- algorithm: haga\n",
+			expected: []SchedulerConfig{{Algorithm: "nsga2", Params: map[string]interface{}{"mutationProbability": 0.1}}, {Algorithm: "haga"}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		configs, err := ParseSchedulerConfigs([]byte(testCase.data))
+		assert.NoError(t, err, fmt.Sprintf("%s: unexpected error", testCase.name))
+		assert.Equal(t, testCase.expected, configs, fmt.Sprintf("%s: configs not as expected", testCase.name))
+	}
+	_, err := ParseSchedulerConfigs([]byte("algorithm: [mcasga"))
+	assert.True(t, errors.Is(err, ErrInvalidParams), "a broken document should be an error")
+}
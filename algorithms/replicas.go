@@ -0,0 +1,148 @@
+package algorithms
+
+import (
+	"fmt"
+	"gogeneticwrsp/model"
+)
+
+// servingCloud returns the cloud of the replica of apps[appIdx] that serves the apps on clouds[cloudIdx], which is the accepted replica with the smallest RTT to clouds[cloudIdx].
+// replicaSets is from model.ReplicaSets. If apps[appIdx] is not replicated, it is the cloud of apps[appIdx]. It returns len(clouds) if all replicas are rejected.
+func servingCloud(clouds []model.Cloud, replicaSets [][]int, schedulingResult []int, appIdx, cloudIdx int) int {
+	if replicaSets == nil || replicaSets[appIdx] == nil {
+		return schedulingResult[appIdx]
+	}
+	var serving int = len(clouds)
+	for _, replicaIdx := range replicaSets[appIdx] {
+		replicaCloudIdx := schedulingResult[replicaIdx]
+		if replicaCloudIdx == len(clouds) {
+			continue
+		}
+		if serving == len(clouds) || clouds[cloudIdx].Allocatable.NetCondClouds[replicaCloudIdx].RTT < clouds[cloudIdx].Allocatable.NetCondClouds[serving].RTT {
+			serving = replicaCloudIdx
+		}
+	}
+	return serving
+}
+
+// replicasExpanded returns an error wrapping ErrInvalidParams if a Replicated service does not have all its replicas in apps.
+// The schedulers give every replica its own gene, so the replicas have to be expanded with model.ExpandReplicas first, otherwise the service is never acceptable.
+func replicasExpanded(apps []model.Application) error {
+	for appIdx, set := range model.ReplicaSets(apps) {
+		if set != nil && set[0] == appIdx && len(set) != apps[appIdx].Replicas {
+			return fmt.Errorf("%w: app %d has %d of its %d replicas, expand them with model.ExpandReplicas before scheduling", ErrInvalidParams, appIdx, len(set), apps[appIdx].Replicas)
+		}
+	}
+	return nil
+}
+
+// minSpread returns the number of different clouds that the replicas of a service need
+func minSpread(app model.Application) int {
+	if app.MinSpread > app.Replicas {
+		return app.Replicas
+	}
+	return app.MinSpread
+}
+
+// replicaViolations returns the services whose replicas are partly accepted, or are accepted but not spread on enough clouds
+func replicaViolations(clouds []model.Cloud, apps []model.Application, schedulingResult []int, replicaSets [][]int) []model.Violation {
+	var violations []model.Violation
+	for appIdx, set := range replicaSets {
+		if set == nil || set[0] != appIdx { // check every set once, with its first replica
+			continue
+		}
+		var accepted int
+		var usedClouds map[int]struct{} = make(map[int]struct{})
+		for _, replicaIdx := range set {
+			if schedulingResult[replicaIdx] != len(clouds) {
+				accepted++
+				usedClouds[schedulingResult[replicaIdx]] = struct{}{}
+			}
+		}
+		if accepted == 0 {
+			continue
+		}
+		if accepted < apps[appIdx].Replicas {
+			violations = append(violations, model.Violation{CloudIdx: -1, AppIdx: appIdx, Reason: model.RejectReasonReplicas, Resource: "replicas", DependAppIdx: -1, DependCloudIdx: -1, Required: float64(apps[appIdx].Replicas), Available: float64(accepted)})
+		}
+		if spread := minSpread(apps[appIdx]); len(usedClouds) < spread {
+			violations = append(violations, model.Violation{CloudIdx: -1, AppIdx: appIdx, Reason: model.RejectReasonSpread, Resource: "spread", DependAppIdx: -1, DependCloudIdx: -1, Required: float64(spread), Available: float64(len(usedClouds))})
+		}
+	}
+	return violations
+}
+
+// replicasOf returns the indexes of all replicas of the service of apps[appIdx], nil if it is not replicated
+func (fs *FeasibilityState) replicasOf(appIdx int) []int {
+	if fs.replicaSets == nil {
+		return nil
+	}
+	return fs.replicaSets[appIdx]
+}
+
+// servedBy returns the apps whose clouds decide the cloud serving a dependence on apps[appIdx], which are all its replicas
+func (fs *FeasibilityState) servedBy(appIdx int) []int {
+	if replicas := fs.replicasOf(appIdx); replicas != nil {
+		return replicas
+	}
+	return []int{appIdx}
+}
+
+// placeReplicas deploys all replicas of the service of apps[appIdx] together, because only a part of them is never acceptable, so they cannot be tested one at a time.
+// Every replica is put on the first cloud in candidates on which all clouds stay acceptable, and the clouds without other replicas go first until the spread is reached.
+// If the replicas are not acceptable together, they are moved back to their original clouds. It returns whether they are deployed.
+func (fs *FeasibilityState) placeReplicas(appIdx int, candidates []int) bool {
+	var replicas []int = fs.replicasOf(appIdx)
+	var oldClouds []int = make([]int, len(replicas))
+	for i, replicaIdx := range replicas {
+		oldClouds[i] = fs.schedulingResult[replicaIdx]
+	}
+	var restore func() = func() {
+		for i, replicaIdx := range replicas {
+			fs.Add(replicaIdx, oldClouds[i])
+		}
+	}
+
+	var spread int = minSpread(fs.apps[replicas[0]])
+	var usedClouds map[int]struct{} = make(map[int]struct{})
+	for _, replicaIdx := range replicas {
+		if !fs.apps[replicaIdx].IsNew && !fs.apps[replicaIdx].CanMigrate { // cannot be migrated
+			usedClouds[fs.schedulingResult[replicaIdx]] = struct{}{}
+			continue
+		}
+		var placed bool
+		for pass := 0; pass < 2 && !placed; pass++ {
+			for _, cloudIdx := range candidates {
+				if _, used := usedClouds[cloudIdx]; pass == 0 && used && len(usedClouds) < spread {
+					continue
+				}
+				fs.Add(replicaIdx, cloudIdx)
+				if fs.badClouds == 0 {
+					placed = true
+					usedClouds[cloudIdx] = struct{}{}
+					break
+				}
+			}
+		}
+		if !placed {
+			restore()
+			return false
+		}
+	}
+
+	if !fs.Acceptable() {
+		restore()
+		return false
+	}
+	return true
+}
+
+// randomCandidates returns the clouds in cloudIdxs that meet app in random order
+func randomCandidates(r *Random, clouds []model.Cloud, app model.Application, cloudIdxs []int) []int {
+	var candidates []int
+	for _, picked := range r.RandomPickN(cloudIdxs, len(cloudIdxs)) {
+		if CloudMeetApp(clouds[cloudIdxs[picked]], app) {
+			candidates = append(candidates, cloudIdxs[picked])
+		}
+	}
+	return candidates
+}
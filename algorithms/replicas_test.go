@@ -0,0 +1,146 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/model"
+)
+
+// app 2 is a service with 3 replicas on at least 2 clouds, apps 8 and 9 are its other replicas, and app 6 depends on it
+func forTestReplicatedApps() []model.Application {
+	apps := forTestSmallApps()
+	apps[2].Replicas = 3
+	apps[2].MinSpread = 2
+	apps[6].Depend = []model.Dependence{{AppIdx: 2, DownBw: 10, UpBw: 10, RTT: 15}, {AppIdx: 5, RTT: 100000}}
+	return model.ExpandReplicas(apps)
+}
+
+func TestReplicaViolations(t *testing.T) {
+	testCases := []struct {
+		name               string
+		schedulingResult   []int
+		expectedViolations []model.Violation
+	}{
+		{
+			name:               "all replicas rejected",
+			schedulingResult:   []int{0, 0, 3, 1, 1, 1, 3, 1, 3, 3},
+			expectedViolations: nil,
+		},
+		{
+			name:             "a part of replicas accepted",
+			schedulingResult: []int{0, 0, 1, 1, 1, 1, 3, 1, 2, 3},
+			expectedViolations: []model.Violation{
+				{CloudIdx: -1, AppIdx: 2, Reason: model.RejectReasonReplicas, Resource: "replicas", DependAppIdx: -1, DependCloudIdx: -1, Required: 3, Available: 2},
+			},
+		},
+		{
+			name:             "replicas not spread",
+			schedulingResult: []int{0, 0, 1, 1, 1, 1, 3, 1, 1, 1},
+			expectedViolations: []model.Violation{
+				{CloudIdx: -1, AppIdx: 2, Reason: model.RejectReasonSpread, Resource: "spread", DependAppIdx: -1, DependCloudIdx: -1, Required: 2, Available: 1},
+			},
+		},
+		{
+			name:               "served by the nearest replica",
+			schedulingResult:   []int{0, 0, 2, 1, 1, 1, 0, 1, 1, 2}, // RTT from cloud 0 to cloud 1 is 10, and to cloud 2 is 20
+			expectedViolations: nil,
+		},
+		{
+			name:             "no replica is near enough",
+			schedulingResult: []int{0, 0, 2, 1, 1, 1, 0, 1, 2, 2},
+			expectedViolations: []model.Violation{
+				{CloudIdx: 0, AppIdx: 6, Reason: model.RejectReasonRTT, Resource: "rtt", DependAppIdx: 2, DependCloudIdx: 2, Required: 15, Available: 20},
+				{CloudIdx: -1, AppIdx: 2, Reason: model.RejectReasonSpread, Resource: "spread", DependAppIdx: -1, DependCloudIdx: -1, Required: 2, Available: 1},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestReplicatedApps()
+		violations := AcceptableViolations(clouds, apps, testCase.schedulingResult)
+		assert.Equal(t, testCase.expectedViolations, violations, fmt.Sprintf("%s: violations are not expected", testCase.name))
+		assert.Equal(t, len(testCase.expectedViolations) == 0, NewFeasibilityState(clouds, apps, testCase.schedulingResult).Acceptable(), fmt.Sprintf("%s: FeasibilityState is not consistent", testCase.name))
+	}
+}
+
+func TestGreedySchedulersPlaceReplicasTogether(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule func(clouds []model.Cloud, apps []model.Application) []int
+	}{
+		{name: "FirstFit", schedule: FirstFitSchedule},
+		{name: "RandomFit", schedule: func(clouds []model.Cloud, apps []model.Application) []int {
+			return RandomFitSchedule(NewRandom(1), clouds, apps)
+		}},
+		{name: "InitializeAcceptableChromosome", schedule: func(clouds []model.Cloud, apps []model.Application) []int {
+			return InitializeAcceptableChromosome(NewRandom(1), clouds, apps)
+		}},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds(), forTestReplicatedApps()
+		schedulingResult := testCase.schedule(clouds, apps)
+		assert.True(t, Acceptable(clouds, apps, schedulingResult), fmt.Sprintf("%s: %v is not acceptable", testCase.name, schedulingResult))
+		var usedClouds map[int]struct{} = make(map[int]struct{})
+		for _, replicaIdx := range []int{2, 8, 9} {
+			assert.NotEqual(t, len(clouds), schedulingResult[replicaIdx], fmt.Sprintf("%s: replica %d is rejected", testCase.name, replicaIdx))
+			usedClouds[schedulingResult[replicaIdx]] = struct{}{}
+		}
+		assert.GreaterOrEqual(t, len(usedClouds), 2, fmt.Sprintf("%s: replicas are not spread", testCase.name))
+	}
+}
+
+func TestFeasibilityStateSameAsAcceptableWithReplicas(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		r := NewRandom(seed)
+		clouds, apps := forTestSmallClouds(), forTestReplicatedApps()
+		var schedulingResult []int = FirstFitSchedule(clouds, apps)
+		state := NewFeasibilityState(clouds, apps, schedulingResult)
+		for step := 0; step < 200; step++ {
+			appIdx, cloudIdx := r.RandomInt(0, len(apps)-1), r.RandomInt(0, len(clouds))
+			state.Add(appIdx, cloudIdx)
+			schedulingResult[appIdx] = cloudIdx
+			assert.Equal(t, Acceptable(clouds, apps, schedulingResult), state.Acceptable(), fmt.Sprintf("seed %d step %d: result %v", seed, step, schedulingResult))
+		}
+	}
+}
+
+func TestNSGAIIScoresNearestReplica(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestReplicatedApps()
+	n, err := NewNSGAII(20, 30, 1, 0.25, 10, clouds, apps)
+	assert.NoError(t, err)
+	solution, err := n.Schedule(clouds, apps)
+	assert.NoError(t, err)
+	assert.True(t, Acceptable(clouds, apps, solution.SchedulingResult), fmt.Sprintf("%v is not acceptable", solution.SchedulingResult))
+
+	// app 6 on cloud 0 depends on app 2, whose replicas are on clouds 2, 1 and 2, and on app 5 on cloud 1, RTT from cloud 0 to cloud 1 is 10, and to cloud 2 is 20
+	var schedulingResult []int = []int{0, 0, 2, 1, 1, 1, 0, 1, 1, 2}
+	var replicaSets [][]int = model.ReplicaSets(apps)
+	assert.Equal(t, 20.0, latencyOverheadOneApp(clouds, apps, replicaSets, 6, schedulingResult), "app 6 should be scored with the nearest replica of app 2")
+	var latencyOverhead float64
+	for appIdx := range apps {
+		if schedulingResult[appIdx] != len(clouds) {
+			latencyOverhead += latencyOverheadOneApp(clouds, apps, replicaSets, appIdx, schedulingResult)
+		}
+	}
+	// NSGA-II also counts the dependence of app 6 on task 5, with RTT 10, which ObjectiveInterCloudLatency skips like checkAcceptable
+	assert.Equal(t, ObjectiveInterCloudLatency.Value(clouds, apps, schedulingResult), latencyOverhead-10, "NSGA-II should score the latency of the services the same as the other schedulers")
+}
+
+func TestReplicasNotExpanded(t *testing.T) {
+	clouds, apps := forTestSmallClouds(), forTestSmallApps()
+	apps[2].Replicas = 3
+	for _, algorithm := range SchedulerNames() {
+		t.Logf("test: %s", algorithm)
+		_, err := NewScheduler(SchedulerConfig{Algorithm: algorithm}, clouds, apps)
+		assert.True(t, errors.Is(err, ErrInvalidParams), fmt.Sprintf("%s: error should be ErrInvalidParams, but it is %v", algorithm, err))
+		_, err = NewScheduler(SchedulerConfig{Algorithm: algorithm}, clouds, model.ExpandReplicas(apps))
+		assert.NoError(t, err, fmt.Sprintf("%s: unexpected error with the expanded replicas", algorithm))
+	}
+	_, err := NewGenetic(20, 30, 0.4, 0.05, 10, RandomFitSchedule, OnePointCrossOver, PriorityTimeFitness, true, false, clouds, apps)
+	assert.True(t, errors.Is(err, ErrInvalidParams), fmt.Sprintf("NewGenetic: error should be ErrInvalidParams, but it is %v", err))
+}
@@ -0,0 +1,317 @@
+package algorithms
+
+import (
+	"gogeneticwrsp/model"
+	"math"
+)
+
+// taskPool simulates the processor sharing of the logical cores left by services on a cloud with ConcurrentTasks among the tasks being executed on it
+type taskPool struct {
+	cores   float64 // number of shared logical cores
+	clock   float64 // CPU cycles per second of one logical core
+	sharing model.TaskSharing
+	now     float64 // the pool has been simulated until now, unit second
+	tasks   []poolTask
+}
+
+// poolTask is a task in a taskPool
+type poolTask struct {
+	appIdx     int
+	req        model.TaskResources
+	arrival    float64 // the task is executed from its StableTime, unit second
+	weight     float64 // the share of the task is weight / the total weight of the tasks being executed
+	remaining  float64 // number of CPU cycles not executed yet
+	done       bool
+	completion float64 // the completion time of the task, unit second
+}
+
+// newTaskPool creates the taskPool of a cloud after SimulateDeploy, all services in its RunningApps take up their logical cores
+func newTaskPool(cloud model.Cloud) *taskPool {
+	cores := cloud.Allocatable.CPU.LogicalCores
+	for _, runningApp := range cloud.RunningApps {
+		if !runningApp.IsTask {
+			cores -= runningApp.SvcReq.CPUClock / cloud.Allocatable.CPU.BaseClock
+		}
+	}
+	return &taskPool{
+		cores:   cores,
+		clock:   cloud.Allocatable.CPU.BaseClock * 1024 * 1024 * 1024,
+		sharing: cloud.TaskSharing,
+	}
+}
+
+// add puts a task into the pool, it is executed from arrival, which should not be before tp.now
+func (tp *taskPool) add(app model.Application, arrival float64) {
+	var weight float64 = 1
+	if tp.sharing == model.TaskSharingPriority {
+		weight = float64(app.Priority)
+	}
+	tp.tasks = append(tp.tasks, poolTask{appIdx: app.AppIdx, req: app.TaskReq, arrival: arrival, weight: weight, remaining: app.TaskReq.CPUCycle})
+}
+
+// executing returns whether tp.tasks[i] is being executed at tp.now
+func (tp *taskPool) executing(i int) bool {
+	return !tp.tasks[i].done && tp.tasks[i].arrival <= tp.now
+}
+
+// rates returns the CPU cycles per second of every task at tp.now.
+// The cores are shared according to the weights, but a task gets at most its MaxCores, and the cores it cannot use are shared by the other tasks.
+func (tp *taskPool) rates() []float64 {
+	var rates []float64 = make([]float64, len(tp.tasks))
+	if tp.cores <= 0 {
+		return rates
+	}
+	var shares []float64 = make([]float64, len(tp.tasks))
+	var limited []bool = make([]bool, len(tp.tasks))
+	var leftCores float64 = tp.cores
+	for changed := true; changed; {
+		changed = false
+		var totalWeight float64
+		for i := 0; i < len(tp.tasks); i++ {
+			if tp.executing(i) && !limited[i] {
+				totalWeight += tp.tasks[i].weight
+			}
+		}
+		if totalWeight <= 0 {
+			break
+		}
+		for i := 0; i < len(tp.tasks); i++ {
+			if !tp.executing(i) || limited[i] {
+				continue
+			}
+			shares[i] = leftCores * tp.tasks[i].weight / totalWeight
+			if maxCores := tp.tasks[i].req.MaxCores; maxCores > 0 && shares[i] > maxCores {
+				shares[i], limited[i], changed = maxCores, true, true
+				leftCores -= maxCores
+			}
+		}
+	}
+	for i := 0; i < len(tp.tasks); i++ {
+		if tp.executing(i) {
+			rates[i] = tp.tasks[i].req.EffectiveCores(shares[i]) * tp.clock
+		}
+	}
+	return rates
+}
+
+// nextEvent returns the time of the next arrival or completion after tp.now, and the index of the task completing at this time, -1 for an arrival.
+// It returns +Inf if no task will arrive or complete.
+func (tp *taskPool) nextEvent() (float64, int) {
+	var next float64 = math.Inf(1)
+	var completing int = -1
+	rates := tp.rates()
+	for i := 0; i < len(tp.tasks); i++ {
+		if tp.tasks[i].done {
+			continue
+		}
+		if tp.tasks[i].arrival > tp.now {
+			if tp.tasks[i].arrival < next {
+				next, completing = tp.tasks[i].arrival, -1
+			}
+		} else if rates[i] > 0 || tp.tasks[i].remaining <= 0 {
+			if completion := tp.now + math.Max(tp.tasks[i].remaining, 0)/math.Max(rates[i], math.SmallestNonzeroFloat64); completion < next {
+				next, completing = completion, i
+			}
+		}
+	}
+	return next, completing
+}
+
+// advance simulates the pool until time to
+func (tp *taskPool) advance(to float64) {
+	for {
+		next, completing := tp.nextEvent()
+		step := math.Min(next, to)
+		rates := tp.rates()
+		for i := 0; i < len(tp.tasks); i++ {
+			if rates[i] > 0 {
+				tp.tasks[i].remaining -= rates[i] * (step - tp.now)
+			}
+		}
+		if step > tp.now {
+			tp.now = step
+		}
+
+		if step == next && completing >= 0 {
+			tp.tasks[completing].remaining = 0
+		}
+		// the tasks completing at the same time
+		for i := 0; i < len(tp.tasks); i++ {
+			if tp.executing(i) && tp.tasks[i].remaining <= 1e-9*rates[i] {
+				tp.tasks[i].done, tp.tasks[i].remaining, tp.tasks[i].completion = true, 0, tp.now
+			}
+		}
+
+		if step >= to {
+			return
+		}
+	}
+}
+
+// remaining returns the CPU cycles not executed of the task of apps[appIdx] at tp.now
+func (tp *taskPool) remaining(appIdx int) float64 {
+	for i := 0; i < len(tp.tasks); i++ {
+		if tp.tasks[i].appIdx == appIdx {
+			return tp.tasks[i].remaining
+		}
+	}
+	return 0
+}
+
+// eventStartComplTime is CalcStartComplTime with the event-driven simulation of all clouds, it is used when any cloud executes tasks concurrently.
+// On every cloud, apps start one after another in the order of sortByExecutionOrder, every app starts after the app before it is stable (or completes, for a task on a cloud without ConcurrentTasks), and after its dependence.
+// A task on a cloud with ConcurrentTasks joins the taskPool of the cloud when it is stable, and its completion time is known when the simulation reaches it,
+// so the apps depending on it wait until then. On clouds without ConcurrentTasks, the result is the same as CalcStartComplTime.
+func eventStartComplTime(clouds []model.Cloud, apps []model.Application, chromosome Chromosome) []model.Application {
+	// initialization
+	for i := 0; i < len(clouds); i++ {
+		clouds[i].TotalTaskComplTime = 0
+		clouds[i].TmpAlloc.CPU.LogicalCores = clouds[i].Allocatable.CPU.LogicalCores
+	}
+	// save the original order of apps
+	unorderedApps := model.AppsCopy(apps)
+	sortByExecutionOrder(clouds, apps, chromosome)
+
+	var order []int = make([]int, len(apps))               // order[appIdx] is the position of this app in the execution order
+	var runningIdx []int = make([]int, len(apps))          // runningIdx[appIdx] is the index of this app in the RunningApps of its cloud
+	var queues [][]int = make([][]int, len(clouds))        // the apps on every cloud in the execution order
+	var pools []*taskPool = make([]*taskPool, len(clouds)) // nil for the clouds without ConcurrentTasks
+	for k := 0; k < len(apps); k++ {
+		order[apps[k].AppIdx] = k
+		cloudIndex := chromosome[apps[k].AppIdx]
+		if cloudIndex == len(clouds) {
+			continue // this app is rejected
+		}
+		for i := 0; i < len(clouds[cloudIndex].RunningApps); i++ {
+			// find the app in the RunningApps of this cloud
+			if clouds[cloudIndex].RunningApps[i].AppIdx == apps[k].AppIdx {
+				runningIdx[apps[k].AppIdx] = i
+				queues[cloudIndex] = append(queues[cloudIndex], apps[k].AppIdx)
+				break
+			}
+		}
+	}
+	for i := 0; i < len(clouds); i++ {
+		if clouds[i].ConcurrentTasks() {
+			pools[i] = newTaskPool(clouds[i])
+		}
+	}
+
+	var known []bool = make([]bool, len(apps)) // whether the StableTime of a service or the TaskCompletionTime of a task is known
+	var complete func(cloudIndex, appIdx int, completion float64) = func(cloudIndex, appIdx int, completion float64) {
+		clouds[cloudIndex].RunningApps[runningIdx[appIdx]].TaskCompletionTime = completion
+		unorderedApps[appIdx].TaskCompletionTime = completion
+		known[appIdx] = true
+	}
+
+	// an app can start after all its dependent apps before it have known times, and a task being executed already has all its dependence satisfied
+	var ready func(cloudIndex, appIdx int) bool = func(cloudIndex, appIdx int) bool {
+		thisApp := clouds[cloudIndex].RunningApps[runningIdx[appIdx]]
+		if executingOn(thisApp, cloudIndex) {
+			return true
+		}
+		for _, dependence := range thisApp.Depend {
+			if chromosome[dependence.AppIdx] != len(clouds) && order[dependence.AppIdx] < order[appIdx] && !known[dependence.AppIdx] {
+				return false
+			}
+		}
+		return true
+	}
+
+	var start func(cloudIndex, appIdx int) = func(cloudIndex, appIdx int) {
+		thisApp := &clouds[cloudIndex].RunningApps[runningIdx[appIdx]]
+		// the start time of every app should be after all its dependent apps
+		latestStartTime := clouds[cloudIndex].TotalTaskComplTime
+		for j := 0; j < len(thisApp.Depend) && !executingOn(*thisApp, cloudIndex); j++ {
+			dependentApp := unorderedApps[thisApp.Depend[j].AppIdx]
+			if dependentApp.IsTask { // should be after the completion time of every dependent task
+				latestStartTime = math.Max(latestStartTime, dependentApp.TaskCompletionTime)
+			} else { // should be after the start time of every dependent service
+				latestStartTime = math.Max(latestStartTime, dependentApp.StableTime)
+			}
+		}
+		clouds[cloudIndex].TotalTaskComplTime = latestStartTime
+
+		imagePullTime, dataInputTime, startUpTime := deployTimes(clouds[cloudIndex], *thisApp, cloudIndex, latestStartTime)
+		thisApp.StartTime = latestStartTime
+		thisApp.ImagePullDoneTime = thisApp.StartTime + imagePullTime
+		thisApp.DataInputDoneTime = thisApp.StartTime + imagePullTime + dataInputTime
+		thisApp.StableTime = thisApp.StartTime + imagePullTime + dataInputTime + startUpTime
+		unorderedApps[appIdx].StartTime = thisApp.StartTime
+		unorderedApps[appIdx].ImagePullDoneTime = thisApp.ImagePullDoneTime
+		unorderedApps[appIdx].DataInputDoneTime = thisApp.DataInputDoneTime
+		unorderedApps[appIdx].StableTime = thisApp.StableTime
+
+		switch {
+		case !thisApp.IsTask: // Services take up the resource
+			clouds[cloudIndex].TmpAlloc.CPU.LogicalCores -= thisApp.SvcReq.CPUClock / clouds[cloudIndex].TmpAlloc.CPU.BaseClock
+			clouds[cloudIndex].TotalTaskComplTime += imagePullTime + dataInputTime + startUpTime
+			known[appIdx] = true
+		case pools[cloudIndex] != nil: // Concurrent tasks are executed in the pool, and the next app can start after this task is stable
+			clouds[cloudIndex].TotalTaskComplTime += imagePullTime + dataInputTime + startUpTime
+			if pools[cloudIndex].cores <= 0 { // no logical cores are left by services
+				complete(cloudIndex, appIdx, math.Inf(1))
+			} else {
+				pools[cloudIndex].add(*thisApp, thisApp.StableTime)
+			}
+		default: // Tasks use all remaining resources to finish this task before handling other applications
+			execTime := taskExecTime(clouds[cloudIndex], *thisApp)
+			clouds[cloudIndex].TotalTaskComplTime += imagePullTime + dataInputTime + startUpTime + execTime
+			complete(cloudIndex, appIdx, clouds[cloudIndex].TotalTaskComplTime)
+		}
+	}
+
+	var next []int = make([]int, len(clouds)) // next[i] is the position of the next app to start in queues[i]
+	for {
+		// start all apps that are ready on every cloud, the times of services are known immediately, which may make apps on other clouds ready
+		for started := true; started; {
+			started = false
+			for i := 0; i < len(clouds); i++ {
+				for next[i] < len(queues[i]) && ready(i, queues[i][next[i]]) {
+					start(i, queues[i][next[i]])
+					next[i]++
+					started = true
+				}
+			}
+		}
+
+		// move all pools to the next arrival or completion of concurrent tasks
+		var now float64 = math.Inf(1)
+		for i := 0; i < len(pools); i++ {
+			if pools[i] != nil {
+				eventTime, _ := pools[i].nextEvent()
+				now = math.Min(now, eventTime)
+			}
+		}
+		if math.IsInf(now, 1) {
+			break
+		}
+		for i := 0; i < len(pools); i++ {
+			if pools[i] == nil {
+				continue
+			}
+			pools[i].advance(now)
+			for _, task := range pools[i].tasks {
+				if task.done && !known[task.appIdx] {
+					complete(i, task.appIdx, task.completion)
+				}
+			}
+		}
+	}
+
+	// the clouds with ConcurrentTasks finish all tasks when the last one completes
+	for i := 0; i < len(pools); i++ {
+		if pools[i] == nil {
+			continue
+		}
+		for _, task := range pools[i].tasks {
+			clouds[i].TotalTaskComplTime = math.Max(clouds[i].TotalTaskComplTime, task.completion)
+		}
+	}
+
+	// restore
+	for i := 0; i < len(clouds); i++ {
+		clouds[i].TmpAlloc.CPU.LogicalCores = clouds[i].Allocatable.CPU.LogicalCores
+	}
+	return unorderedApps
+}
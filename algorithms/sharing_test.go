@@ -0,0 +1,80 @@
+package algorithms
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/model"
+)
+
+func TestEventStartComplTimeSameAsSequential(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		r := NewRandom(seed)
+		clouds, apps := forTestSmallClouds(), forTestSmallApps()
+		var chromosome Chromosome = make(Chromosome, len(apps))
+		for i := 0; i < len(chromosome); i++ {
+			chromosome[i] = r.RandomInt(0, len(clouds))
+		}
+
+		sequentialClouds := SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: chromosome})
+		sequentialApps := CalcStartComplTime(sequentialClouds, model.AppsCopy(apps), chromosome)
+		eventClouds := SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: chromosome})
+		eventApps := eventStartComplTime(eventClouds, model.AppsCopy(apps), chromosome)
+
+		assert.Equal(t, sequentialApps, eventApps, fmt.Sprintf("seed %d: apps of chromosome %v", seed, chromosome))
+		assert.Equal(t, sequentialClouds, eventClouds, fmt.Sprintf("seed %d: clouds of chromosome %v", seed, chromosome))
+	}
+}
+
+func TestConcurrentTasks(t *testing.T) {
+	var gib float64 = 1024 * 1024 * 1024
+	// each task needs 1 second with all cores of cloud 0, and 0.07 second (image pulling RTT and data input RTT) before being stable
+	// app 2 is a service depending on app 0
+	var forTestApps func() []model.Application = func() []model.Application {
+		return []model.Application{
+			{IsTask: true, TaskReq: model.TaskResources{CPUCycle: 40 * gib, Memory: 20 * gib}, Priority: 2000, AppIdx: 0, IsNew: true},
+			{IsTask: true, TaskReq: model.TaskResources{CPUCycle: 40 * gib, Memory: 20 * gib}, Priority: 1000, AppIdx: 1, IsNew: true},
+			{Priority: 500, AppIdx: 2, IsNew: true, Depend: []model.Dependence{{AppIdx: 0}}},
+		}
+	}
+	var schedulingResult []int = []int{0, 0, 0}
+
+	testCases := []struct {
+		name         string
+		taskSharing  model.TaskSharing
+		complTimes   []float64 // TaskCompletionTime of app 0 and app 1
+		svcStart     float64   // StartTime of app 2
+		totalTime    float64   // TotalTaskComplTime of cloud 0
+		acceptable   bool      // the memory of the 2 tasks is more than cloud 0
+		executedRate []float64 // the rate of executed CPU cycles of app 0 and app 1 after 1 second
+	}{
+		{name: "case 1: one task at a time", taskSharing: model.TaskSharingNone, complTimes: []float64{1.07, 2.14}, svcStart: 2.14, totalTime: 2.21, acceptable: true, executedRate: []float64{0.93, 0}},
+		{name: "case 2: fair share", taskSharing: model.TaskSharingFair, complTimes: []float64{2, 2.07}, svcStart: 2, totalTime: 2.07, acceptable: false, executedRate: []float64{0.5, 0.43}},
+		{name: "case 3: priority-weighted share", taskSharing: model.TaskSharingPriority, complTimes: []float64{1.535, 2.07}, svcStart: 1.535, totalTime: 2.07, acceptable: false, executedRate: []float64{0.07 + 0.86*2/3, 0.86 / 3}},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		clouds, apps := forTestSmallClouds()[:1], forTestApps()
+		clouds[0].TaskSharing = testCase.taskSharing
+
+		deployedClouds := SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+		timeApps := CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+		for i := 0; i < len(testCase.complTimes); i++ {
+			assert.InDelta(t, testCase.complTimes[i], timeApps[i].TaskCompletionTime, 1e-6, fmt.Sprintf("%s: completion time of app %d", testCase.name, i))
+		}
+		assert.InDelta(t, testCase.svcStart, timeApps[2].StartTime, 1e-6, fmt.Sprintf("%s: start time of the service", testCase.name))
+		assert.InDelta(t, testCase.totalTime, deployedClouds[0].TotalTaskComplTime, 1e-6, fmt.Sprintf("%s: total completion time", testCase.name))
+		assert.Equal(t, testCase.acceptable, Acceptable(clouds, apps, schedulingResult), fmt.Sprintf("%s: acceptable", testCase.name))
+
+		remainingApps, err := CalcRemainingApps(clouds, deployedClouds, 1)
+		assert.NoError(t, err, testCase.name)
+		for _, remainingApp := range remainingApps {
+			if remainingApp.IsTask {
+				oriIdx := 2 - remainingApp.Priority/1000 // priority 2000 is app 0, and 1000 is app 1
+				assert.InDelta(t, testCase.executedRate[oriIdx]*apps[oriIdx].TaskReq.CPUCycle, remainingApp.ExecutedCPUCycle, 1e-6*gib, fmt.Sprintf("%s: executed CPU cycles of app %d", testCase.name, oriIdx))
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package algorithms
+
+import (
+	"gogeneticwrsp/model"
+	"math"
+)
+
+// DescribeSolution fills the placement of each app and the makespan of each cloud according to solution.SchedulingResult.
+// The time attributes of apps should be 0, the same as the input of Schedule.
+func DescribeSolution(clouds []model.Cloud, apps []model.Application, solution model.Solution) model.Solution {
+	var described model.Solution = model.SolutionCopy(solution)
+	var schedulingResult []int = described.SchedulingResult
+
+	// calculate the time attributes of all apps with this scheduling result
+	var deployedClouds []model.Cloud = SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+	var timeApps []model.Application = CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+
+	costs, hourlyCosts := appCosts(clouds, apps, schedulingResult)
+
+	described.Placements = make([]model.AppPlacement, len(schedulingResult))
+	described.CloudMakespans = make([]float64, len(clouds))
+	for appIdx := 0; appIdx < len(schedulingResult); appIdx++ {
+		if schedulingResult[appIdx] == len(clouds) {
+			reason, violations := rejectReason(clouds, apps, schedulingResult, appIdx)
+			described.Placements[appIdx] = model.AppPlacement{
+				AppIdx:       appIdx,
+				CloudIdx:     -1,
+				Rejected:     true,
+				RejectReason: reason,
+				Violations:   violations,
+			}
+			continue
+		}
+
+		thisApp := timeApps[appIdx]
+		described.Placements[appIdx] = model.AppPlacement{
+			AppIdx:             appIdx,
+			CloudIdx:           schedulingResult[appIdx],
+			StartTime:          thisApp.StartTime,
+			StableTime:         thisApp.StableTime,
+			TaskCompletionTime: thisApp.TaskCompletionTime,
+			Lateness:           thisApp.Lateness(),
+			Cost:               costs[appIdx],
+			HourlyCost:         hourlyCosts[appIdx],
+		}
+		described.Cost += costs[appIdx]
+		described.HourlyCost += hourlyCosts[appIdx]
+
+		// a service finishes its work on the cloud when it is stable, a task finishes when it completes
+		described.CloudMakespans[schedulingResult[appIdx]] = math.Max(described.CloudMakespans[schedulingResult[appIdx]], thisApp.FinishTime())
+	}
+
+	return described
+}
+
+// rejectReason explains why apps[appIdx] is rejected in schedulingResult, and returns the violations of deploying it on each cloud.
+// If the app can be deployed on any cloud without making schedulingResult unacceptable, the algorithm chose to reject it;
+// otherwise, the reason is the first violation caused by deploying it on the first cloud.
+func rejectReason(clouds []model.Cloud, apps []model.Application, schedulingResult []int, appIdx int) (model.RejectReason, []model.Violation) {
+	var tryResult []int = make([]int, len(schedulingResult))
+	copy(tryResult, schedulingResult)
+	var violations []model.Violation
+	var selectable bool
+	for cloudIdx := 0; cloudIdx < len(clouds); cloudIdx++ {
+		tryResult[appIdx] = cloudIdx
+		thisViolations := AcceptableViolations(clouds, apps, tryResult)
+		if len(thisViolations) == 0 {
+			selectable = true
+		}
+		violations = append(violations, thisViolations...)
+	}
+
+	for _, dependence := range apps[appIdx].Depend {
+		if schedulingResult[dependence.AppIdx] == len(clouds) {
+			return model.RejectReasonDependencyRejected, violations
+		}
+	}
+	if selectable || len(violations) == 0 {
+		return model.RejectReasonNotSelected, violations
+	}
+	return violations[0].Reason, violations
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"gogeneticwrsp/experimenttools"
+	"gogeneticwrsp/model"
+)
+
+func main() {
+	// set the log to show line number and file name
+	log.SetFlags(0 | log.Lshortfile)
+	var taskProportion float64
+	// read task proportion form the input parameter
+	if len(os.Args) > 1 {
+		var errParse error
+		taskProportion, errParse = strconv.ParseFloat(os.Args[1], 64)
+		if errParse != nil {
+			log.Println("errParse,", errParse)
+			taskProportion = 0.5
+		}
+	} else {
+		taskProportion = 0.5
+	}
+	log.Println("taskProportion", taskProportion)
+
+	// 10 clouds, 15 groups, in experiments
+	var numCloud int = 10
+	var groupNum int = 15
+	experimenttools.GenerateNumTimeGroup(groupNum)
+
+	var numTime experimenttools.NumTimeGroup = experimenttools.ReadNumTimeGroup(groupNum)
+	var numInGroup []int = numTime.NumInGroup
+	var appArrivalTimeIntervals []time.Duration = numTime.TimeIntervals
+
+	// generate clouds and apps, and write to files
+	experimenttools.GenerateClouds(numCloud)
+	for i := 0; i < len(numInGroup); i++ {
+		experimenttools.GenerateApps(numInGroup[i], fmt.Sprintf("%d", i), taskProportion)
+	}
+
+	// read clouds and apps from files
+	var clouds []model.Cloud
+	var appGroups [][]model.Application
+	clouds = experimenttools.ReadClouds(numCloud)
+	for i := 0; i < len(numInGroup); i++ {
+		appGroups = append(appGroups, experimenttools.ReadApps(numInGroup[i], fmt.Sprintf("%d", i)))
+	}
+
+	// cloud 3 is down from 120s to 300s, the bandwidth from cloud 2 to cloud 5 halves from 60s, and cloud 7 loses half of its resources from 200s
+	var failures []model.CloudFailure = []model.CloudFailure{
+		{Kind: model.FailureCloud, CloudIdx: 3, Start: 120, End: 300, Factor: 0},
+		{Kind: model.FailureLink, CloudIdx: 2, PeerCloudIdx: 5, Start: 60, Factor: 0.5},
+		{Kind: model.FailureCloud, CloudIdx: 7, Start: 200, Factor: 0.5},
+	}
+
+	experimenttools.FailureExperiment(clouds, appGroups, appArrivalTimeIntervals, failures, 10)
+
+}
@@ -47,6 +47,11 @@ func main() {
 	clouds = experimenttools.ReadClouds(numCloud)
 	apps = experimenttools.ReadApps(numApp, appSuffix)
 
-	experimenttools.OneTimeExperiment(clouds, apps)
+	// an optional second parameter is a JSON or YAML file of the schedulers to compare, e.g., [{"algorithm": "mcasga", "params": {"iterationCount": 1000}}, {"algorithm": "firstfit"}]
+	if len(os.Args) > 2 {
+		experimenttools.OneTimeExperimentWithConfigs(clouds, apps, experimenttools.ReadSchedulerConfigs(os.Args[2]))
+	} else {
+		experimenttools.OneTimeExperiment(clouds, apps)
+	}
 
 }
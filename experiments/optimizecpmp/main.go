@@ -140,16 +140,29 @@ func testParameters(crossoverProbability float64, mutationProbability float64, t
 	clouds = experimenttools.ReadClouds(numCloud)
 	apps = experimenttools.ReadApps(numApp, appSuffix)
 
-	var crossoverOperator func(algorithms.Chromosome, algorithms.Chromosome) (algorithms.Chromosome, algorithms.Chromosome)
+	var crossover string = "onePoint"
 	if twoPointCrossover {
-		crossoverOperator = algorithms.TwoPointCrossOver
-	} else {
-		crossoverOperator = algorithms.OnePointCrossOver
+		crossover = "twoPoint"
 	}
 
-	geneticAlgorithm := algorithms.NewGenetic(100, 5000, crossoverProbability, mutationProbability, 100, algorithms.RandomFitSchedule, crossoverOperator, btSelection, cbMutation, clouds, apps)
+	algorithm, err := algorithms.NewScheduler(algorithms.SchedulerConfig{
+		Algorithm: "mcasga",
+		Params: map[string]interface{}{
+			"chromosomesCount":      100,
+			"stopNoUpdateIteration": 100,
+			"crossoverProbability":  crossoverProbability,
+			"mutationProbability":   mutationProbability,
+			"crossover":             crossover,
+			"btSelection":           btSelection,
+			"cbMutation":            cbMutation,
+		},
+	}, clouds, apps)
+	if err != nil {
+		log.Panicf("algorithms.NewScheduler mcasga, error: %s", err.Error())
+	}
+	geneticAlgorithm := algorithm.(*algorithms.Genetic)
 
-	_, err := geneticAlgorithm.Schedule(clouds, apps)
+	_, err = geneticAlgorithm.Schedule(clouds, apps)
 	if err != nil {
 		log.Panicf("geneticAlgorithm.Schedule(clouds, apps), error: %s", err.Error())
 	}
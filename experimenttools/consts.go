@@ -264,4 +264,10 @@ var (
 	resStorToChoose []float64 = []float64{500, 1024, 2048, 3072, 4096, 5120, 250, 250, 300, 350, 350}                                                                           // unit GB
 	reqMemToChoose  []float64 = []float64{1, 1, 2, 1, 0.48828125}                                                                                                               // unit GB
 	ReqStorToChoose []float64 = []float64{8, 4, 3, 2}                                                                                                                           // unit GB
+
+	// GPUs are simulated resources, most clouds and apps do not have them
+	resGPUToChoose    []float64 = []float64{0, 0, 0, 0, 1, 2, 4, 8}    // number of GPUs of a cloud
+	resGPUMemToChoose []float64 = []float64{16, 24, 40, 80}            // memory of one GPU, unit GB
+	reqGPUToChoose    []float64 = []float64{0, 0, 0, 0, 0, 0, 1, 1, 2} // number of GPUs required by an app
+	reqGPUMemToChoose []float64 = []float64{2, 4, 8, 16}               // GPU memory required by an app, unit GB
 )
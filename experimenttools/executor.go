@@ -60,6 +60,8 @@ func GenerateClouds(numCloud int) {
 		clouds[i].Capacity.CPU = chooseResCPU()
 		clouds[i].Capacity.Memory = chooseResMem()
 		clouds[i].Capacity.Storage = chooseResStor()
+		clouds[i].Capacity.GPU = chooseResGPU()
+		clouds[i].Power = generatePower(clouds[i].Capacity.CPU.LogicalCores)
 
 		// network conditions
 		clouds[i].Capacity.NetCondClouds = make([]model.NetworkCondition, numCloud)
@@ -134,12 +136,14 @@ func GenerateApps(numApp int, suffix string, taskProportion float64) {
 
 			apps[i].TaskReq.Memory = chooseReqMem()
 			apps[i].TaskReq.Storage = chooseReqStor()
+			apps[i].TaskReq.GPU = chooseReqGPU()
 		} else {
 			currentSvcNum++
 			apps[i].IsTask = false
 			apps[i].SvcReq.CPUClock = generateSvcCPU()
 			apps[i].SvcReq.Memory = chooseReqMem()
 			apps[i].SvcReq.Storage = chooseReqStor()
+			apps[i].SvcReq.GPU = chooseReqGPU()
 		}
 
 		//apps[i].Priority = generatePriority(100, 10000, 5000, 5000)
@@ -297,6 +301,21 @@ func ReadApps(numApp int, suffix string) []model.Application {
 	return apps
 }
 
+// ReadSchedulerConfigs from a JSON or YAML file of one algorithms.SchedulerConfig or a list of them
+func ReadSchedulerConfigs(path string) []algorithms.SchedulerConfig {
+	configData, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatalln("ioutil.ReadFile(path) error:", err.Error())
+	}
+
+	configs, err := algorithms.ParseSchedulerConfigs(configData)
+	if err != nil {
+		log.Fatalln("algorithms.ParseSchedulerConfigs(configData) error:", err.Error())
+	}
+
+	return configs
+}
+
 type NumTimeGroup struct {
 	NumInGroup    []int           `json:"numInGroup"`
 	TimeIntervals []time.Duration `json:"timeIntervals"`
@@ -367,61 +386,56 @@ type OneTimeHelper struct {
 	ExperimentSolution  model.Solution
 }
 
+// oneTimeConfigs are the schedulers compared by OneTimeExperiment, the genetic algorithm with every combination of the crossover, selection and mutation operators, First Fit and Random Fit
+func oneTimeConfigs() []algorithms.SchedulerConfig {
+	var configs []algorithms.SchedulerConfig
+	for _, crossover := range []string{"onePoint", "twoPoint"} {
+		for _, cbMutation := range []bool{true, false} {
+			for _, btSelection := range []bool{true, false} {
+				var crossoverName, selectionName, mutationName string = "1-point", "binary-tournament", "chromosome-based"
+				var mutationProbability float64 = 0.25
+				if crossover == "twoPoint" {
+					crossoverName = "2-point"
+				}
+				if !btSelection {
+					selectionName = "roulette-wheel"
+				}
+				if !cbMutation {
+					mutationName = "gene-based"
+					mutationProbability = 0.001
+				}
+				configs = append(configs, algorithms.SchedulerConfig{
+					Name:      fmt.Sprintf("ga/%s-Crossover/%s-Selection/%s-Mutation", crossoverName, selectionName, mutationName),
+					Algorithm: "mcasga",
+					Params: map[string]interface{}{
+						"crossoverProbability": 0.3,
+						"mutationProbability":  mutationProbability,
+						"crossover":            crossover,
+						"btSelection":          btSelection,
+						"cbMutation":           cbMutation,
+					},
+				})
+			}
+		}
+	}
+	return append(configs, algorithms.SchedulerConfig{Name: "First Fit", Algorithm: "firstfit"}, algorithms.SchedulerConfig{Name: "Random Fit", Algorithm: "randomfit"})
+}
+
 // OneTimeExperiment is that all applications are deployed in one time and handled together
 func OneTimeExperiment(clouds []model.Cloud, apps []model.Application) {
-	ga1pcbtscbm := algorithms.NewGenetic(200, 5000, 0.3, 0.25, 250, algorithms.RandomFitSchedule, algorithms.OnePointCrossOver, true, true, clouds, apps)
-	ga1pcrhscbm := algorithms.NewGenetic(200, 5000, 0.3, 0.25, 250, algorithms.RandomFitSchedule, algorithms.OnePointCrossOver, false, true, clouds, apps)
-	ga1pcbtsgbm := algorithms.NewGenetic(200, 5000, 0.3, 0.001, 250, algorithms.RandomFitSchedule, algorithms.OnePointCrossOver, true, false, clouds, apps)
-	ga1pcrhsgbm := algorithms.NewGenetic(200, 5000, 0.3, 0.001, 250, algorithms.RandomFitSchedule, algorithms.OnePointCrossOver, false, false, clouds, apps)
-	ga2pcbtscbm := algorithms.NewGenetic(200, 5000, 0.3, 0.25, 250, algorithms.RandomFitSchedule, algorithms.TwoPointCrossOver, true, true, clouds, apps)
-	ga2pcrhscbm := algorithms.NewGenetic(200, 5000, 0.3, 0.25, 250, algorithms.RandomFitSchedule, algorithms.TwoPointCrossOver, false, true, clouds, apps)
-	ga2pcbtsgbm := algorithms.NewGenetic(200, 5000, 0.3, 0.001, 250, algorithms.RandomFitSchedule, algorithms.TwoPointCrossOver, true, false, clouds, apps)
-	ga2pcrhsgbm := algorithms.NewGenetic(200, 5000, 0.3, 0.001, 250, algorithms.RandomFitSchedule, algorithms.TwoPointCrossOver, false, false, clouds, apps)
-
-	ff := algorithms.NewFirstFit(clouds, apps)
-	rf := algorithms.NewRandomFit(clouds, apps)
-
-	var experimenters []OneTimeHelper = []OneTimeHelper{
-		{
-			Name:                "ga/1-point-Crossover/binary-tournament-Selection/chromosome-based-Mutation",
-			ExperimentAlgorithm: ga1pcbtscbm,
-		},
-		{
-			Name:                "ga/1-point-Crossover/roulette-wheel-Selection/chromosome-based-Mutation",
-			ExperimentAlgorithm: ga1pcrhscbm,
-		},
-		{
-			Name:                "ga/1-point-Crossover/binary-tournament-Selection/gene-based-Mutation",
-			ExperimentAlgorithm: ga1pcbtsgbm,
-		},
-		{
-			Name:                "ga/1-point-Crossover/roulette-wheel-Selection/gene-based-Mutation",
-			ExperimentAlgorithm: ga1pcrhsgbm,
-		},
-		{
-			Name:                "ga/2-point-Crossover/binary-tournament-Selection/chromosome-based-Mutation",
-			ExperimentAlgorithm: ga2pcbtscbm,
-		},
-		{
-			Name:                "ga/2-point-Crossover/roulette-wheel-Selection/chromosome-based-Mutation",
-			ExperimentAlgorithm: ga2pcrhscbm,
-		},
-		{
-			Name:                "ga/2-point-Crossover/binary-tournament-Selection/gene-based-Mutation",
-			ExperimentAlgorithm: ga2pcbtsgbm,
-		},
-		{
-			Name:                "ga/2-point-Crossover/roulette-wheel-Selection/gene-based-Mutation",
-			ExperimentAlgorithm: ga2pcrhsgbm,
-		},
-		{
-			Name:                "First Fit",
-			ExperimentAlgorithm: ff,
-		},
-		{
-			Name:                "Random Fit",
-			ExperimentAlgorithm: rf,
-		},
+	OneTimeExperimentWithConfigs(clouds, apps, oneTimeConfigs())
+}
+
+// OneTimeExperimentWithConfigs is OneTimeExperiment comparing the schedulers in configs
+func OneTimeExperimentWithConfigs(clouds []model.Cloud, apps []model.Application, configs []algorithms.SchedulerConfig) {
+	var experimenters []OneTimeHelper
+	for _, config := range configs {
+		// all schedulers share the same input, so an invalid input or config fails the experiment
+		algorithm, err := algorithms.NewScheduler(config, clouds, apps)
+		if err != nil {
+			log.Panicf("algorithms.NewScheduler, %s, error: %s", config.Label(), err.Error())
+		}
+		experimenters = append(experimenters, OneTimeHelper{Name: config.Label(), ExperimentAlgorithm: algorithm})
 	}
 
 	for i := 0; i < len(experimenters); i++ {
@@ -445,12 +459,16 @@ type ContinuousHelper struct {
 	CPUIdleRecords     []float64
 	MemoryIdleRecords  []float64
 	StorageIdleRecords []float64
+	GPUIdleRecords     []float64
 	BwIdleRecords      []float64
 
 	AcceptedPriorityRateRecords []float64
 	AcceptedSvcPriRateRecords   []float64
 	AcceptedTaskPriRateRecords  []float64
 
+	EnergyRecords []float64 // the energy consumed by all clouds in every round, unit joule
+	CarbonRecords []float64 // the carbon emissions of EnergyRecords, unit gram
+
 	CloudsWithTime        [][]float64
 	AllAppComplTime       []float64
 	AllAppComplTimePerPri []float64
@@ -459,6 +477,10 @@ type ContinuousHelper struct {
 	TaskComplTime    []float64 // record the weighted time since generated until completed of every task
 	maxSvcSusTime    float64
 	maxTaskComplTime float64
+
+	// only recorded in FailureExperiment
+	EvictedAppRecords []float64 // the number of apps restarted because of cloud failures in every round
+	LostAppRecords    []float64 // the number of remaining apps rejected in every round, which are lost
 }
 
 func NewFirstFitRecorder() ContinuousHelper {
@@ -467,15 +489,20 @@ func NewFirstFitRecorder() ContinuousHelper {
 		CPUIdleRecords:              make([]float64, 0),
 		MemoryIdleRecords:           make([]float64, 0),
 		StorageIdleRecords:          make([]float64, 0),
+		GPUIdleRecords:              make([]float64, 0),
 		BwIdleRecords:               make([]float64, 0),
 		AcceptedPriorityRateRecords: make([]float64, 0),
 		AcceptedSvcPriRateRecords:   make([]float64, 0),
 		AcceptedTaskPriRateRecords:  make([]float64, 0),
+		EnergyRecords:               make([]float64, 0),
+		CarbonRecords:               make([]float64, 0),
 		CloudsWithTime:              make([][]float64, 0),
 		AllAppComplTime:             make([]float64, 0),
 		AllAppComplTimePerPri:       make([]float64, 0),
 		SvcSusTime:                  make([]float64, 0),
 		TaskComplTime:               make([]float64, 0),
+		EvictedAppRecords:           make([]float64, 0),
+		LostAppRecords:              make([]float64, 0),
 	}
 }
 
@@ -485,15 +512,20 @@ func NewRandomFitRecorder() ContinuousHelper {
 		CPUIdleRecords:              make([]float64, 0),
 		MemoryIdleRecords:           make([]float64, 0),
 		StorageIdleRecords:          make([]float64, 0),
+		GPUIdleRecords:              make([]float64, 0),
 		BwIdleRecords:               make([]float64, 0),
 		AcceptedPriorityRateRecords: make([]float64, 0),
 		AcceptedSvcPriRateRecords:   make([]float64, 0),
 		AcceptedTaskPriRateRecords:  make([]float64, 0),
+		EnergyRecords:               make([]float64, 0),
+		CarbonRecords:               make([]float64, 0),
 		CloudsWithTime:              make([][]float64, 0),
 		AllAppComplTime:             make([]float64, 0),
 		AllAppComplTimePerPri:       make([]float64, 0),
 		SvcSusTime:                  make([]float64, 0),
 		TaskComplTime:               make([]float64, 0),
+		EvictedAppRecords:           make([]float64, 0),
+		LostAppRecords:              make([]float64, 0),
 	}
 }
 
@@ -503,15 +535,20 @@ func NewNSGAIIRecorder() ContinuousHelper {
 		CPUIdleRecords:              make([]float64, 0),
 		MemoryIdleRecords:           make([]float64, 0),
 		StorageIdleRecords:          make([]float64, 0),
+		GPUIdleRecords:              make([]float64, 0),
 		BwIdleRecords:               make([]float64, 0),
 		AcceptedPriorityRateRecords: make([]float64, 0),
 		AcceptedSvcPriRateRecords:   make([]float64, 0),
 		AcceptedTaskPriRateRecords:  make([]float64, 0),
+		EnergyRecords:               make([]float64, 0),
+		CarbonRecords:               make([]float64, 0),
 		CloudsWithTime:              make([][]float64, 0),
 		AllAppComplTime:             make([]float64, 0),
 		AllAppComplTimePerPri:       make([]float64, 0),
 		SvcSusTime:                  make([]float64, 0),
 		TaskComplTime:               make([]float64, 0),
+		EvictedAppRecords:           make([]float64, 0),
+		LostAppRecords:              make([]float64, 0),
 	}
 }
 
@@ -521,15 +558,20 @@ func NewHAGARecorder() ContinuousHelper {
 		CPUIdleRecords:              make([]float64, 0),
 		MemoryIdleRecords:           make([]float64, 0),
 		StorageIdleRecords:          make([]float64, 0),
+		GPUIdleRecords:              make([]float64, 0),
 		BwIdleRecords:               make([]float64, 0),
 		AcceptedPriorityRateRecords: make([]float64, 0),
 		AcceptedSvcPriRateRecords:   make([]float64, 0),
 		AcceptedTaskPriRateRecords:  make([]float64, 0),
+		EnergyRecords:               make([]float64, 0),
+		CarbonRecords:               make([]float64, 0),
 		CloudsWithTime:              make([][]float64, 0),
 		AllAppComplTime:             make([]float64, 0),
 		AllAppComplTimePerPri:       make([]float64, 0),
 		SvcSusTime:                  make([]float64, 0),
 		TaskComplTime:               make([]float64, 0),
+		EvictedAppRecords:           make([]float64, 0),
+		LostAppRecords:              make([]float64, 0),
 	}
 }
 
@@ -539,15 +581,20 @@ func NewMCASGARecorder() ContinuousHelper {
 		CPUIdleRecords:              make([]float64, 0),
 		MemoryIdleRecords:           make([]float64, 0),
 		StorageIdleRecords:          make([]float64, 0),
+		GPUIdleRecords:              make([]float64, 0),
 		BwIdleRecords:               make([]float64, 0),
 		AcceptedPriorityRateRecords: make([]float64, 0),
 		AcceptedSvcPriRateRecords:   make([]float64, 0),
 		AcceptedTaskPriRateRecords:  make([]float64, 0),
+		EnergyRecords:               make([]float64, 0),
+		CarbonRecords:               make([]float64, 0),
 		CloudsWithTime:              make([][]float64, 0),
 		AllAppComplTime:             make([]float64, 0),
 		AllAppComplTimePerPri:       make([]float64, 0),
 		SvcSusTime:                  make([]float64, 0),
 		TaskComplTime:               make([]float64, 0),
+		EvictedAppRecords:           make([]float64, 0),
+		LostAppRecords:              make([]float64, 0),
 	}
 }
 
@@ -578,6 +625,21 @@ func (ch *ContinuousHelper) setSvcSusTaskComplTime(clouds []model.Cloud, totalAp
 }
 
 // set the service suspension time and task completion time of rejected apps as very high
+// recordEnergy records the energy and carbon emissions of round i, in which apps are scheduled on clouds with schedulingResult.
+// A round lasts until the next group of apps comes, and the last round lasts until longestTime, when all its apps finish, unit second since the beginning of the experiment.
+func (ch *ContinuousHelper) recordEnergy(clouds []model.Cloud, apps []model.Application, schedulingResult []int, appArrivalTimeIntervals []time.Duration, i int, longestTime float64) {
+	var start float64
+	for j := 0; j <= i; j++ {
+		start += float64(appArrivalTimeIntervals[j]) / float64(time.Second)
+	}
+	var period float64 = longestTime - start
+	if i+1 < len(appArrivalTimeIntervals) {
+		period = float64(appArrivalTimeIntervals[i+1]) / float64(time.Second)
+	}
+	ch.EnergyRecords = append(ch.EnergyRecords, algorithms.Energy(clouds, apps, schedulingResult, period))
+	ch.CarbonRecords = append(ch.CarbonRecords, algorithms.Carbon(clouds, apps, schedulingResult, start, period))
+}
+
 func setRejectedSvcTask(recorders ...*ContinuousHelper) {
 	var totalMaxSvcSus, totalMaxTaskCompl float64 = 0, 0
 	for _, recorder := range recorders {
@@ -720,6 +782,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			}
 			firstFitRecorder.CloudsWithTime = append(firstFitRecorder.CloudsWithTime, thisTimeRecord)
 			firstFitRecorder.AllAppComplTime = append(firstFitRecorder.AllAppComplTime, longestTime)
+			firstFitRecorder.recordEnergy(currentClouds, thisAppGroup, solution.SchedulingResult, appArrivalTimeIntervals, i, longestTime)
 			log.Println("thisTimeRecord", thisTimeRecord)
 			log.Println("firstFitRecorder.AllAppComplTime", firstFitRecorder.AllAppComplTime)
 
@@ -739,6 +802,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			firstFitRecorder.CPUIdleRecords = append(firstFitRecorder.CPUIdleRecords, algorithms.CPUIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			firstFitRecorder.MemoryIdleRecords = append(firstFitRecorder.MemoryIdleRecords, algorithms.MemoryIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			firstFitRecorder.StorageIdleRecords = append(firstFitRecorder.StorageIdleRecords, algorithms.StorageIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
+			firstFitRecorder.GPUIdleRecords = append(firstFitRecorder.GPUIdleRecords, algorithms.GPUIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			firstFitRecorder.BwIdleRecords = append(firstFitRecorder.BwIdleRecords, algorithms.BwIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 
 			firstFitRecorder.AcceptedPriorityRateRecords = append(firstFitRecorder.AcceptedPriorityRateRecords, float64(algorithms.AcceptedPriority(clouds, totalApps, currentSolution.SchedulingResult))/float64(algorithms.TotalPriority(clouds, totalApps, currentSolution.SchedulingResult)))
@@ -816,6 +880,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			}
 			randomFitRecorder.CloudsWithTime = append(randomFitRecorder.CloudsWithTime, thisTimeRecord)
 			randomFitRecorder.AllAppComplTime = append(randomFitRecorder.AllAppComplTime, longestTime)
+			randomFitRecorder.recordEnergy(currentClouds, thisAppGroup, solution.SchedulingResult, appArrivalTimeIntervals, i, longestTime)
 			log.Println("thisTimeRecord", thisTimeRecord)
 			log.Println("randomFitRecorder.AllAppComplTime", randomFitRecorder.AllAppComplTime)
 
@@ -835,6 +900,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			randomFitRecorder.CPUIdleRecords = append(randomFitRecorder.CPUIdleRecords, algorithms.CPUIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			randomFitRecorder.MemoryIdleRecords = append(randomFitRecorder.MemoryIdleRecords, algorithms.MemoryIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			randomFitRecorder.StorageIdleRecords = append(randomFitRecorder.StorageIdleRecords, algorithms.StorageIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
+			randomFitRecorder.GPUIdleRecords = append(randomFitRecorder.GPUIdleRecords, algorithms.GPUIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			randomFitRecorder.BwIdleRecords = append(randomFitRecorder.BwIdleRecords, algorithms.BwIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 
 			randomFitRecorder.AcceptedPriorityRateRecords = append(randomFitRecorder.AcceptedPriorityRateRecords, float64(algorithms.AcceptedPriority(clouds, totalApps, currentSolution.SchedulingResult))/float64(algorithms.TotalPriority(clouds, totalApps, currentSolution.SchedulingResult)))
@@ -861,7 +927,10 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			totalApps = model.CombApps(totalApps, apps[i])
 			thisAppGroup := apps[i]
 
-			nsga := algorithms.NewNSGAII(200, 5000, 1, 0.25, 250, currentClouds, thisAppGroup)
+			nsga, err := algorithms.NewScheduler(algorithms.SchedulerConfig{Algorithm: "nsga2"}, currentClouds, thisAppGroup)
+			if err != nil {
+				log.Panicf("algorithms.NewScheduler nsga2, app %d, error: %s", i, err.Error())
+			}
 			solution, err := nsga.Schedule(currentClouds, thisAppGroup)
 			if err != nil {
 				log.Printf("Error, app %d. Error message: %s", i, err.Error())
@@ -913,6 +982,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			}
 			NSGAIIRecorder.CloudsWithTime = append(NSGAIIRecorder.CloudsWithTime, thisTimeRecord)
 			NSGAIIRecorder.AllAppComplTime = append(NSGAIIRecorder.AllAppComplTime, longestTime)
+			NSGAIIRecorder.recordEnergy(currentClouds, thisAppGroup, solution.SchedulingResult, appArrivalTimeIntervals, i, longestTime)
 			log.Println("thisTimeRecord", thisTimeRecord)
 			log.Println("NSGAIIRecorder.AllAppComplTime", NSGAIIRecorder.AllAppComplTime)
 
@@ -932,6 +1002,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			NSGAIIRecorder.CPUIdleRecords = append(NSGAIIRecorder.CPUIdleRecords, algorithms.CPUIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			NSGAIIRecorder.MemoryIdleRecords = append(NSGAIIRecorder.MemoryIdleRecords, algorithms.MemoryIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			NSGAIIRecorder.StorageIdleRecords = append(NSGAIIRecorder.StorageIdleRecords, algorithms.StorageIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
+			NSGAIIRecorder.GPUIdleRecords = append(NSGAIIRecorder.GPUIdleRecords, algorithms.GPUIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			NSGAIIRecorder.BwIdleRecords = append(NSGAIIRecorder.BwIdleRecords, algorithms.BwIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 
 			NSGAIIRecorder.AcceptedPriorityRateRecords = append(NSGAIIRecorder.AcceptedPriorityRateRecords, float64(algorithms.AcceptedPriority(clouds, totalApps, currentSolution.SchedulingResult))/float64(algorithms.TotalPriority(clouds, totalApps, currentSolution.SchedulingResult)))
@@ -958,7 +1029,10 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			totalApps = model.CombApps(totalApps, apps[i])
 			thisAppGroup := apps[i]
 
-			haga := algorithms.NewHAGA(10, 0.6, 200, 5000, 0.6, 0.7, 250, currentClouds, thisAppGroup)
+			haga, err := algorithms.NewScheduler(algorithms.SchedulerConfig{Algorithm: "haga"}, currentClouds, thisAppGroup)
+			if err != nil {
+				log.Panicf("algorithms.NewScheduler haga, app %d, error: %s", i, err.Error())
+			}
 			solution, err := haga.Schedule(currentClouds, thisAppGroup)
 			if err != nil {
 				log.Printf("Error, app %d. Error message: %s", i, err.Error())
@@ -1010,6 +1084,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			}
 			HAGARecorder.CloudsWithTime = append(HAGARecorder.CloudsWithTime, thisTimeRecord)
 			HAGARecorder.AllAppComplTime = append(HAGARecorder.AllAppComplTime, longestTime)
+			HAGARecorder.recordEnergy(currentClouds, thisAppGroup, solution.SchedulingResult, appArrivalTimeIntervals, i, longestTime)
 			log.Println("thisTimeRecord", thisTimeRecord)
 			log.Println("HAGARecorder.AllAppComplTime", HAGARecorder.AllAppComplTime)
 
@@ -1029,6 +1104,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			HAGARecorder.CPUIdleRecords = append(HAGARecorder.CPUIdleRecords, algorithms.CPUIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			HAGARecorder.MemoryIdleRecords = append(HAGARecorder.MemoryIdleRecords, algorithms.MemoryIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			HAGARecorder.StorageIdleRecords = append(HAGARecorder.StorageIdleRecords, algorithms.StorageIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
+			HAGARecorder.GPUIdleRecords = append(HAGARecorder.GPUIdleRecords, algorithms.GPUIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 			HAGARecorder.BwIdleRecords = append(HAGARecorder.BwIdleRecords, algorithms.BwIdleRate(clouds, totalApps, currentSolution.SchedulingResult))
 
 			HAGARecorder.AcceptedPriorityRateRecords = append(HAGARecorder.AcceptedPriorityRateRecords, float64(algorithms.AcceptedPriority(clouds, totalApps, currentSolution.SchedulingResult))/float64(algorithms.TotalPriority(clouds, totalApps, currentSolution.SchedulingResult)))
@@ -1082,7 +1158,10 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 				// timeClouds have the information: 1. execution time; 2. deployed apps
 				// I need to use them to calculate: at this time (this round), what applications are still running on each cloud, and how many cycles of them still need to be executed
 
-				remainingApps := algorithms.CalcRemainingApps(resClouds, timeClouds, timeIntervalSec)
+				remainingApps, err := algorithms.CalcRemainingApps(resClouds, timeClouds, timeIntervalSec)
+				if err != nil {
+					log.Panicf("algorithms.CalcRemainingApps, app %d, error: %s", i, err.Error())
+				}
 				appsToDeploy = model.CombApps(appsToDeploy, remainingApps) // this group of apps + remaining apps
 
 			}
@@ -1091,7 +1170,10 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			lastApps = model.AppsCopy(appsToDeploy)
 
 			//ga := algorithms.NewGenetic(100, 5000, 0.7, 0.007, 2000, algorithms.InitializeUndeployedChromosome, clouds, totalApps)
-			ga := algorithms.NewGenetic(200, 5000, 0.4, 0.003, 250, algorithms.RandomFitSchedule, algorithms.OnePointCrossOver, true, false, clouds, appsToDeploy)
+			ga, err := algorithms.NewScheduler(algorithms.SchedulerConfig{Algorithm: "mcasga"}, clouds, appsToDeploy)
+			if err != nil {
+				log.Panicf("algorithms.NewScheduler mcasga, app %d, error: %s", i, err.Error())
+			}
 			solution, err := ga.Schedule(clouds, appsToDeploy)
 			if err != nil {
 				log.Printf("Error, app %d. Error message: %s", i, err.Error())
@@ -1129,6 +1211,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			}
 			MCASGARecorder.CloudsWithTime = append(MCASGARecorder.CloudsWithTime, thisTimeRecord)
 			MCASGARecorder.AllAppComplTime = append(MCASGARecorder.AllAppComplTime, longestTime)
+			MCASGARecorder.recordEnergy(clouds, appsToDeploy, solution.SchedulingResult, appArrivalTimeIntervals, i, longestTime)
 			log.Println("thisTimeRecord", thisTimeRecord)
 			log.Println("MCASGARecorder.AllAppComplTime", MCASGARecorder.AllAppComplTime)
 
@@ -1152,6 +1235,7 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			MCASGARecorder.CPUIdleRecords = append(MCASGARecorder.CPUIdleRecords, algorithms.CPUIdleRate(clouds, appsToDeploy, currentSolution.SchedulingResult))
 			MCASGARecorder.MemoryIdleRecords = append(MCASGARecorder.MemoryIdleRecords, algorithms.MemoryIdleRate(clouds, appsToDeploy, currentSolution.SchedulingResult))
 			MCASGARecorder.StorageIdleRecords = append(MCASGARecorder.StorageIdleRecords, algorithms.StorageIdleRate(clouds, appsToDeploy, currentSolution.SchedulingResult))
+			MCASGARecorder.GPUIdleRecords = append(MCASGARecorder.GPUIdleRecords, algorithms.GPUIdleRate(clouds, appsToDeploy, currentSolution.SchedulingResult))
 			MCASGARecorder.BwIdleRecords = append(MCASGARecorder.BwIdleRecords, algorithms.BwIdleRate(clouds, appsToDeploy, currentSolution.SchedulingResult))
 
 			MCASGARecorder.AcceptedPriorityRateRecords = append(MCASGARecorder.AcceptedPriorityRateRecords, float64(algorithms.AcceptedPriority(clouds, totalApps, totalSolution.SchedulingResult))/float64(algorithms.TotalPriority(clouds, totalApps, totalSolution.SchedulingResult)))
@@ -1206,6 +1290,16 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			}
 			averageRecorder.StorageIdleRecords = append(averageRecorder.StorageIdleRecords, sum/float64(num))
 		}
+		//GPUIdleRecords
+		for i := 0; i < len(recorders[0].GPUIdleRecords); i++ {
+			var sum float64 = 0.0
+			var num int = 0
+			for j := 0; j < len(recorders); j++ {
+				sum += recorders[j].GPUIdleRecords[i]
+				num++
+			}
+			averageRecorder.GPUIdleRecords = append(averageRecorder.GPUIdleRecords, sum/float64(num))
+		}
 		//BwIdleRecords
 		for i := 0; i < len(recorders[0].BwIdleRecords); i++ {
 			var sum float64 = 0.0
@@ -1246,6 +1340,26 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 			}
 			averageRecorder.AcceptedTaskPriRateRecords = append(averageRecorder.AcceptedTaskPriRateRecords, sum/float64(num))
 		}
+		//EnergyRecords
+		for i := 0; i < len(recorders[0].EnergyRecords); i++ {
+			var sum float64 = 0.0
+			var num int = 0
+			for j := 0; j < len(recorders); j++ {
+				sum += recorders[j].EnergyRecords[i]
+				num++
+			}
+			averageRecorder.EnergyRecords = append(averageRecorder.EnergyRecords, sum/float64(num))
+		}
+		//CarbonRecords
+		for i := 0; i < len(recorders[0].CarbonRecords); i++ {
+			var sum float64 = 0.0
+			var num int = 0
+			for j := 0; j < len(recorders); j++ {
+				sum += recorders[j].CarbonRecords[i]
+				num++
+			}
+			averageRecorder.CarbonRecords = append(averageRecorder.CarbonRecords, sum/float64(num))
+		}
 
 		//AllAppComplTime
 		for i := 0; i < len(recorders[0].AllAppComplTime); i++ {
@@ -1300,13 +1414,13 @@ func ContinuousExperiment(clouds []model.Cloud, apps [][]model.Application, appA
 	// output csv files
 	generateCsvFunc := func(recorder ContinuousHelper) [][]string {
 		var csvContent [][]string
-		csvContent = append(csvContent, []string{"Number of Applications", "Number of New Applications", "Time", "CPUClock Idle Rate", "Memory Idle Rate", "Storage Idle Rate", "Bandwidth Idle Rate", "Application Acceptance Rate", "Service Acceptance Rate", "Task Acceptance Rate", "Completion Time", "Completion Time Per Priority"})
+		csvContent = append(csvContent, []string{"Number of Applications", "Number of New Applications", "Time", "CPUClock Idle Rate", "Memory Idle Rate", "Storage Idle Rate", "GPU Idle Rate", "Bandwidth Idle Rate", "Application Acceptance Rate", "Service Acceptance Rate", "Task Acceptance Rate", "Completion Time", "Completion Time Per Priority", "Energy (J)", "Carbon Emissions (g)"})
 		appNum := 0
 		currentTime = 0 * time.Second
 		for i := 0; i < len(apps); i++ {
 			appNum += len(apps[i])
 			currentTime += appArrivalTimeIntervals[i]
-			csvContent = append(csvContent, []string{fmt.Sprintf("%d", appNum), fmt.Sprintf("%d", len(apps[i])), fmt.Sprintf("%.0f", float64(currentTime)/float64(time.Second)), fmt.Sprintf("%f", recorder.CPUIdleRecords[i]), fmt.Sprintf("%f", recorder.MemoryIdleRecords[i]), fmt.Sprintf("%f", recorder.StorageIdleRecords[i]), fmt.Sprintf("%f", recorder.BwIdleRecords[i]), fmt.Sprintf("%f", recorder.AcceptedPriorityRateRecords[i]), fmt.Sprintf("%f", recorder.AcceptedSvcPriRateRecords[i]), fmt.Sprintf("%f", recorder.AcceptedTaskPriRateRecords[i]), fmt.Sprintf("%f", recorder.AllAppComplTime[i]), fmt.Sprintf("%f", recorder.AllAppComplTimePerPri[i])})
+			csvContent = append(csvContent, []string{fmt.Sprintf("%d", appNum), fmt.Sprintf("%d", len(apps[i])), fmt.Sprintf("%.0f", float64(currentTime)/float64(time.Second)), fmt.Sprintf("%f", recorder.CPUIdleRecords[i]), fmt.Sprintf("%f", recorder.MemoryIdleRecords[i]), fmt.Sprintf("%f", recorder.StorageIdleRecords[i]), fmt.Sprintf("%f", recorder.GPUIdleRecords[i]), fmt.Sprintf("%f", recorder.BwIdleRecords[i]), fmt.Sprintf("%f", recorder.AcceptedPriorityRateRecords[i]), fmt.Sprintf("%f", recorder.AcceptedSvcPriRateRecords[i]), fmt.Sprintf("%f", recorder.AcceptedTaskPriRateRecords[i]), fmt.Sprintf("%f", recorder.AllAppComplTime[i]), fmt.Sprintf("%f", recorder.AllAppComplTimePerPri[i]), fmt.Sprintf("%f", recorder.EnergyRecords[i]), fmt.Sprintf("%f", recorder.CarbonRecords[i])})
 		}
 		return csvContent
 	}
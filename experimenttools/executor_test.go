@@ -40,7 +40,10 @@ func TestNoChangeMCASGA(t *testing.T) {
 	oriClouds := model.CloudsCopy(clouds)
 	oriApps := model.AppsCopy(apps)
 
-	geneticAlgorithm := algorithms.NewGenetic(200, 5000, 0.4, 0.003, 250, algorithms.RandomFitSchedule, algorithms.OnePointCrossOver, true, false, clouds, apps)
+	geneticAlgorithm, err := algorithms.NewGenetic(200, 5000, 0.4, 0.003, 250, algorithms.RandomFitSchedule, algorithms.OnePointCrossOver, algorithms.PriorityTimeFitness, true, false, clouds, apps)
+	if err != nil {
+		log.Panicf("algorithms.NewGenetic, error: %s", err.Error())
+	}
 
 	solution, err := geneticAlgorithm.Schedule(clouds, apps)
 	if err != nil {
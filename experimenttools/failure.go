@@ -0,0 +1,273 @@
+package experimenttools
+
+import (
+	"encoding/csv"
+	"fmt"
+	"go/build"
+	"gogeneticwrsp/algorithms"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"gogeneticwrsp/model"
+)
+
+// FailureExperiment is like ContinuousExperiment, but the clouds fail as scripted in failures, to compare how resilient the algorithms are.
+// In every round, every algorithm reschedules the new apps together with the remaining apps, like MCASGA in ContinuousExperiment, on the clouds with the active failures.
+// The apps affected by the failures since last round are restarted, and the restarted apps that are rejected are lost.
+// Failures take effect at the arrivals of app groups, so the time between a failure and the next arrival is counted as suspension of the restarted services.
+func FailureExperiment(clouds []model.Cloud, apps [][]model.Application, appArrivalTimeIntervals []time.Duration, failures []model.CloudFailure, repeatCount int) {
+	for i, failure := range failures {
+		if err := failure.Validate(len(clouds)); err != nil {
+			log.Panicf("failures[%d] error: %s", i, err.Error())
+		}
+	}
+	SetOriIdx(apps)
+	SetGeneratedTime(apps, appArrivalTimeIntervals)
+
+	var schedulers []func(clouds []model.Cloud, apps []model.Application) (model.Solution, error) = []func(clouds []model.Cloud, apps []model.Application) (model.Solution, error){
+		func(clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
+			return algorithms.NewFirstFit(clouds, apps).Schedule(clouds, apps)
+		},
+		func(clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
+			// HAGA does not know remaining apps, it schedules them like new ones
+			haga, err := algorithms.NewScheduler(algorithms.SchedulerConfig{Algorithm: "haga"}, clouds, apps)
+			if err != nil {
+				return model.Solution{}, err
+			}
+			return haga.Schedule(clouds, apps)
+		},
+		func(clouds []model.Cloud, apps []model.Application) (model.Solution, error) {
+			ga, err := algorithms.NewScheduler(algorithms.SchedulerConfig{Algorithm: "mcasga"}, clouds, apps)
+			if err != nil {
+				return model.Solution{}, err
+			}
+			return ga.Schedule(clouds, apps)
+		},
+	}
+	var newRecorders []func() ContinuousHelper = []func() ContinuousHelper{NewFirstFitRecorder, NewHAGARecorder, NewMCASGARecorder}
+
+	var recorders [][]ContinuousHelper = make([][]ContinuousHelper, len(schedulers)) // recorders[i][j] is the recorder of algorithm i in repeat j
+	for i := 0; i < len(schedulers); i++ {
+		recorders[i] = make([]ContinuousHelper, repeatCount)
+		for curRepeatCount := 0; curRepeatCount < repeatCount; curRepeatCount++ {
+			recorders[i][curRepeatCount] = newRecorders[i]()
+		}
+	}
+
+	for curRepeatCount := 0; curRepeatCount < repeatCount; curRepeatCount++ {
+		log.Println("repeat: ", curRepeatCount)
+		var oneRepeat []*ContinuousHelper
+		for i := 0; i < len(schedulers); i++ {
+			rescheduleWithFailures(&recorders[i][curRepeatCount], schedulers[i], clouds, apps, appArrivalTimeIntervals, failures)
+			oneRepeat = append(oneRepeat, &recorders[i][curRepeatCount])
+		}
+		// after the service suspension time and task completion time in all recorders are set, we handle the rejected apps
+		setRejectedSvcTask(oneRepeat...)
+	}
+
+	// calculate average value and output csv files
+	var averageRecorders []ContinuousHelper = make([]ContinuousHelper, len(schedulers))
+	for i := 0; i < len(schedulers); i++ {
+		averageRecorders[i] = averageFailureRecorders(newRecorders[i](), recorders[i])
+	}
+
+	for _, recorder := range averageRecorders {
+		var csvContent [][]string
+		csvContent = append(csvContent, []string{"Number of Applications", "Number of New Applications", "Time", "CPUClock Idle Rate", "Memory Idle Rate", "Storage Idle Rate", "Bandwidth Idle Rate", "Application Acceptance Rate", "Service Acceptance Rate", "Task Acceptance Rate", "Completion Time", "Restarted Applications", "Lost Applications"})
+		var appNum int = 0
+		var currentTime time.Duration = 0 * time.Second
+		for i := 0; i < len(apps); i++ {
+			appNum += len(apps[i])
+			currentTime += appArrivalTimeIntervals[i]
+			csvContent = append(csvContent, []string{fmt.Sprintf("%d", appNum), fmt.Sprintf("%d", len(apps[i])), fmt.Sprintf("%.0f", float64(currentTime)/float64(time.Second)), fmt.Sprintf("%f", recorder.CPUIdleRecords[i]), fmt.Sprintf("%f", recorder.MemoryIdleRecords[i]), fmt.Sprintf("%f", recorder.StorageIdleRecords[i]), fmt.Sprintf("%f", recorder.BwIdleRecords[i]), fmt.Sprintf("%f", recorder.AcceptedPriorityRateRecords[i]), fmt.Sprintf("%f", recorder.AcceptedSvcPriRateRecords[i]), fmt.Sprintf("%f", recorder.AcceptedTaskPriRateRecords[i]), fmt.Sprintf("%f", recorder.AllAppComplTime[i]), fmt.Sprintf("%f", recorder.EvictedAppRecords[i]), fmt.Sprintf("%f", recorder.LostAppRecords[i])})
+		}
+		writeFailureCsv(recorder.Name+" failure", csvContent)
+	}
+
+	// write cdf csv file of service suspension time and task completion time
+	var svcCsvContent, taskCsvContent [][]string = [][]string{{}}, [][]string{{}}
+	for _, recorder := range averageRecorders {
+		svcCsvContent[0] = append(svcCsvContent[0], recorder.Name+" Weighted Service Suspension Time")
+		taskCsvContent[0] = append(taskCsvContent[0], recorder.Name+" Weighted Task Completion Time")
+	}
+	for i := 0; i < len(averageRecorders[0].SvcSusTime); i++ {
+		var line []string
+		for _, recorder := range averageRecorders {
+			line = append(line, fmt.Sprintf("%g", recorder.SvcSusTime[i]))
+		}
+		svcCsvContent = append(svcCsvContent, line)
+	}
+	for i := 0; i < len(averageRecorders[0].TaskComplTime); i++ {
+		var line []string
+		for _, recorder := range averageRecorders {
+			line = append(line, fmt.Sprintf("%g", recorder.TaskComplTime[i]))
+		}
+		taskCsvContent = append(taskCsvContent, line)
+	}
+	writeFailureCsv("svc_cdf_failure", svcCsvContent)
+	writeFailureCsv("task_cdf_failure", taskCsvContent)
+}
+
+// rescheduleWithFailures runs one algorithm through all app groups, in every round, the new apps and the remaining apps are scheduled together on the clouds with the failures active at that time
+func rescheduleWithFailures(recorder *ContinuousHelper, schedule func([]model.Cloud, []model.Application) (model.Solution, error), clouds []model.Cloud, apps [][]model.Application, appArrivalTimeIntervals []time.Duration, failures []model.CloudFailure) {
+	var totalApps []model.Application
+	var totalSolution model.Solution
+
+	var lastClouds []model.Cloud     // clouds in last round
+	var lastApps []model.Application // apps in last round
+	var lastSolution model.Solution  // solution of last round
+
+	var lastTime, currentTime float64 // unit second
+	for i := 0; i < len(apps); i++ {
+		lastTime = currentTime
+		currentTime += float64(appArrivalTimeIntervals[i]) / float64(time.Second)
+		log.Println(recorder.Name, "group", i, "currentTime", currentTime)
+
+		var failedClouds []model.Cloud = model.ApplyFailures(clouds, failures, currentTime)
+
+		// the ith applications group request comes
+		var appsToDeploy []model.Application = model.CombApps(nil, apps[i])
+		var downTimes map[int]float64
+		if len(lastApps) != 0 { // not the first group
+			tmpApps := model.AppsCopy(lastApps)
+			tmpSolution := model.SolutionCopy(lastSolution)
+			timeClouds := algorithms.SimulateDeploy(model.CloudsCopy(lastClouds), tmpApps, tmpSolution)
+			algorithms.CalcStartComplTime(timeClouds, tmpApps, tmpSolution.SchedulingResult)
+
+			remainingApps, err := algorithms.CalcRemainingApps(lastClouds, timeClouds, currentTime-lastTime)
+			if err != nil {
+				log.Panicf("algorithms.CalcRemainingApps, app %d, error: %s", i, err.Error())
+			}
+			downTimes = algorithms.EvictFailedApps(remainingApps, failures, lastTime, currentTime)
+
+			// the indexes of remaining apps are shifted by the new apps
+			var shiftedDownTimes map[int]float64 = make(map[int]float64, len(downTimes))
+			for appIdx, downTime := range downTimes {
+				shiftedDownTimes[appIdx+len(appsToDeploy)] = downTime
+			}
+			downTimes = shiftedDownTimes
+			appsToDeploy = model.CombApps(appsToDeploy, remainingApps) // this group of apps + remaining apps
+		}
+
+		totalApps = model.CombApps(totalApps, apps[i])
+
+		solution, err := schedule(failedClouds, appsToDeploy)
+		if err != nil {
+			log.Printf("Error, app %d. Error message: %s", i, err.Error())
+		}
+		if len(solution.SchedulingResult) != len(appsToDeploy) { // no solution, all apps are rejected
+			solution.SchedulingResult = make([]int, len(appsToDeploy))
+			for j := 0; j < len(appsToDeploy); j++ {
+				solution.SchedulingResult[j] = len(clouds)
+			}
+		}
+		totalSolution.SchedulingResult = append(totalSolution.SchedulingResult, solution.SchedulingResult[:len(apps[i])]...)
+
+		// the remaining apps rejected in this round are lost
+		var lost int
+		for j := len(apps[i]); j < len(appsToDeploy); j++ {
+			if solution.SchedulingResult[j] == len(clouds) {
+				totalSolution.SchedulingResult[appsToDeploy[j].OriIdx] = len(clouds)
+				lost++
+			}
+		}
+
+		// get apps with all time related attributes
+		tmpClouds4Time := algorithms.SimulateDeploy(model.CloudsCopy(failedClouds), model.AppsCopy(appsToDeploy), model.SolutionCopy(solution))
+		timeApps := algorithms.CalcStartComplTime(tmpClouds4Time, model.AppsCopy(appsToDeploy), solution.SchedulingResult)
+		for j := 0; j < len(timeApps); j++ {
+			if solution.SchedulingResult[j] == len(clouds) { // only record the time of accepted apps
+				continue
+			}
+			if timeApps[j].IsTask { // set final completion time for tasks
+				totalApps[timeApps[j].OriIdx].TaskFinalComplTime = timeApps[j].TaskCompletionTime + currentTime
+			} else { // set suspension time for services, the restarted services were also suspended since they failed
+				totalApps[timeApps[j].OriIdx].SvcSuspensionTime += timeApps[j].StableTime + downTimes[j]
+			}
+		}
+
+		// record TotalTaskComplTime of clouds
+		var longestTime float64 = 0
+		for j := 0; j < len(tmpClouds4Time); j++ {
+			if thisTime := tmpClouds4Time[j].TotalTaskComplTime + currentTime; thisTime > longestTime {
+				longestTime = thisTime
+			}
+		}
+		recorder.AllAppComplTime = append(recorder.AllAppComplTime, longestTime)
+
+		// evaluate current solution, current cloud, current apps
+		recorder.CPUIdleRecords = append(recorder.CPUIdleRecords, algorithms.CPUIdleRate(failedClouds, appsToDeploy, solution.SchedulingResult))
+		recorder.MemoryIdleRecords = append(recorder.MemoryIdleRecords, algorithms.MemoryIdleRate(failedClouds, appsToDeploy, solution.SchedulingResult))
+		recorder.StorageIdleRecords = append(recorder.StorageIdleRecords, algorithms.StorageIdleRate(failedClouds, appsToDeploy, solution.SchedulingResult))
+		recorder.BwIdleRecords = append(recorder.BwIdleRecords, algorithms.BwIdleRate(failedClouds, appsToDeploy, solution.SchedulingResult))
+
+		recorder.AcceptedPriorityRateRecords = append(recorder.AcceptedPriorityRateRecords, float64(algorithms.AcceptedPriority(clouds, totalApps, totalSolution.SchedulingResult))/float64(algorithms.TotalPriority(clouds, totalApps, totalSolution.SchedulingResult)))
+		recorder.AcceptedSvcPriRateRecords = append(recorder.AcceptedSvcPriRateRecords, algorithms.AcceptedSvcPriRate(clouds, totalApps, totalSolution.SchedulingResult))
+		recorder.AcceptedTaskPriRateRecords = append(recorder.AcceptedTaskPriRateRecords, algorithms.AcceptedTaskPriRate(clouds, totalApps, totalSolution.SchedulingResult))
+
+		recorder.EvictedAppRecords = append(recorder.EvictedAppRecords, float64(len(downTimes)))
+		recorder.LostAppRecords = append(recorder.LostAppRecords, float64(lost))
+
+		lastClouds = failedClouds
+		lastApps = model.AppsCopy(appsToDeploy)
+		lastSolution = model.SolutionCopy(solution)
+	}
+	// record the service suspension time and task completion time
+	recorder.setSvcSusTaskComplTime(clouds, totalApps, totalSolution)
+}
+
+// averageFailureRecorders sets the records in averageRecorder as the averages of the records in recorders, which are repeats of the same algorithm
+func averageFailureRecorders(averageRecorder ContinuousHelper, recorders []ContinuousHelper) ContinuousHelper {
+	var average func(records func(ContinuousHelper) []float64) []float64 = func(records func(ContinuousHelper) []float64) []float64 {
+		var averageRecords []float64
+		for i := 0; i < len(records(recorders[0])); i++ {
+			var sum float64 = 0.0
+			for j := 0; j < len(recorders); j++ {
+				sum += records(recorders[j])[i]
+			}
+			averageRecords = append(averageRecords, sum/float64(len(recorders)))
+		}
+		return averageRecords
+	}
+	averageRecorder.CPUIdleRecords = average(func(ch ContinuousHelper) []float64 { return ch.CPUIdleRecords })
+	averageRecorder.MemoryIdleRecords = average(func(ch ContinuousHelper) []float64 { return ch.MemoryIdleRecords })
+	averageRecorder.StorageIdleRecords = average(func(ch ContinuousHelper) []float64 { return ch.StorageIdleRecords })
+	averageRecorder.BwIdleRecords = average(func(ch ContinuousHelper) []float64 { return ch.BwIdleRecords })
+	averageRecorder.AcceptedPriorityRateRecords = average(func(ch ContinuousHelper) []float64 { return ch.AcceptedPriorityRateRecords })
+	averageRecorder.AcceptedSvcPriRateRecords = average(func(ch ContinuousHelper) []float64 { return ch.AcceptedSvcPriRateRecords })
+	averageRecorder.AcceptedTaskPriRateRecords = average(func(ch ContinuousHelper) []float64 { return ch.AcceptedTaskPriRateRecords })
+	averageRecorder.AllAppComplTime = average(func(ch ContinuousHelper) []float64 { return ch.AllAppComplTime })
+	averageRecorder.SvcSusTime = average(func(ch ContinuousHelper) []float64 { return ch.SvcSusTime })
+	averageRecorder.TaskComplTime = average(func(ch ContinuousHelper) []float64 { return ch.TaskComplTime })
+	averageRecorder.EvictedAppRecords = average(func(ch ContinuousHelper) []float64 { return ch.EvictedAppRecords })
+	averageRecorder.LostAppRecords = average(func(ch ContinuousHelper) []float64 { return ch.LostAppRecords })
+	return averageRecorder
+}
+
+// writeFailureCsv writes a csv file of FailureExperiment into the directory of experimenttools, as ContinuousExperiment does
+func writeFailureCsv(name string, csvContent [][]string) {
+	name = strings.Replace(name, " ", "_", -1)
+	var csvpath string
+	if runtime.GOOS == "windows" {
+		csvpath = fmt.Sprintf("%s\\src\\gogeneticwrsp\\experimenttools\\%s.csv", build.Default.GOPATH, name)
+	} else {
+		csvpath = fmt.Sprintf("%s/src/gogeneticwrsp/experimenttools/%s.csv", build.Default.GOPATH, name)
+	}
+
+	f, err := os.Create(csvpath)
+	if err != nil {
+		log.Fatalln("Fatal: ", err)
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	for _, record := range csvContent {
+		if err := w.Write(record); err != nil {
+			log.Fatalf("write record %v, error: %s", record, err.Error())
+		}
+	}
+}
@@ -19,6 +19,18 @@ func chooseResCPU() model.CPUResource {
 	return cpuRes
 }
 
+// generate the power model of a cloud with logicalCores logical cores, with a constant carbon intensity
+func generatePower(logicalCores float64) model.CloudPower {
+	idlePerCore := random.RandomFloat64(5, 10)  // unit watt, from the idle power of common servers
+	peakPerCore := random.RandomFloat64(15, 30) // unit watt, from the peak power of common servers
+	return model.CloudPower{
+		IdleWatts:       idlePerCore * logicalCores,
+		PeakWatts:       peakPerCore * logicalCores,
+		PUE:             random.RandomFloat64(1.1, 1.6),
+		CarbonIntensity: []model.CarbonIntensityPoint{{Time: 0, GramsPerKWh: random.RandomFloat64(50, 700)}}, // from low-carbon to coal-heavy grids
+	}
+}
+
 // generate CPU cycles needed by a task
 func generateTaskCPU() float64 {
 	var CPUCycle float64
@@ -100,6 +112,22 @@ func chooseReqStor() float64 {
 	return ReqStorToChoose[random.RandomInt(0, len(ReqStorToChoose)-1)] * 1024 * 1024 * 1024
 }
 
+// randomly choose the GPUs of a cloud, GPU memory unit B
+func chooseResGPU() model.AcceleratorResource {
+	count := resGPUToChoose[random.RandomInt(0, len(resGPUToChoose)-1)]
+	memory := resGPUMemToChoose[random.RandomInt(0, len(resGPUMemToChoose)-1)] * 1024 * 1024 * 1024
+	return model.AcceleratorResource{Count: count, Memory: count * memory}
+}
+
+// randomly choose the GPUs required by an app, GPU memory unit B
+func chooseReqGPU() model.AcceleratorResource {
+	count := reqGPUToChoose[random.RandomInt(0, len(reqGPUToChoose)-1)]
+	if count == 0 {
+		return model.AcceleratorResource{}
+	}
+	return model.AcceleratorResource{Count: count, Memory: reqGPUMemToChoose[random.RandomInt(0, len(reqGPUMemToChoose)-1)] * 1024 * 1024 * 1024}
+}
+
 // memory and storage Byte, forRequest
 func generateResourceMemoryStorageRequest(lowerBound, upperBound, miu, sigma float64) float64 {
 	return random.NormalRandomBM(lowerBound, upperBound, miu, sigma)
@@ -6,6 +6,7 @@ require (
 	github.com/KeepTheBeats/routing-algorithms v0.0.0-20221123203558-62929d0c2ea0
 	github.com/stretchr/testify v1.8.0
 	github.com/wcharczuk/go-chart v2.0.1+incompatible
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -15,5 +16,4 @@ require (
 	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/image v0.0.0-20220902085622-e7cb96979f69 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
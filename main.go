@@ -1,159 +1,186 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"gogeneticwrsp/algorithms"
+	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"gogeneticwrsp/experimenttools"
-	"gogeneticwrsp/model"
+	"gopkg.in/yaml.v3"
+
+	"gogeneticwrsp/algorithms"
+	"gogeneticwrsp/service"
+)
+
+// exit codes of the scheduler tool
+const (
+	exitOK           int = 0
+	exitError        int = 1 // e.g., a file cannot be read or written
+	exitInvalidInput int = 2 // the clouds, apps, or scheduler config are invalid, the same code as an invalid flag
+	exitNoSolution   int = 3 // the scheduler found no acceptable solution
 )
 
 func main() {
-	// set the log to show line number and file name
-	log.SetFlags(0 | log.Lshortfile)
-
-	//log.Println("Hello World!")
-
-	//var numCloud, numApp int = 10, 140
-	var numCloud, numApp int = 5, 40
-	var appSuffix string = "0"
-
-	// generate clouds and apps, and write to files
-	//experimenttools.GenerateCloudsApps(numCloud, numApp, appSuffix)
-	experimenttools.GenerateClouds(numCloud)
-	experimenttools.GenerateApps(numApp, appSuffix, 0.5)
-
-	// read clouds and apps from files
-	var clouds []model.Cloud
-	var apps []model.Application
-	//clouds, apps = experimenttools.ReadCloudsApps(numCloud, numApp, appSuffix)
-	clouds = experimenttools.ReadClouds(numCloud)
-	apps = experimenttools.ReadApps(numApp, appSuffix)
-	//for i := 0; i < numCloud; i++ {
-	//	log.Println(clouds[i])
-	//}
-	//
-	//for i := 0; i < numApp; i++ {
-	//	log.Println(apps[i])
-	//}
-
-	var CPUCapa, CPUReq, MemCapa, MemReq, StoCapa, StoReq, BWCapa, BWReq float64
-	for i := 0; i < numCloud; i++ {
-		CPUCapa += clouds[i].Capacity.CPU.BaseClock * clouds[i].Capacity.CPU.LogicalCores
-		MemCapa += clouds[i].Capacity.Memory
-		StoCapa += clouds[i].Capacity.Storage
-		for j := 0; j < numCloud; j++ {
-			if j != i {
-				BWCapa += clouds[i].Capacity.NetCondClouds[j].DownBw
+	var cloudsPath, appsPath, algorithm, params, configPath, format, outPath string
+	var serveAddr string
+	var concurrency int
+	var timeout time.Duration
+	flag.StringVar(&cloudsPath, "clouds", "", "JSON file of the clouds, required without -serve; with -serve, the clouds of the stateful scheduler at /cluster")
+	flag.StringVar(&appsPath, "apps", "", "JSON file of the applications, required without -serve")
+	flag.StringVar(&algorithm, "algorithm", "mcasga", fmt.Sprintf("name of the scheduler, one of %v", algorithms.SchedulerNames()))
+	flag.StringVar(&params, "params", "", `JSON or YAML parameters of the scheduler, e.g., {"iterationCount": 1000, "seed": 1}, the others keep their defaults`)
+	flag.StringVar(&configPath, "config", "", `JSON or YAML file of a scheduler config, e.g., {"algorithm": "mcasga", "params": {...}}, it replaces -algorithm and -params`)
+	flag.StringVar(&format, "format", service.FormatJSON, "output format, json or csv")
+	flag.StringVar(&outPath, "out", "", "output file, stdout if it is empty")
+	flag.DurationVar(&timeout, "timeout", 0, "stop searching after this duration and output the best solution found until then, 0 means no limit")
+	flag.StringVar(&serveAddr, "serve", "", "serve the schedulers over HTTP on this address, e.g., :8080, instead of scheduling the files; -timeout bounds each request")
+	flag.IntVar(&concurrency, "concurrency", service.DefaultServerConfig().MaxConcurrent, "with -serve, the largest number of requests handled at the same time")
+	flag.Parse()
+
+	if serveAddr != "" {
+		var config service.ServerConfig = service.DefaultServerConfig()
+		config.MaxConcurrent = concurrency
+		if timeout > 0 {
+			config.Timeout = timeout
+		}
+		server := service.NewServer(config)
+		// with -clouds, the server also keeps the state of these clouds for online scheduling
+		if cloudsPath != "" {
+			defaultConfig, err := schedulerConfig(algorithm, params, configPath)
+			if err != nil {
+				exit(exitCode(err), "scheduler config error: %s", err.Error())
+			}
+			clouds, err := service.ReadClouds(cloudsPath)
+			if err != nil {
+				exit(exitCode(err), "read clouds error: %s", err.Error())
 			}
+			cluster, err := service.NewCluster(clouds, defaultConfig)
+			if err != nil {
+				exit(exitCode(err), "cluster error: %s", err.Error())
+			}
+			server.HandleCluster(cluster)
+		}
+		if err := serve(serveAddr, server, config.Timeout); err != nil {
+			exit(exitError, "serve error: %s", err.Error())
 		}
+		return
 	}
 
-	for i := 0; i < numApp; i++ {
-		if !apps[i].IsTask {
-			CPUReq += apps[i].SvcReq.CPUClock
-			MemReq += apps[i].SvcReq.Memory
-			StoReq += apps[i].SvcReq.Storage
-		} else {
-			MemReq += apps[i].TaskReq.Memory
-			StoReq += apps[i].TaskReq.Storage
-		}
-		for j := 0; j < len(apps[i].Depend); j++ {
-			BWReq += (apps[i].Depend[j].DownBw + apps[i].Depend[j].UpBw)
-		}
+	if cloudsPath == "" || appsPath == "" {
+		flag.Usage()
+		os.Exit(exitInvalidInput)
 	}
-	fmt.Println("CPU:", CPUReq, CPUCapa, CPUReq/CPUCapa)
-	fmt.Println("Mem:", MemReq, MemCapa, MemReq/MemCapa)
-	fmt.Println("Sto:", StoReq, StoCapa, StoReq/StoCapa)
-	fmt.Println("BW:", BWReq, BWCapa, BWReq/BWCapa)
-
-	//geneticAlgorithm := algorithms.NewGenetic(200, 5000, 0.7, 0.01, 200, algorithms.InitializeUndeployedChromosome, clouds, apps)
-	//geneticAlgorithm := algorithms.NewGenetic(100, 5000, 0.7, 0.007, 200, algorithms.InitializeAcceptableChromosome, clouds, apps)
-	geneticAlgorithm := algorithms.NewGenetic(200, 5000, 0.4, 0.003, 250, algorithms.RandomFitSchedule, algorithms.OnePointCrossOver, true, false, clouds, apps)
-	haga := algorithms.NewHAGA(10, 0.6, 200, 5000, 0.6, 0.7, 250, clouds, apps)
-	nsga := algorithms.NewNSGAII(200, 5000, 1, 0.25, 250, clouds, apps)
-	ff := algorithms.NewFirstFit(clouds, apps)
-	rf := algorithms.NewRandomFit(clouds, apps)
-	geneticBefore := time.Now()
-	geneticSolution, err := geneticAlgorithm.Schedule(clouds, apps)
-	geneticAfter := time.Now()
-	if err != nil {
-		//log.Printf("geneticAlgorithm.Schedule(clouds, apps), error: %s", err.Error())
-		log.Panicf("geneticAlgorithm.Schedule(clouds, apps), error: %s", err.Error())
+	if format != service.FormatJSON && format != service.FormatCSV {
+		exit(exitInvalidInput, "unknown output format %q", format)
 	}
 
-	hagaBefore := time.Now()
-	hagaSolution, err := haga.Schedule(clouds, apps)
-	hagaAfter := time.Now()
+	config, err := schedulerConfig(algorithm, params, configPath)
 	if err != nil {
-		//log.Printf("haga.Schedule(clouds, apps), error: %s", err.Error())
-		log.Panicf("haga.Schedule(clouds, apps), error: %s", err.Error())
+		exit(exitCode(err), "scheduler config error: %s", err.Error())
 	}
-
-	nsgaBefore := time.Now()
-	nsgaSolution, err := nsga.Schedule(clouds, apps)
-	nsgaAfter := time.Now()
+	clouds, err := service.ReadClouds(cloudsPath)
 	if err != nil {
-		//log.Printf("nsga.Schedule(clouds, apps), error: %s", err.Error())
-		log.Panicf("nsga.Schedule(clouds, apps), error: %s", err.Error())
+		exit(exitCode(err), "read clouds error: %s", err.Error())
 	}
-
-	ffBefore := time.Now()
-	ffSolution, err := ff.Schedule(clouds, apps)
-	ffAfter := time.Now()
+	apps, err := service.ReadApps(appsPath)
 	if err != nil {
-		//log.Printf("ff.Schedule(clouds, apps), error: %s", err.Error())
-		log.Panicf("ff.Schedule(clouds, apps), error: %s", err.Error())
+		exit(exitCode(err), "read apps error: %s", err.Error())
 	}
 
-	rfBefore := time.Now()
-	rfSolution, err := rf.Schedule(clouds, apps)
-	rfAfter := time.Now()
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	result, err := service.Schedule(ctx, clouds, apps, config)
 	if err != nil {
-		//log.Printf("rf.Schedule(clouds, apps), error: %s", err.Error())
-		log.Panicf("rf.Schedule(clouds, apps), error: %s", err.Error())
+		exit(exitCode(err), "schedule error: %s", err.Error())
 	}
 
-	log.Println("geneticAlgorithm calculation time:", geneticAfter.Sub(geneticBefore).Seconds())
-	log.Println("haga calculation time:", hagaAfter.Sub(hagaBefore).Seconds())
-	log.Println("nsga calculation time:", nsgaAfter.Sub(nsgaBefore).Seconds())
-	log.Println("ff calculation time:", ffAfter.Sub(ffBefore).Seconds())
-	log.Println("rf calculation time:", rfAfter.Sub(rfBefore).Seconds())
-
-	tmpClouds := model.CloudsCopy(clouds)
-	tmpApps := model.AppsCopy(apps)
-	tmpSolution := model.SolutionCopy(geneticSolution)
-
-	tmpClouds = algorithms.SimulateDeploy(tmpClouds, tmpApps, tmpSolution)
-	algorithms.CalcStartComplTime(tmpClouds, tmpApps, tmpSolution.SchedulingResult)
-	log.Println("final, geneticAlgorithm.RejectExecTime:", geneticAlgorithm.RejectExecTime)
-	for j := 0; j < len(tmpClouds); j++ {
-		log.Println(tmpClouds[j].TotalTaskComplTime, len(tmpClouds[j].RunningApps))
+	var out io.Writer = os.Stdout
+	if outPath != "" {
+		file, err := os.Create(outPath)
+		if err != nil {
+			exit(exitError, "create output file error: %s", err.Error())
+		}
+		defer file.Close()
+		out = file
 	}
+	if err := service.WriteResult(out, result, format); err != nil {
+		exit(exitError, "write result error: %s", err.Error())
+	}
+}
 
-	//for i := 0; i < len(geneticAlgorithm.FitnessRecordIterationBest); i++ {
-	//	log.Printf("Iteration %d: FitnessRecordIterationBest: %f\n", i, geneticAlgorithm.FitnessRecordIterationBest[i])
-	//	log.Printf("Iteration %d: FitnessRecordIterationBestAcceptable: %f\n", i, geneticAlgorithm.FitnessRecordIterationBestAcceptable[i])
-	//}
-
-	log.Println()
-	if len(geneticAlgorithm.FitnessRecordBestUntilNow) != len(geneticAlgorithm.BestUntilNowUpdateIterations) {
-		log.Panicf("len(geneticAlgorithm.FitnessRecordBestUntilNow): %d, len(geneticAlgorithm.BestUntilNowUpdateIterations): %d\n", len(geneticAlgorithm.FitnessRecordBestUntilNow), len(geneticAlgorithm.BestUntilNowUpdateIterations))
+// serve runs server on addr until SIGINT or SIGTERM, and then waits at most timeout more for the requests being handled
+func serve(addr string, server *service.Server, timeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{Addr: addr, Handler: server}
+	var serveErr chan error = make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+	log.Printf("serving the schedulers %v on %s", algorithms.SchedulerNames(), addr)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
 	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout+10*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
+}
 
-	for i := 0; i < len(geneticAlgorithm.FitnessRecordBestAcceptableUntilNow); i++ {
-		log.Printf("Iteration %d: FitnessRecordBestAcceptableUntilNow: %f\n", int(geneticAlgorithm.BestAcceptableUntilNowUpdateIterations[i]), geneticAlgorithm.FitnessRecordBestAcceptableUntilNow[i])
+// schedulerConfig returns the config in the file at configPath if it is not empty, otherwise the config of algorithm with params
+func schedulerConfig(algorithm, params, configPath string) (algorithms.SchedulerConfig, error) {
+	if configPath != "" {
+		data, err := ioutil.ReadFile(configPath)
+		if err != nil {
+			return algorithms.SchedulerConfig{}, err
+		}
+		configs, err := algorithms.ParseSchedulerConfigs(data)
+		if err != nil {
+			return algorithms.SchedulerConfig{}, err
+		}
+		if len(configs) != 1 {
+			return algorithms.SchedulerConfig{}, fmt.Errorf("%w: %s has %d scheduler configs, it should have 1", algorithms.ErrInvalidParams, configPath, len(configs))
+		}
+		return configs[0], nil
 	}
 
-	log.Println("geneticSolution:", geneticSolution)
-	log.Println("hagaSolution:", hagaSolution)
-	log.Println("nsgaSolution:", nsgaSolution)
-	log.Println("ffSolution:", ffSolution)
-	log.Println("rfSolution:", rfSolution)
+	var config algorithms.SchedulerConfig = algorithms.SchedulerConfig{Algorithm: algorithm}
+	if params != "" {
+		if err := yaml.Unmarshal([]byte(params), &config.Params); err != nil {
+			return algorithms.SchedulerConfig{}, fmt.Errorf("%w: %s", algorithms.ErrInvalidParams, err.Error())
+		}
+	}
+	return config, nil
+}
 
-	// draw geneticAlgorithm.FitnessRecordIterationBest and geneticAlgorithm.FitnessRecordBestUntilNow on a line chart
-	geneticAlgorithm.DrawChart()
+// exitCode classifies err into the exit codes
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, algorithms.ErrNoAcceptableSolution):
+		return exitNoSolution
+	case errors.Is(err, service.ErrInvalidInput), errors.Is(err, algorithms.ErrDirtyTimeState), errors.Is(err, algorithms.ErrUnknownScheduler), errors.Is(err, algorithms.ErrInvalidParams):
+		return exitInvalidInput
+	}
+	return exitError
+}
 
+// exit prints the message to stderr and exits with code
+func exit(code int, format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(code)
 }
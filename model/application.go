@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -34,6 +35,25 @@ type Application struct {
 
 	Depend []Dependence `json:"depend"` // the dependence information of this application
 
+	// SLA, time duration from "the moment that all apps start to be deployed", the same as StableTime and TaskCompletionTime, unit second, 0 means no limit
+	Deadline       float64      `json:"deadline"`       // only task has this, the latest TaskCompletionTime
+	MaxStartupTime float64      `json:"maxStartupTime"` // only service has this, the latest StableTime
+	DeadlineMode   DeadlineMode `json:"deadlineMode"`   // how Deadline or MaxStartupTime is enforced
+	Budget         float64      `json:"budget"`         // the largest cost of a task, or the largest hourly cost of a service, with the prices of its cloud, 0 means no limit
+
+	// placement constraints, empty means no constraint
+	RequiredClouds    []string          `json:"requiredClouds"`    // names of the clouds that this application can only be deployed on
+	ForbiddenClouds   []string          `json:"forbiddenClouds"`   // names of the clouds that this application cannot be deployed on
+	CloudSelector     map[string]string `json:"cloudSelector"`     // this application can only be deployed on the clouds with all of these labels
+	AntiAffinityGroup string            `json:"antiAffinityGroup"` // applications in the same group, e.g., replicas of the same service, cannot be deployed on the same cloud
+	CoLocateWith      []int             `json:"coLocateWith"`      // indexes of the applications that this application must be deployed on the same cloud with
+
+	// replicas, only service has this, every replica is an application with its own gene, see ExpandReplicas
+	Replicas   int `json:"replicas"`   // number of replicas of this service, 0 or 1 means a single replica. The replicas are accepted or rejected together, and a dependence on any of them is served by the nearest one. The schedulers need the replicas expanded with ExpandReplicas first
+	MinSpread  int `json:"minSpread"`  // the replicas must be deployed on at least this number of different clouds, at most Replicas
+	ReplicaIdx int `json:"replicaIdx"` // set by ExpandReplicas, the index of this replica in the replicas of this service, the first replica is 0
+	ReplicaOf  int `json:"replicaOf"`  // set by ExpandReplicas, only effective when ReplicaIdx > 0, the index of the first replica of this service
+
 	// for remaining apps
 	IsNew bool `json:"isNew"` // whether this application is newly coming in this round. true: newly coming in this round; false: remaining from previous rounds
 	// this group of parameters are effective only when IsNew == false
@@ -41,21 +61,177 @@ type Application struct {
 	ImagePullDone    bool `json:"imagePullDone"`    // whether the image was pulled down in previous rounds
 	AlreadyStable    bool `json:"alreadyStable"`    // whether the app already finished startup in previous rounds
 	CanMigrate       bool `json:"canMigrate"`       // whether the app can be migrated or can only be suspended
+	// only task has this, the number of CPU cycles executed in previous rounds, TaskReq.CPUCycle is the number of CPU cycles not executed yet
+	ExecutedCPUCycle float64 `json:"executedCPUCycle"`
+}
+
+// DeadlineMode decides how the deadline of an application is enforced
+type DeadlineMode string
+
+const (
+	DeadlineSoft DeadlineMode = "soft" // missing the deadline is allowed, but the lateness is penalized in fitness, this is the default
+	DeadlineHard DeadlineMode = "hard" // a scheduling result missing the deadline is not acceptable
+)
+
+// Due returns the deadline of a task or the maximum tolerated startup time of a service, and false if the application has no limit
+func (app Application) Due() (float64, bool) {
+	if app.IsTask {
+		return app.Deadline, app.Deadline > 0
+	}
+	return app.MaxStartupTime, app.MaxStartupTime > 0
+}
+
+// HardDeadline returns whether the application has a due time that must be met
+func (app Application) HardDeadline() bool {
+	_, hasDue := app.Due()
+	return hasDue && app.DeadlineMode == DeadlineHard
+}
+
+// FinishTime returns the TaskCompletionTime of a task or the StableTime of a service
+func (app Application) FinishTime() float64 {
+	if app.IsTask {
+		return app.TaskCompletionTime
+	}
+	return app.StableTime
+}
+
+// Lateness returns how long the application finishes after its due time, 0 if it is on time or has no due time.
+// The time attributes of the application should have been calculated.
+func (app Application) Lateness() float64 {
+	due, hasDue := app.Due()
+	if !hasDue || app.FinishTime() <= due {
+		return 0
+	}
+	return app.FinishTime() - due
+}
+
+// ErrInvalidDependency is matched by errors.Is for every InvalidDependencyError
+var ErrInvalidDependency = errors.New("invalid dependency")
+
+// InvalidDependencyError means that apps[AppIdx] has a dependency on apps[DependAppIdx] that cannot be scheduled
+type InvalidDependencyError struct {
+	AppIdx       int    // index of the app that has the invalid dependency
+	DependAppIdx int    // index of the app that it depends on
+	Reason       string // why this dependency is invalid
+}
+
+func (e *InvalidDependencyError) Error() string {
+	return fmt.Sprintf("%s: app index %d depend on app index %d, %s", ErrInvalidDependency.Error(), e.AppIdx, e.DependAppIdx, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrInvalidDependency) true
+func (e *InvalidDependencyError) Is(target error) bool {
+	return target == ErrInvalidDependency
 }
 
-// DependencyValid Check whether Dependency is Valid
+// DependencyValid Check whether Dependency is Valid, the returned error is an *InvalidDependencyError
 func DependencyValid(apps []Application) error {
 	// If app A depends on app B, priority(A) must be smaller than priority(B)
 	for i := 0; i < len(apps); i++ {
 		for j := 0; j < len(apps[i].Depend); j++ {
-			if apps[i].Priority >= apps[apps[i].Depend[j].AppIdx].Priority {
-				return fmt.Errorf("app index %d with priority %d depend on app index %d with priority %d", i, apps[i].Priority, apps[i].Depend[j].AppIdx, apps[apps[i].Depend[j].AppIdx].Priority)
+			dependIdx := apps[i].Depend[j].AppIdx
+			if dependIdx < 0 || dependIdx >= len(apps) {
+				return &InvalidDependencyError{AppIdx: i, DependAppIdx: dependIdx, Reason: fmt.Sprintf("there are only %d apps", len(apps))}
+			}
+			if apps[i].Priority >= apps[dependIdx].Priority {
+				return &InvalidDependencyError{AppIdx: i, DependAppIdx: dependIdx, Reason: fmt.Sprintf("priority %d is not smaller than priority %d", apps[i].Priority, apps[dependIdx].Priority)}
+			}
+		}
+		// co-located apps do not need an order of priorities
+		for _, coLocateIdx := range apps[i].CoLocateWith {
+			if coLocateIdx < 0 || coLocateIdx >= len(apps) {
+				return &InvalidDependencyError{AppIdx: i, DependAppIdx: coLocateIdx, Reason: fmt.Sprintf("co-location: there are only %d apps", len(apps))}
 			}
 		}
 	}
 	return nil
 }
 
+// Replicated returns whether the application is a service with more than one replica
+func (app Application) Replicated() bool {
+	return !app.IsTask && app.Replicas > 1
+}
+
+// ExpandReplicas returns a copy of apps in which every Replicated service gets the other Replicas-1 replicas appended after all apps, so the indexes of the input apps do not change.
+// The replicas have the same requirements, dependencies and priority as the first one, and a dependence on the first one is served by any of them.
+// The services that are already expanded are not expanded again.
+func ExpandReplicas(apps []Application) []Application {
+	var expanded []Application = AppsCopy(apps)
+	var hasReplicas map[int]bool = make(map[int]bool)
+	for i := 0; i < len(apps); i++ {
+		if apps[i].Replicated() && apps[i].ReplicaIdx > 0 {
+			hasReplicas[apps[i].ReplicaOf] = true
+		}
+	}
+	for i := 0; i < len(apps); i++ {
+		if !apps[i].Replicated() || apps[i].ReplicaIdx > 0 || hasReplicas[i] {
+			continue
+		}
+		for r := 1; r < apps[i].Replicas; r++ {
+			var replica Application = AppCopy(apps[i])
+			replica.AppIdx = len(expanded)
+			replica.ReplicaIdx = r
+			replica.ReplicaOf = i
+			expanded = append(expanded, replica)
+		}
+	}
+	return expanded
+}
+
+// ReplicaSets groups the replicas of every service, ReplicaSets(apps)[i] is the indexes of all replicas of the service of apps[i] in ascending order, nil if apps[i] is not Replicated.
+// It returns nil if no application is Replicated.
+func ReplicaSets(apps []Application) [][]int {
+	var sets map[int][]int
+	for i := 0; i < len(apps); i++ {
+		if !apps[i].Replicated() {
+			continue
+		}
+		if sets == nil {
+			sets = make(map[int][]int)
+		}
+		var first int = i
+		if apps[i].ReplicaIdx > 0 {
+			first = apps[i].ReplicaOf
+		}
+		sets[first] = append(sets[first], i)
+	}
+	if sets == nil {
+		return nil
+	}
+	var replicaSets [][]int = make([][]int, len(apps))
+	for _, set := range sets {
+		for _, appIdx := range set {
+			replicaSets[appIdx] = set
+		}
+	}
+	return replicaSets
+}
+
+// MeetCloud returns whether the placement constraints about clouds allow this application to be deployed on cloud
+func (app Application) MeetCloud(cloud Cloud) bool {
+	if len(app.RequiredClouds) > 0 && !containsString(app.RequiredClouds, cloud.Name) {
+		return false
+	}
+	if containsString(app.ForbiddenClouds, cloud.Name) {
+		return false
+	}
+	for key, value := range app.CloudSelector {
+		if label, exist := cloud.Labels[key]; !exist || label != value {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(s []string, v string) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
 // CheckDepend check whether apps[i] depends on apps[j]
 func CheckDepend(apps []Application, i, j int) bool {
 	for k := 0; k < len(apps[i].Depend); k++ {
@@ -77,6 +253,12 @@ func CombApps(a, b []Application) []Application {
 		for j := 0; j < len(bCopy[i].Depend); j++ {
 			bCopy[i].Depend[j].AppIdx += diff
 		}
+		for j := 0; j < len(bCopy[i].CoLocateWith); j++ {
+			bCopy[i].CoLocateWith[j] += diff
+		}
+		if bCopy[i].ReplicaIdx > 0 {
+			bCopy[i].ReplicaOf += diff
+		}
 	}
 	aCopy = append(aCopy, bCopy...)
 	return aCopy
@@ -97,6 +279,12 @@ func AppCopy(src Application) Application {
 
 	dst.Depend = make([]Dependence, len(src.Depend))
 	copy(dst.Depend, src.Depend)
+	if src.CoLocateWith != nil {
+		dst.CoLocateWith = make([]int, len(src.CoLocateWith))
+		copy(dst.CoLocateWith, src.CoLocateWith)
+	}
+	dst.SvcReq.Scalars = src.SvcReq.Scalars.Copy()
+	dst.TaskReq.Scalars = src.TaskReq.Scalars.Copy()
 
 	return dst
 }
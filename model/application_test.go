@@ -266,3 +266,99 @@ func TestCombApps(t *testing.T) {
 
 	}
 }
+
+func TestLateness(t *testing.T) {
+	testCases := []struct {
+		name             string
+		app              Application
+		expectedDue      float64
+		expectedHasDue   bool
+		expectedHard     bool
+		expectedLateness float64
+	}{
+		{
+			name:             "case 1: task without deadline",
+			app:              Application{IsTask: true, StableTime: 5, TaskCompletionTime: 20},
+			expectedLateness: 0,
+		},
+		{
+			name:             "case 2: task missing soft deadline",
+			app:              Application{IsTask: true, StableTime: 5, TaskCompletionTime: 20, Deadline: 15, MaxStartupTime: 100},
+			expectedDue:      15,
+			expectedHasDue:   true,
+			expectedLateness: 5,
+		},
+		{
+			name:             "case 3: task meeting hard deadline",
+			app:              Application{IsTask: true, StableTime: 5, TaskCompletionTime: 20, Deadline: 20, DeadlineMode: DeadlineHard},
+			expectedDue:      20,
+			expectedHasDue:   true,
+			expectedHard:     true,
+			expectedLateness: 0,
+		},
+		{
+			name:             "case 4: service missing hard maximum startup time",
+			app:              Application{StableTime: 12, Deadline: 100, MaxStartupTime: 10, DeadlineMode: DeadlineHard},
+			expectedDue:      10,
+			expectedHasDue:   true,
+			expectedHard:     true,
+			expectedLateness: 2,
+		},
+		{
+			name:             "case 5: service with only a task deadline",
+			app:              Application{StableTime: 12, Deadline: 1, DeadlineMode: DeadlineHard},
+			expectedLateness: 0,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		due, hasDue := testCase.app.Due()
+		assert.Equal(t, testCase.expectedDue, due, fmt.Sprintf("%s: wrong due", testCase.name))
+		assert.Equal(t, testCase.expectedHasDue, hasDue, fmt.Sprintf("%s: wrong hasDue", testCase.name))
+		assert.Equal(t, testCase.expectedHard, testCase.app.HardDeadline(), fmt.Sprintf("%s: wrong hard", testCase.name))
+		assert.Equal(t, testCase.expectedLateness, testCase.app.Lateness(), fmt.Sprintf("%s: wrong lateness", testCase.name))
+	}
+}
+
+func TestMeetCloud(t *testing.T) {
+	var cloud Cloud = Cloud{Name: "edge-0", Labels: map[string]string{"region": "eu", "tier": "edge"}}
+	testCases := []struct {
+		name     string
+		app      Application
+		expected bool
+	}{
+		{name: "case 1: no constraint", app: Application{}, expected: true},
+		{name: "case 2: required", app: Application{RequiredClouds: []string{"core-0", "edge-0"}}, expected: true},
+		{name: "case 3: not required", app: Application{RequiredClouds: []string{"core-0"}}, expected: false},
+		{name: "case 4: forbidden", app: Application{ForbiddenClouds: []string{"edge-0"}}, expected: false},
+		{name: "case 5: not forbidden", app: Application{ForbiddenClouds: []string{"core-0"}}, expected: true},
+		{name: "case 6: selector matches", app: Application{CloudSelector: map[string]string{"region": "eu"}}, expected: true},
+		{name: "case 7: selector value differs", app: Application{CloudSelector: map[string]string{"region": "eu", "tier": "core"}}, expected: false},
+		{name: "case 8: selector key missing", app: Application{CloudSelector: map[string]string{"gpu": ""}}, expected: false},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		assert.Equal(t, testCase.expected, testCase.app.MeetCloud(cloud), fmt.Sprintf("%s: MeetCloud", testCase.name))
+	}
+}
+
+func TestExpandReplicas(t *testing.T) {
+	var apps []Application = []Application{
+		{AppIdx: 0, Priority: 3, Replicas: 3, MinSpread: 2},
+		{AppIdx: 1, Priority: 2, IsTask: true, Replicas: 3}, // tasks do not have replicas
+		{AppIdx: 2, Priority: 1, Replicas: 2, Depend: []Dependence{{AppIdx: 0}}},
+	}
+	expanded := ExpandReplicas(apps)
+	assert.Equal(t, 6, len(expanded), "number of apps after expanding")
+	for i, expected := range []struct{ replicaIdx, replicaOf int }{{0, 0}, {0, 0}, {0, 0}, {1, 0}, {2, 0}, {1, 2}} {
+		assert.Equal(t, i, expanded[i].AppIdx, fmt.Sprintf("AppIdx of app %d", i))
+		assert.Equal(t, expected.replicaIdx, expanded[i].ReplicaIdx, fmt.Sprintf("ReplicaIdx of app %d", i))
+		assert.Equal(t, expected.replicaOf, expanded[i].ReplicaOf, fmt.Sprintf("ReplicaOf of app %d", i))
+	}
+	assert.Equal(t, []Dependence{{AppIdx: 0}}, expanded[5].Depend, "the replicas have the same dependencies")
+	assert.Equal(t, expanded, ExpandReplicas(expanded), "expanded apps are not expanded again")
+
+	sets := ReplicaSets(expanded)
+	assert.Equal(t, [][]int{{0, 3, 4}, nil, {2, 5}, {0, 3, 4}, {0, 3, 4}, {2, 5}}, sets, "replica sets")
+	assert.Nil(t, ReplicaSets(apps[1:2]), "no replicated apps")
+}
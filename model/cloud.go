@@ -6,12 +6,114 @@ import (
 
 // Cloud : clouds that applications can be scheduled to
 type Cloud struct {
+	Name   string            `json:"name"`   // referred to by Application.RequiredClouds and Application.ForbiddenClouds
+	Labels map[string]string `json:"labels"` // matched by Application.CloudSelector, e.g., {"region": "eu"}, read-only, so the copies of a cloud share it
+
 	Capacity           Resources     `json:"capacity"`    // total resources
 	Allocatable        Resources     `json:"allocatable"` // allocatable resources
 	TmpAlloc           Resources     `json:"tmpAlloc"`    // temporary allocatable resources, for temporary record during scheduling
 	RunningApps        []Application `json:"runningApps"`
 	TotalTaskComplTime float64       `json:"totalTaskComplTime"` // unit second
 	UpdateTime         time.Time     `json:"updateTime"`
+	Price              CloudPrice    `json:"price"` // the prices of the resources, the traffic prices are in NetworkCondition
+	Power              CloudPower    `json:"power"` // the power draw and the carbon intensity of the electricity
+
+	// task execution model
+	TaskSharing TaskSharing `json:"taskSharing"` // how the tasks on this cloud use the logical cores left by services
+	Preemption  Preemption  `json:"preemption"`  // only effective when TaskSharing == TaskSharingNone, whether an application with a higher priority can suspend a task being executed on this cloud
+	ResumeTime  float64     `json:"resumeTime"`  // only effective when Preemption == PreemptionSuspend, the time to resume a suspended task through cgroup freezer, unit second
+}
+
+// Preemption decides whether the tasks being executed on a cloud since previous rounds can be suspended by applications with higher priorities
+type Preemption string
+
+const (
+	PreemptionFree    Preemption = ""        // all applications start in priority order, and a suspended task is resumed without cost, this is the default and the original model
+	PreemptionSuspend Preemption = "suspend" // all applications start in priority order, and resuming a suspended task costs ResumeTime
+	PreemptionNone    Preemption = "none"    // a task being executed runs to completion before other applications
+)
+
+// TaskSharing decides how the tasks on a cloud use its logical cores
+type TaskSharing string
+
+const (
+	TaskSharingNone     TaskSharing = ""         // tasks are executed one at a time, and each task uses all logical cores left by services, this is the default
+	TaskSharingFair     TaskSharing = "fair"     // tasks are executed concurrently, and the logical cores left by services are shared equally by the tasks being executed
+	TaskSharingPriority TaskSharing = "priority" // tasks are executed concurrently, and the logical cores left by services are shared by the tasks being executed in proportion to their priorities
+)
+
+// CloudPrice is the price list of a cloud in any currency, 0 means free
+type CloudPrice struct {
+	CPUHour       float64 `json:"cpuHour"`       // one logical core for one hour
+	MemoryGBHour  float64 `json:"memoryGBHour"`  // one GB of memory for one hour
+	StorageGBHour float64 `json:"storageGBHour"` // one GB of storage for one hour
+	GPUHour       float64 `json:"gpuHour"`       // one GPU for one hour
+}
+
+// CloudPower is the power model of a cloud. The power grows linearly with the busy logical cores from IdleWatts to PeakWatts.
+type CloudPower struct {
+	IdleWatts float64 `json:"idleWatts"` // the power when no logical core is busy, unit watt
+	PeakWatts float64 `json:"peakWatts"` // the power when all logical cores are busy, unit watt
+	PUE       float64 `json:"pue"`       // power usage effectiveness, the power of the whole data center divided by the power of the servers, 0 means 1
+
+	// optional carbon intensity of the electricity changing over time, sorted by Time, read-only, so the copies of a cloud share it.
+	// Every point lasts until the next one, the first point also applies before it, and the last point applies forever.
+	CarbonIntensity []CarbonIntensityPoint `json:"carbonIntensity"`
+}
+
+// CarbonIntensityPoint is one point of the carbon intensity curve of a cloud
+type CarbonIntensityPoint struct {
+	Time        float64 `json:"time"`        // unit second since the beginning of the simulation
+	GramsPerKWh float64 `json:"gramsPerKWh"` // the emissions of consuming one kWh, unit gram CO2-equivalent
+}
+
+// EffectivePUE returns PUE, or 1 if PUE is not set
+func (p CloudPower) EffectivePUE() float64 {
+	if p.PUE <= 0 {
+		return 1
+	}
+	return p.PUE
+}
+
+// CarbonIntensityAt returns the carbon intensity at time t (unit second), 0 if there is no curve
+func (p CloudPower) CarbonIntensityAt(t float64) float64 {
+	if len(p.CarbonIntensity) == 0 {
+		return 0
+	}
+	var intensity float64 = p.CarbonIntensity[0].GramsPerKWh
+	for _, point := range p.CarbonIntensity {
+		if point.Time > t {
+			break
+		}
+		intensity = point.GramsPerKWh
+	}
+	return intensity
+}
+
+// CarbonIntegral returns the integral of the carbon intensity from time from to time to, unit gram/kWh * second.
+// Multiplied by a constant power in watt and divided by 3.6e6, it is the emissions of that power during this period, unit gram.
+func (p CloudPower) CarbonIntegral(from, to float64) float64 {
+	if to <= from || len(p.CarbonIntensity) == 0 {
+		return 0
+	}
+	var integral float64
+	var segmentStart float64 = from
+	for _, point := range p.CarbonIntensity {
+		if point.Time <= segmentStart {
+			continue
+		}
+		if point.Time >= to {
+			break
+		}
+		integral += p.CarbonIntensityAt(segmentStart) * (point.Time - segmentStart)
+		segmentStart = point.Time
+	}
+	return integral + p.CarbonIntensityAt(segmentStart)*(to-segmentStart)
+}
+
+// ConcurrentTasks returns whether the tasks on this cloud are executed concurrently
+func (c Cloud) ConcurrentTasks() bool {
+	return c.TaskSharing != TaskSharingNone
 }
 
 // CloudsCopy deep copy a Cloud Slice
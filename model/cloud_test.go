@@ -0,0 +1,31 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarbonIntegral(t *testing.T) {
+	var power CloudPower = CloudPower{CarbonIntensity: []CarbonIntensityPoint{{Time: 100, GramsPerKWh: 300}, {Time: 200, GramsPerKWh: 100}, {Time: 400, GramsPerKWh: 500}}}
+	testCases := []struct {
+		name     string
+		power    CloudPower
+		from, to float64
+		expected float64
+	}{
+		{name: "case 1: no curve", power: CloudPower{}, from: 0, to: 1000, expected: 0},
+		{name: "case 2: before the first point", power: power, from: 0, to: 50, expected: 50 * 300},
+		{name: "case 3: inside one point", power: power, from: 250, to: 350, expected: 100 * 100},
+		{name: "case 4: across all points", power: power, from: 50, to: 500, expected: 150*300 + 200*100 + 100*500},
+		{name: "case 5: starts on a point", power: power, from: 200, to: 400, expected: 200 * 100},
+		{name: "case 6: empty period", power: power, from: 300, to: 300, expected: 0},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		assert.InDelta(t, testCase.expected, testCase.power.CarbonIntegral(testCase.from, testCase.to), 1e-9, fmt.Sprintf("%s: wrong integral", testCase.name))
+	}
+	assert.Equal(t, 1.0, CloudPower{}.EffectivePUE(), "PUE 0 should mean 1")
+	assert.Equal(t, 500.0, power.CarbonIntensityAt(1e9), "the last point should apply forever")
+}
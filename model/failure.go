@@ -0,0 +1,121 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// CloudFailure is a scripted outage or degradation in simulations, e.g., "cloud 3 is down from 120s to 300s" or "the bandwidth from cloud 2 to cloud 5 halves from 60s".
+// While it is active, the resources of the cloud or the bandwidth of the link are multiplied by Factor.
+type CloudFailure struct {
+	Kind         FailureKind `json:"kind"`
+	CloudIdx     int         `json:"cloudIdx"`     // the failed cloud, or the source of the failed link
+	PeerCloudIdx int         `json:"peerCloudIdx"` // only effective when Kind == FailureLink, the destination of the failed link
+	Start        float64     `json:"start"`        // time since the beginning of the simulation, unit second
+	End          float64     `json:"end"`          // time since the beginning of the simulation, unit second, the failure never recovers if End <= Start
+	Factor       float64     `json:"factor"`       // the proportion of the resources left, 0: the cloud is down or the link is cut off; 0.5: halved
+}
+
+// FailureKind decides what a CloudFailure affects
+type FailureKind string
+
+const (
+	FailureCloud FailureKind = ""     // all resources of a cloud, including its bandwidth from and to other clouds, this is the default
+	FailureLink  FailureKind = "link" // the bandwidth from a cloud to another cloud, i.e., the downstream bandwidth of PeerCloudIdx from CloudIdx
+)
+
+// ErrInvalidFailure is matched by errors.Is for every InvalidFailureError
+var ErrInvalidFailure = errors.New("invalid failure")
+
+// InvalidFailureError means that a CloudFailure cannot be applied to the clouds
+type InvalidFailureError struct {
+	Field  string // the invalid field, e.g., "cloudIdx"
+	Reason string // why it is invalid
+}
+
+func (e *InvalidFailureError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidFailure.Error(), e.Field, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrInvalidFailure) true
+func (e *InvalidFailureError) Is(target error) bool {
+	return target == ErrInvalidFailure
+}
+
+// Validate checks whether the failure can be applied to cloudNum clouds, the returned error is an *InvalidFailureError
+func (f CloudFailure) Validate(cloudNum int) error {
+	if f.Kind != FailureCloud && f.Kind != FailureLink {
+		return &InvalidFailureError{Field: "kind", Reason: fmt.Sprintf("is %q, it should be %q or %q", f.Kind, FailureCloud, FailureLink)}
+	}
+	if f.CloudIdx < 0 || f.CloudIdx >= cloudNum {
+		return &InvalidFailureError{Field: "cloudIdx", Reason: fmt.Sprintf("is %d, out of [0, %d)", f.CloudIdx, cloudNum)}
+	}
+	if f.Kind == FailureLink && (f.PeerCloudIdx < 0 || f.PeerCloudIdx >= cloudNum || f.PeerCloudIdx == f.CloudIdx) {
+		return &InvalidFailureError{Field: "peerCloudIdx", Reason: fmt.Sprintf("is %d, it should be another cloud in [0, %d)", f.PeerCloudIdx, cloudNum)}
+	}
+	// a factor above 1 would create resources, and a factor of 1 changes nothing
+	if !(f.Factor >= 0 && f.Factor < 1) {
+		return &InvalidFailureError{Field: "factor", Reason: fmt.Sprintf("is %g, out of [0, 1)", f.Factor)}
+	}
+	return nil
+}
+
+// Active returns whether the failure is in effect at time t (unit second)
+func (f CloudFailure) Active(t float64) bool {
+	return t >= f.Start && (f.End <= f.Start || t < f.End)
+}
+
+// Down returns whether the failure takes a whole cloud down
+func (f CloudFailure) Down() bool {
+	return f.Kind == FailureCloud && f.Factor == 0
+}
+
+// ApplyFailures returns a copy of clouds in which the failures active at time t (unit second) are in effect.
+// The capacity lost by a failure is also taken from the allocatable resources, so it works on clouds with deployed applications, and the allocatable resources of a cloud that is down are 0.
+// The failures that are not valid for clouds are ignored, callers should check them with Validate first.
+func ApplyFailures(clouds []Cloud, failures []CloudFailure, t float64) []Cloud {
+	var failedClouds []Cloud = CloudsCopy(clouds)
+	for _, failure := range failures {
+		if !failure.Active(t) || failure.Validate(len(clouds)) != nil {
+			continue
+		}
+		if failure.Kind == FailureLink {
+			degradeBw(&failedClouds[failure.PeerCloudIdx], failure.CloudIdx, failure.Factor)
+			continue
+		}
+		var cloud *Cloud = &failedClouds[failure.CloudIdx]
+		degrade(&cloud.Capacity.CPU.LogicalCores, &cloud.Allocatable.CPU.LogicalCores, failure.Factor)
+		degradeAmounts(&cloud.Capacity, &cloud.Allocatable, failure.Factor)
+		for j := 0; j < len(failedClouds); j++ {
+			if j == failure.CloudIdx {
+				continue
+			}
+			degradeBw(cloud, j, failure.Factor)
+			degradeBw(&failedClouds[j], failure.CloudIdx, failure.Factor)
+		}
+	}
+	return failedClouds
+}
+
+// degrade multiplies a capacity by factor, and takes the lost part from the allocatable amount
+func degrade(capacity, allocatable *float64, factor float64) {
+	var lost float64 = *capacity * (1 - factor)
+	*capacity -= lost
+	*allocatable = math.Max(0, *allocatable-lost)
+}
+
+// degradeAmounts is degrade for all resources other than CPU
+func degradeAmounts(capacity, allocatable *Resources, factor float64) {
+	var lost Amounts = combine(capacity.Amounts(), Amounts{}, func(x, _ float64) float64 { return x * (1 - factor) })
+	capacity.SetAmounts(capacity.Amounts().Sub(lost))
+	allocatable.SetAmounts(combine(allocatable.Amounts(), lost, func(x, y float64) float64 { return math.Max(0, x-y) }))
+}
+
+// degradeBw multiplies the downstream bandwidth of cloud from the cloud with index from by factor
+func degradeBw(cloud *Cloud, from int, factor float64) {
+	if from >= len(cloud.Capacity.NetCondClouds) || from >= len(cloud.Allocatable.NetCondClouds) {
+		return
+	}
+	degrade(&cloud.Capacity.NetCondClouds[from].DownBw, &cloud.Allocatable.NetCondClouds[from].DownBw, factor)
+}
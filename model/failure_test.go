@@ -0,0 +1,131 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyFailures(t *testing.T) {
+	var newCloud func(cores, memory float64) Cloud = func(cores, memory float64) Cloud {
+		return Cloud{
+			Capacity: Resources{
+				CPU:           CPUResource{LogicalCores: 8, BaseClock: 2},
+				Memory:        100,
+				GPU:           AcceleratorResource{Count: 2, Memory: 32},
+				Scalars:       ScalarResources{"licenses": 4},
+				NetCondClouds: []NetworkCondition{{RTT: 1, DownBw: 100}, {RTT: 1, DownBw: 100}},
+			},
+			Allocatable: Resources{
+				CPU:           CPUResource{LogicalCores: cores, BaseClock: 2},
+				Memory:        memory,
+				GPU:           AcceleratorResource{Count: 2, Memory: 32},
+				Scalars:       ScalarResources{"licenses": 4},
+				NetCondClouds: []NetworkCondition{{RTT: 1, DownBw: 100}, {RTT: 1, DownBw: 100}},
+			},
+		}
+	}
+	var clouds []Cloud = []Cloud{newCloud(6, 40), newCloud(8, 100)}
+
+	testCases := []struct {
+		name     string
+		failure  CloudFailure
+		time     float64
+		expected func() []Cloud
+	}{
+		{
+			name:     "case 1: not started",
+			failure:  CloudFailure{CloudIdx: 0, Start: 120, End: 300, Factor: 0},
+			time:     60,
+			expected: func() []Cloud { return CloudsCopy(clouds) },
+		},
+		{
+			name:     "case 2: recovered",
+			failure:  CloudFailure{CloudIdx: 0, Start: 120, End: 300, Factor: 0},
+			time:     300,
+			expected: func() []Cloud { return CloudsCopy(clouds) },
+		},
+		{
+			name:    "case 3: cloud down",
+			failure: CloudFailure{CloudIdx: 0, Start: 120, End: 300, Factor: 0},
+			time:    120,
+			expected: func() []Cloud {
+				expected := CloudsCopy(clouds)
+				expected[0].Capacity = Resources{CPU: CPUResource{BaseClock: 2}, Scalars: ScalarResources{"licenses": 0}, NetCondClouds: []NetworkCondition{{RTT: 1, DownBw: 100}, {RTT: 1}}}
+				expected[0].Allocatable = ResCopy(expected[0].Capacity)
+				expected[1].Capacity.NetCondClouds[0].DownBw = 0
+				expected[1].Allocatable.NetCondClouds[0].DownBw = 0
+				return expected
+			},
+		},
+		{
+			name:    "case 4: cloud halved, never recovers",
+			failure: CloudFailure{CloudIdx: 0, Start: 120, Factor: 0.5},
+			time:    1000,
+			expected: func() []Cloud {
+				expected := CloudsCopy(clouds)
+				expected[0].Capacity = Resources{CPU: CPUResource{LogicalCores: 4, BaseClock: 2}, Memory: 50, GPU: AcceleratorResource{Count: 1, Memory: 16}, Scalars: ScalarResources{"licenses": 2}, NetCondClouds: []NetworkCondition{{RTT: 1, DownBw: 100}, {RTT: 1, DownBw: 50}}}
+				expected[0].Allocatable = Resources{CPU: CPUResource{LogicalCores: 2, BaseClock: 2}, Memory: 0, GPU: AcceleratorResource{Count: 1, Memory: 16}, Scalars: ScalarResources{"licenses": 2}, NetCondClouds: []NetworkCondition{{RTT: 1, DownBw: 100}, {RTT: 1, DownBw: 50}}}
+				expected[1].Capacity.NetCondClouds[0].DownBw = 50
+				expected[1].Allocatable.NetCondClouds[0].DownBw = 50
+				return expected
+			},
+		},
+		{
+			name:    "case 5: link halved",
+			failure: CloudFailure{Kind: FailureLink, CloudIdx: 0, PeerCloudIdx: 1, Start: 120, End: 300, Factor: 0.5},
+			time:    200,
+			expected: func() []Cloud {
+				expected := CloudsCopy(clouds)
+				expected[1].Capacity.NetCondClouds[0].DownBw = 50
+				expected[1].Allocatable.NetCondClouds[0].DownBw = 50
+				return expected
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		actual := ApplyFailures(clouds, []CloudFailure{testCase.failure}, testCase.time)
+		assert.Equal(t, testCase.expected(), actual, fmt.Sprintf("%s: clouds not as expected", testCase.name))
+	}
+	assert.Equal(t, []Cloud{newCloud(6, 40), newCloud(8, 100)}, clouds, "the original clouds should not change")
+}
+
+func TestCloudFailureValidate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		failure       CloudFailure
+		expectedField string
+	}{
+		{name: "case 1: cloud down", failure: CloudFailure{CloudIdx: 1}},
+		{name: "case 2: link halved", failure: CloudFailure{Kind: FailureLink, CloudIdx: 0, PeerCloudIdx: 1, Factor: 0.5}},
+		{name: "case 3: unknown kind", failure: CloudFailure{Kind: "flood"}, expectedField: "kind"},
+		{name: "case 4: missing cloud", failure: CloudFailure{CloudIdx: 2}, expectedField: "cloudIdx"},
+		{name: "case 5: negative cloud", failure: CloudFailure{CloudIdx: -1}, expectedField: "cloudIdx"},
+		{name: "case 6: missing peer", failure: CloudFailure{Kind: FailureLink, CloudIdx: 0, PeerCloudIdx: 2}, expectedField: "peerCloudIdx"},
+		{name: "case 7: link to itself", failure: CloudFailure{Kind: FailureLink, CloudIdx: 1, PeerCloudIdx: 1}, expectedField: "peerCloudIdx"},
+		{name: "case 8: negative factor", failure: CloudFailure{Factor: -0.5}, expectedField: "factor"},
+		{name: "case 9: factor creating resources", failure: CloudFailure{Factor: 1.5}, expectedField: "factor"},
+		{name: "case 10: factor without loss", failure: CloudFailure{Factor: 1}, expectedField: "factor"},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		err := testCase.failure.Validate(2)
+		if testCase.expectedField == "" {
+			assert.NoError(t, err, fmt.Sprintf("%s: unexpected error", testCase.name))
+			continue
+		}
+		var failureErr *InvalidFailureError
+		if assert.True(t, errors.As(err, &failureErr), fmt.Sprintf("%s: %v is not an InvalidFailureError", testCase.name, err)) {
+			assert.True(t, errors.Is(err, ErrInvalidFailure))
+			assert.Equal(t, testCase.expectedField, failureErr.Field, testCase.name)
+		}
+	}
+
+	// an invalid failure is ignored instead of indexing a missing cloud
+	var clouds []Cloud = []Cloud{{Capacity: Resources{Memory: 40}, Allocatable: Resources{Memory: 40}}, {Capacity: Resources{Memory: 100}}}
+	assert.Equal(t, CloudsCopy(clouds), ApplyFailures(clouds, []CloudFailure{{CloudIdx: 5}, {Factor: 2}}, 0))
+}
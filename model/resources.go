@@ -1,10 +1,17 @@
 package model
 
+import (
+	"math"
+	"sort"
+)
+
 // Resources : resources that clouds have and applications require
 type Resources struct {
-	CPU     CPUResource `json:"cpu"`
-	Memory  float64     `json:"memory"`  // unit Byte (B)
-	Storage float64     `json:"storage"` // unit Byte (B)
+	CPU     CPUResource         `json:"cpu"`
+	Memory  float64             `json:"memory"`  // unit Byte (B)
+	Storage float64             `json:"storage"` // unit Byte (B)
+	GPU     AcceleratorResource `json:"gpu"`
+	Scalars ScalarResources     `json:"scalars,omitempty"` // named resources declared in the input files, e.g., "licenses" or "hugepages"
 
 	// network resources
 	NetCondClouds     []NetworkCondition `json:"netCondClouds"`     // network condition between this cloud and every other cloud
@@ -19,29 +26,199 @@ func ResCopy(src Resources) Resources {
 	var dst Resources = src
 	dst.NetCondClouds = make([]NetworkCondition, len(src.NetCondClouds))
 	copy(dst.NetCondClouds, src.NetCondClouds)
+	dst.Scalars = src.Scalars.Copy()
 	return dst
 }
 
+// Amounts returns the resources of r other than CPU
+func (r Resources) Amounts() Amounts {
+	return Amounts{Memory: r.Memory, Storage: r.Storage, GPU: r.GPU, Scalars: r.Scalars}
+}
+
+// SetAmounts sets the resources of r other than CPU
+func (r *Resources) SetAmounts(a Amounts) {
+	r.Memory, r.Storage, r.GPU, r.Scalars = a.Memory, a.Storage, a.GPU, a.Scalars
+}
+
+// Amounts are the resources other than CPU, which are all accounted in the same way: a service takes them up, and a task needs them to be available during its execution.
+// The checks and the accounting of them go through the methods of Amounts, so a new kind of resource only needs to be added here. CPU keeps its clock-speed semantics and is handled separately.
+type Amounts struct {
+	Memory  float64
+	Storage float64
+	GPU     AcceleratorResource
+	Scalars ScalarResources
+}
+
+// Shortage is a resource of which less is available than required
+type Shortage struct {
+	Name      string       // "memory", "storage", "gpu", "gpuMemory", or the name of a scalar resource
+	Reason    RejectReason // the reject reason of an app that is short of this resource
+	Required  float64
+	Available float64
+}
+
+// Shortages returns the resources in req of which less is available in a, in the order memory, storage, GPUs, GPU memory, and the scalar resources by name
+func (a Amounts) Shortages(req Amounts) []Shortage {
+	var shortages []Shortage
+	var check func(string, RejectReason, float64, float64) = func(name string, reason RejectReason, required, available float64) {
+		if available < required {
+			shortages = append(shortages, Shortage{Name: name, Reason: reason, Required: required, Available: available})
+		}
+	}
+	check("memory", RejectReasonMemory, req.Memory, a.Memory)
+	check("storage", RejectReasonStorage, req.Storage, a.Storage)
+	check("gpu", RejectReasonGPU, req.GPU.Count, a.GPU.Count)
+	check("gpuMemory", RejectReasonGPU, req.GPU.Memory, a.GPU.Memory)
+	if !a.Scalars.Fits(req.Scalars) {
+		for _, name := range req.Scalars.Names() {
+			check(name, RejectReasonScalar, req.Scalars[name], a.Scalars[name])
+		}
+	}
+	return shortages
+}
+
+// Fits returns whether every resource in req is available in a
+func (a Amounts) Fits(req Amounts) bool {
+	return len(a.Shortages(req)) == 0
+}
+
+// Sub returns a minus req, a is not modified
+func (a Amounts) Sub(req Amounts) Amounts {
+	return combine(a, req, func(x, y float64) float64 { return x - y })
+}
+
+// combine applies f to every resource of a and the same resource of b, the scalar resources are those in either of them
+func combine(a, b Amounts, f func(x, y float64) float64) Amounts {
+	var c Amounts = Amounts{
+		Memory:  f(a.Memory, b.Memory),
+		Storage: f(a.Storage, b.Storage),
+		GPU:     AcceleratorResource{Count: f(a.GPU.Count, b.GPU.Count), Memory: f(a.GPU.Memory, b.GPU.Memory)},
+		Scalars: a.Scalars,
+	}
+	if len(b.Scalars) == 0 && len(a.Scalars) == 0 {
+		return c
+	}
+	c.Scalars = make(ScalarResources, len(a.Scalars)+len(b.Scalars))
+	for name, x := range a.Scalars {
+		c.Scalars[name] = f(x, b.Scalars[name])
+	}
+	for name, y := range b.Scalars {
+		if _, exist := a.Scalars[name]; !exist {
+			c.Scalars[name] = f(0, y)
+		}
+	}
+	return c
+}
+
 type CPUResource struct {
 	LogicalCores float64 `json:"logicalCores"` // number of logical cores
 	BaseClock    float64 `json:"baseClock"`    // unit GHz
 }
 
+// AcceleratorResource is the simulated capacity or requirement of a kind of accelerators, e.g., GPUs
+type AcceleratorResource struct {
+	Count  float64 `json:"count"`  // number of accelerators
+	Memory float64 `json:"memory"` // total memory of the accelerators, unit Byte (B)
+}
+
+// ScalarResources are the amounts of named scalar resources. A cloud has none of a resource that is not in its map, and an app requires none of a resource that is not in its map.
+// Like memory and storage, a service takes up its scalar resources, and a task needs them to be available during its execution.
+type ScalarResources map[string]float64
+
+// Copy deep copies the scalar resources, nil stays nil
+func (s ScalarResources) Copy() ScalarResources {
+	if s == nil {
+		return nil
+	}
+	var dst ScalarResources = make(ScalarResources, len(s))
+	for name, amount := range s {
+		dst[name] = amount
+	}
+	return dst
+}
+
+// Names returns the names of the scalar resources in ascending order, so that the checks over them are deterministic
+func (s ScalarResources) Names() []string {
+	var names []string = make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fits returns whether every scalar resource in req is available in s
+func (s ScalarResources) Fits(req ScalarResources) bool {
+	for name, amount := range req {
+		if s[name] < amount {
+			return false
+		}
+	}
+	return true
+}
+
+// Sub returns s minus req, s is not modified
+func (s ScalarResources) Sub(req ScalarResources) ScalarResources {
+	if len(req) == 0 {
+		return s
+	}
+	var dst ScalarResources = s.Copy()
+	if dst == nil {
+		dst = make(ScalarResources, len(req))
+	}
+	for name, amount := range req {
+		dst[name] -= amount
+	}
+	return dst
+}
+
 type NetworkCondition struct {
-	RTT    float64 `json:"rtt"`    // Round-Trip Time, unit millisecond (ms)
-	DownBw float64 `json:"doneBw"` // downstream bandwidth, unit Mb/s
+	RTT        float64 `json:"rtt"`                  // Round-Trip Time, unit millisecond (ms)
+	DownBw     float64 `json:"doneBw"`               // downstream bandwidth, unit Mb/s
+	PricePerGB float64 `json:"pricePerGB,omitempty"` // the price of one GB of downstream traffic, e.g., the egress price of the other cloud, 0 means free
 }
 
 type ServiceResources struct {
-	CPUClock float64 `json:"cpuClock"` // unit GHz
-	Memory   float64 `json:"memory"`   // unit Byte (B)
-	Storage  float64 `json:"storage"`  // unit Byte (B)
+	CPUClock float64             `json:"cpuClock"` // unit GHz
+	Memory   float64             `json:"memory"`   // unit Byte (B)
+	Storage  float64             `json:"storage"`  // unit Byte (B)
+	GPU      AcceleratorResource `json:"gpu"`
+	Scalars  ScalarResources     `json:"scalars,omitempty"`
 }
 
 type TaskResources struct {
-	CPUCycle float64 `json:"cpuCycle"` // number of CPU cycles needed to execute the task
-	Memory   float64 `json:"memory"`   // unit Byte (B)
-	Storage  float64 `json:"storage"`  // unit Byte (B)
+	CPUCycle float64             `json:"cpuCycle"` // number of CPU cycles needed to execute the task
+	Memory   float64             `json:"memory"`   // unit Byte (B)
+	Storage  float64             `json:"storage"`  // unit Byte (B)
+	GPU      AcceleratorResource `json:"gpu"`
+	Scalars  ScalarResources     `json:"scalars,omitempty"`
+
+	// parallelism limit, 0 means no limit, so that the task uses all logical cores given to it
+	MaxCores       float64 `json:"maxCores"`       // the largest number of logical cores that the task can use, e.g., 1 for a single-threaded task
+	SerialFraction float64 `json:"serialFraction"` // the fraction of the CPU cycles that can only be executed on one core, range [0, 1], following Amdahl's law
+}
+
+// Amounts returns the resources of sr other than CPU
+func (sr ServiceResources) Amounts() Amounts {
+	return Amounts{Memory: sr.Memory, Storage: sr.Storage, GPU: sr.GPU, Scalars: sr.Scalars}
+}
+
+// Amounts returns the resources of tr other than CPU
+func (tr TaskResources) Amounts() Amounts {
+	return Amounts{Memory: tr.Memory, Storage: tr.Storage, GPU: tr.GPU, Scalars: tr.Scalars}
+}
+
+// EffectiveCores returns how many logical cores the task is as fast as with when it is given cores logical cores.
+// At most MaxCores are used, and with SerialFraction s and n used cores, the speedup is 1 / (s + (1-s)/n) according to Amdahl's law.
+func (tr TaskResources) EffectiveCores(cores float64) float64 {
+	if tr.MaxCores > 0 && cores > tr.MaxCores {
+		cores = tr.MaxCores
+	}
+	if tr.SerialFraction <= 0 || cores <= 1 {
+		return cores
+	}
+	serialFraction := math.Min(tr.SerialFraction, 1)
+	return 1 / (serialFraction + (1-serialFraction)/cores)
 }
 
 type Dependence struct {
@@ -1,8 +1,11 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestResCopy(t *testing.T) {
@@ -44,3 +47,85 @@ func TestResCopy(t *testing.T) {
 	fmt.Println(cloud.Capacity)
 	fmt.Println(cloud.TmpAlloc)
 }
+
+func TestEffectiveCores(t *testing.T) {
+	testCases := []struct {
+		name     string
+		req      TaskResources
+		cores    float64
+		expected float64
+	}{
+		{name: "case 1: no limit", req: TaskResources{}, cores: 16, expected: 16},
+		{name: "case 2: single-threaded", req: TaskResources{MaxCores: 1}, cores: 16, expected: 1},
+		{name: "case 3: fewer cores than MaxCores", req: TaskResources{MaxCores: 8}, cores: 4, expected: 4},
+		{name: "case 4: Amdahl", req: TaskResources{SerialFraction: 0.5}, cores: 16, expected: 1 / (0.5 + 0.5/16)},
+		{name: "case 5: Amdahl with MaxCores", req: TaskResources{MaxCores: 4, SerialFraction: 0.2}, cores: 16, expected: 1 / (0.2 + 0.8/4)},
+		{name: "case 6: totally serial", req: TaskResources{SerialFraction: 1}, cores: 16, expected: 1},
+		{name: "case 7: less than one core", req: TaskResources{SerialFraction: 0.5}, cores: 0.5, expected: 0.5},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		assert.InDelta(t, testCase.expected, testCase.req.EffectiveCores(testCase.cores), 1e-9, fmt.Sprintf("%s: wrong effective cores", testCase.name))
+	}
+}
+
+func TestScalarResources(t *testing.T) {
+	var alloc ScalarResources = ScalarResources{"licenses": 2, "hugepages": 8}
+	testCases := []struct {
+		name        string
+		req         ScalarResources
+		expectedFit bool
+		expectedSub ScalarResources
+	}{
+		{name: "case 1: no requirement", req: nil, expectedFit: true, expectedSub: ScalarResources{"licenses": 2, "hugepages": 8}},
+		{name: "case 2: fits", req: ScalarResources{"licenses": 2, "hugepages": 1}, expectedFit: true, expectedSub: ScalarResources{"licenses": 0, "hugepages": 7}},
+		{name: "case 3: too much", req: ScalarResources{"licenses": 3}, expectedFit: false, expectedSub: ScalarResources{"licenses": -1, "hugepages": 8}},
+		{name: "case 4: not declared by the cloud", req: ScalarResources{"fpga": 1}, expectedFit: false, expectedSub: ScalarResources{"licenses": 2, "hugepages": 8, "fpga": -1}},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		assert.Equal(t, testCase.expectedFit, alloc.Fits(testCase.req), fmt.Sprintf("%s: Fits", testCase.name))
+		assert.Equal(t, testCase.expectedSub, alloc.Sub(testCase.req), fmt.Sprintf("%s: Sub", testCase.name))
+		assert.Equal(t, ScalarResources{"licenses": 2, "hugepages": 8}, alloc, fmt.Sprintf("%s: Sub modified the receiver", testCase.name))
+	}
+	assert.Equal(t, []string{"hugepages", "licenses"}, alloc.Names(), "Names should be sorted")
+	assert.Equal(t, ScalarResources{"fpga": -1}, ScalarResources(nil).Sub(ScalarResources{"fpga": 1}), "Sub of nil")
+
+	// scalar resources are declared in the input files
+	var res Resources
+	err := json.Unmarshal([]byte(`{"memory":1,"scalars":{"licenses":5}}`), &res)
+	assert.NoError(t, err)
+	assert.Equal(t, ScalarResources{"licenses": 5}, res.Scalars, "unmarshal scalars")
+	resCopy := ResCopy(res)
+	resCopy.Scalars["licenses"] = 1
+	assert.Equal(t, 5.0, res.Scalars["licenses"], "ResCopy should deep copy scalars")
+}
+
+func TestAmounts(t *testing.T) {
+	var alloc Amounts = Resources{Memory: 8, Storage: 16, GPU: AcceleratorResource{Count: 1, Memory: 4}, Scalars: ScalarResources{"licenses": 2}}.Amounts()
+	testCases := []struct {
+		name              string
+		req               Amounts
+		expectedShortages []string
+		expectedSub       Amounts
+	}{
+		{name: "case 1: fits", req: ServiceResources{Memory: 8, GPU: AcceleratorResource{Count: 1}}.Amounts(), expectedSub: Amounts{Storage: 16, GPU: AcceleratorResource{Memory: 4}, Scalars: ScalarResources{"licenses": 2}}},
+		{name: "case 2: every kind short", req: TaskResources{Memory: 9, Storage: 17, GPU: AcceleratorResource{Count: 2, Memory: 5}, Scalars: ScalarResources{"licenses": 3, "fpga": 1}}.Amounts(), expectedShortages: []string{"memory", "storage", "gpu", "gpuMemory", "fpga", "licenses"}, expectedSub: Amounts{Memory: -1, Storage: -1, GPU: AcceleratorResource{Count: -1, Memory: -1}, Scalars: ScalarResources{"licenses": -1, "fpga": -1}}},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		var names []string
+		for _, shortage := range alloc.Shortages(testCase.req) {
+			names = append(names, shortage.Name)
+		}
+		assert.Equal(t, testCase.expectedShortages, names, fmt.Sprintf("%s: Shortages", testCase.name))
+		assert.Equal(t, len(testCase.expectedShortages) == 0, alloc.Fits(testCase.req), fmt.Sprintf("%s: Fits", testCase.name))
+		assert.Equal(t, testCase.expectedSub, alloc.Sub(testCase.req), fmt.Sprintf("%s: Sub", testCase.name))
+	}
+	assert.Equal(t, ScalarResources{"licenses": 2}, alloc.Scalars, "Sub modified the receiver")
+
+	var res Resources
+	res.SetAmounts(alloc.Sub(TaskResources{Memory: 2}.Amounts()))
+	assert.Equal(t, 6.0, res.Memory, "SetAmounts")
+	assert.Nil(t, Amounts{}.Sub(Amounts{}).Scalars, "Sub without scalar resources should not create them")
+}
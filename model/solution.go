@@ -2,7 +2,81 @@ package model
 
 // Solution for scheduling applications to clouds
 type Solution struct {
-	SchedulingResult []int `json:"schedulingResult"`
+	SchedulingResult []int `json:"schedulingResult"` // the cloud index of each app, len(clouds) means that the app is rejected
+	Seed             int64 `json:"seed"`             // the seed of the random source that produced this solution, used to replay the scheduling
+	Truncated        bool  `json:"truncated"`        // the search was stopped by its context before convergence, so this is only the best solution found until then
+
+	// the following attributes describe SchedulingResult, they are filled by the schedulers
+	Placements     []AppPlacement         `json:"placements"`     // where each app is placed or why it is rejected, in the order of apps
+	CloudMakespans []float64              `json:"cloudMakespans"` // on each cloud, the time when the last app becomes stable or the last task completes, unit second
+	Fitness        float64                `json:"fitness"`        // the fitness value of SchedulingResult given by the algorithm, 0 for algorithms without a fitness function
+	Algorithm      string                 `json:"algorithm"`      // the name of the algorithm that produced this solution
+	Params         map[string]interface{} `json:"params"`         // the parameters of the algorithm
+	Cost           float64                `json:"cost"`           // the cost paid once for the accepted apps, including the whole execution of tasks
+	HourlyCost     float64                `json:"hourlyCost"`     // the cost paid every hour for the accepted services
+
+	// the following attributes are only filled by multi-objective algorithms
+	ObjectiveNames []string      `json:"objectiveNames,omitempty"` // the names of the objectives in ParetoFront, all objectives are minimized
+	ParetoFront    []ParetoPoint `json:"paretoFront,omitempty"`    // the acceptable non-dominated scheduling results found by the algorithm, SchedulingResult is one of them
+}
+
+// ParetoPoint is one scheduling result on a Pareto front
+type ParetoPoint struct {
+	SchedulingResult []int     `json:"schedulingResult"`
+	Objectives       []float64 `json:"objectives"` // in the order of Solution.ObjectiveNames
+}
+
+// RejectReason explains why an application is rejected
+type RejectReason string
+
+const (
+	RejectReasonNone               RejectReason = ""
+	RejectReasonCPU                RejectReason = "insufficient CPU"
+	RejectReasonMemory             RejectReason = "insufficient memory"
+	RejectReasonStorage            RejectReason = "insufficient storage"
+	RejectReasonGPU                RejectReason = "insufficient GPU"
+	RejectReasonScalar             RejectReason = "insufficient scalar resource"
+	RejectReasonBandwidth          RejectReason = "insufficient bandwidth"
+	RejectReasonRTT                RejectReason = "RTT violation"
+	RejectReasonDeadline           RejectReason = "deadline missed"
+	RejectReasonDependencyRejected RejectReason = "dependent app rejected"
+	RejectReasonPlacement          RejectReason = "placement constraint violated" // required clouds, forbidden clouds, or cloud selector
+	RejectReasonAntiAffinity       RejectReason = "anti-affinity violation"
+	RejectReasonCoLocation         RejectReason = "co-location violation"
+	RejectReasonReplicas           RejectReason = "replicas not all accepted"
+	RejectReasonSpread             RejectReason = "replicas not spread"
+	RejectReasonBudget             RejectReason = "over budget"
+	RejectReasonNotSelected        RejectReason = "not selected by the algorithm" // the app could be accepted by some cloud, but the algorithm chose to reject it
+)
+
+// AppPlacement is the scheduling result of one application
+type AppPlacement struct {
+	AppIdx       int          `json:"appIdx"`
+	CloudIdx     int          `json:"cloudIdx"` // -1 if the app is rejected
+	Rejected     bool         `json:"rejected"`
+	RejectReason RejectReason `json:"rejectReason,omitempty"`
+	Violations   []Violation  `json:"violations,omitempty"` // for rejected apps, the constraints violated when trying to deploy it on each cloud
+
+	// times calculated with this solution, 0 if the app is rejected, unit second
+	StartTime          float64 `json:"startTime"`
+	StableTime         float64 `json:"stableTime"`
+	TaskCompletionTime float64 `json:"taskCompletionTime"` // only task has this
+	Lateness           float64 `json:"lateness,omitempty"` // how long the app finishes after its deadline or maximum startup time
+
+	Cost       float64 `json:"cost"`       // the cost paid once: image pulling, data input, and for a task, its whole execution
+	HourlyCost float64 `json:"hourlyCost"` // only service has this, the cost paid every hour while it runs
+}
+
+// Violation is a constraint violated by a scheduling result
+type Violation struct {
+	CloudIdx       int          `json:"cloudIdx"`       // the cloud on which the constraint is violated, -1 for the replicas of a service
+	AppIdx         int          `json:"appIdx"`         // the app that violates the constraint
+	Reason         RejectReason `json:"reason"`         // the type of the constraint
+	Resource       string       `json:"resource"`       // the violated resource: cpu, memory, storage, gpu, gpuMemory, the name of a scalar resource, rtt, downBw, upBw, dependency, deadline, cloud, antiAffinity, coLocation, replicas, spread, or budget
+	DependAppIdx   int          `json:"dependAppIdx"`   // for dependency edges, the app that AppIdx depends on, for anti-affinity and co-location, the other app, otherwise -1
+	DependCloudIdx int          `json:"dependCloudIdx"` // for dependency edges and co-location, the cloud of DependAppIdx, -1 if it is rejected or this is not an edge
+	Required       float64      `json:"required"`       // the amount required by the app, the maximum acceptable value for RTT, deadline and budget, unit is the same as the resource
+	Available      float64      `json:"available"`      // the amount left when the app is checked, the actual value for RTT, deadline (finish time) and budget (cost)
 }
 
 // SolutionCopy deep copy a solution
@@ -10,5 +84,38 @@ func SolutionCopy(src Solution) Solution {
 	var dst Solution = src
 	dst.SchedulingResult = make([]int, len(src.SchedulingResult))
 	copy(dst.SchedulingResult, src.SchedulingResult)
+	if src.Placements != nil {
+		dst.Placements = make([]AppPlacement, len(src.Placements))
+		copy(dst.Placements, src.Placements)
+		for i := 0; i < len(src.Placements); i++ {
+			if src.Placements[i].Violations != nil {
+				dst.Placements[i].Violations = make([]Violation, len(src.Placements[i].Violations))
+				copy(dst.Placements[i].Violations, src.Placements[i].Violations)
+			}
+		}
+	}
+	if src.CloudMakespans != nil {
+		dst.CloudMakespans = make([]float64, len(src.CloudMakespans))
+		copy(dst.CloudMakespans, src.CloudMakespans)
+	}
+	if src.ObjectiveNames != nil {
+		dst.ObjectiveNames = make([]string, len(src.ObjectiveNames))
+		copy(dst.ObjectiveNames, src.ObjectiveNames)
+	}
+	if src.ParetoFront != nil {
+		dst.ParetoFront = make([]ParetoPoint, len(src.ParetoFront))
+		for i := 0; i < len(src.ParetoFront); i++ {
+			dst.ParetoFront[i].SchedulingResult = make([]int, len(src.ParetoFront[i].SchedulingResult))
+			copy(dst.ParetoFront[i].SchedulingResult, src.ParetoFront[i].SchedulingResult)
+			dst.ParetoFront[i].Objectives = make([]float64, len(src.ParetoFront[i].Objectives))
+			copy(dst.ParetoFront[i].Objectives, src.ParetoFront[i].Objectives)
+		}
+	}
+	if src.Params != nil {
+		dst.Params = make(map[string]interface{}, len(src.Params))
+		for k, v := range src.Params {
+			dst.Params[k] = v
+		}
+	}
 	return dst
 }
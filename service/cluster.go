@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gogeneticwrsp/algorithms"
+	"gogeneticwrsp/model"
+)
+
+// ErrUnknownApp means that a notification refers to an app that is not in the cluster
+var ErrUnknownApp = errors.New("unknown app")
+
+// Cluster keeps the state of the clouds between rounds of online scheduling, like ContinuousExperiment, but driven by the events of a real controller:
+// an app group arrives, apps complete, or clouds fail and recover. Every event is a round, in which the apps remaining on the clouds at that time,
+// from algorithms.CalcRemainingApps, are rescheduled together with the new apps on the clouds with the active failures.
+// The time of the cluster is in seconds since NewCluster, the same as the simulation time of the experiments.
+// The apps are identified by their OriIdx, which the cluster assigns in the order of arrival.
+type Cluster struct {
+	mu sync.Mutex // held during a whole round
+
+	clouds    []model.Cloud              // the clouds without any app or failure
+	scheduler algorithms.SchedulerConfig // the scheduler of the rounds that do not choose one
+	start     time.Time                  // when the cluster started
+
+	roundState // the state changed by the rounds, guarded by mu
+	nextOriIdx int
+
+	// State is served from a copy of roundState published after every round, so it does not wait for the round in progress
+	snapshotMu sync.RWMutex
+	snapshot   roundState
+
+	now func() time.Time // the clock, replaced in tests
+}
+
+// roundState is the state of a Cluster that the rounds change
+type roundState struct {
+	failures   []model.CloudFailure // all reported failures, with the time of the cluster
+	lastTime   float64              // the time of the last round
+	lastClouds []model.Cloud        // the clouds with the failures active in the last round
+	lastApps   []model.Application  // the apps scheduled in the last round
+	lastNew    map[int]bool         // ids of the apps that arrived in the last round
+	solution   model.Solution       // the solution of the last round
+	deployed   []model.Cloud        // lastClouds with the accepted apps of the last round deployed, their UpdateTime is the time of the last round
+
+	submitted, rejected, lost, restarted, completed int
+}
+
+// copy deep copies rs, so that the copy is not changed by the later rounds
+func (rs roundState) copy() roundState {
+	var dst roundState = rs
+	dst.failures = append([]model.CloudFailure(nil), rs.failures...)
+	dst.lastClouds = model.CloudsCopy(rs.lastClouds)
+	dst.lastApps = model.AppsCopy(rs.lastApps)
+	dst.lastNew = make(map[int]bool, len(rs.lastNew))
+	for id := range rs.lastNew {
+		dst.lastNew[id] = true
+	}
+	dst.solution = model.SolutionCopy(rs.solution)
+	dst.deployed = model.CloudsCopy(rs.deployed)
+	return dst
+}
+
+// publish replaces the snapshot served by State with the current state, it should be called with c.mu locked
+func (c *Cluster) publish() {
+	var snapshot roundState = c.roundState.copy()
+	c.snapshotMu.Lock()
+	defer c.snapshotMu.Unlock()
+	c.snapshot = snapshot
+}
+
+// RoundResult is the outcome of one round of a Cluster
+type RoundResult struct {
+	Time      float64        `json:"time"`      // the time of the cluster when this round was scheduled, unit second
+	Apps      []ClusterApp   `json:"apps"`      // the new apps and the remaining apps scheduled in this round
+	Accepted  []int          `json:"accepted"`  // ids of the new apps that are accepted
+	Rejected  []int          `json:"rejected"`  // ids of the new apps that are rejected
+	Lost      []int          `json:"lost"`      // ids of the remaining apps that are rejected in this round, e.g., because their cloud failed
+	Restarted []int          `json:"restarted"` // ids of the remaining apps that are restarted because of the failures since last round
+	Solution  model.Solution `json:"solution"`  // the solution of this round, the indexes are the AppIdx in Apps
+	Metrics   Metrics        `json:"metrics"`   // the metrics of this round, on the clouds with the active failures
+	Truncated bool           `json:"truncated"` // the search was stopped by the timeout before convergence
+}
+
+// ClusterApp is an app scheduled in a round, its times are since the time of the round
+type ClusterApp struct {
+	ID    int  `json:"id"`    // OriIdx of the app, the same in all rounds
+	IsNew bool `json:"isNew"` // whether the app arrived in this round, false for the remaining apps
+	AppTiming
+}
+
+// ClusterState is a snapshot of a Cluster
+type ClusterState struct {
+	Time      float64                    `json:"time"`      // the time of the cluster when the snapshot is taken, unit second
+	RoundTime float64                    `json:"roundTime"` // the time of the last round
+	Scheduler algorithms.SchedulerConfig `json:"scheduler"`
+	Clouds    []model.Cloud              `json:"clouds"`   // the clouds with the apps deployed in the last round, and the failures active in it
+	Apps      []ClusterApp               `json:"apps"`     // the apps of the last round that have not completed until now
+	Failures  []model.CloudFailure       `json:"failures"` // the failures active now
+
+	// counters since the cluster started
+	Submitted int `json:"submitted"` // number of apps that arrived
+	Rejected  int `json:"rejected"`  // number of new apps that were rejected
+	Lost      int `json:"lost"`      // number of remaining apps that were rejected in a later round
+	Restarted int `json:"restarted"` // number of times that an app was restarted because of a failure
+	Completed int `json:"completed"` // number of apps that were reported as completed
+}
+
+// NewCluster returns a Cluster of clouds without apps, the rounds that do not choose a scheduler use scheduler
+func NewCluster(clouds []model.Cloud, scheduler algorithms.SchedulerConfig) (*Cluster, error) {
+	if err := ValidateInput(clouds, nil); err != nil {
+		return nil, err
+	}
+	if _, err := algorithms.DefaultSchedulerParams(scheduler.Algorithm); err != nil {
+		return nil, err
+	}
+	c := &Cluster{
+		clouds:    withTmpAlloc(clouds),
+		scheduler: scheduler,
+		now:       time.Now,
+	}
+	c.start = c.now()
+	c.lastClouds = model.CloudsCopy(c.clouds)
+	c.deployed = model.CloudsCopy(c.clouds)
+	for i := 0; i < len(c.deployed); i++ {
+		c.deployed[i].UpdateTime = c.start
+	}
+	c.publish()
+	return c, nil
+}
+
+// time returns the time of the cluster now, unit second
+func (c *Cluster) time() float64 {
+	return c.now().Sub(c.start).Seconds()
+}
+
+// Submit schedules an arriving app group together with the remaining apps. The apps are in the same format as the input of Schedule.
+// A nil scheduler means the scheduler of the cluster. If no acceptable solution is found, the state is not changed.
+func (c *Cluster) Submit(ctx context.Context, apps []model.Application, scheduler *algorithms.SchedulerConfig) (RoundResult, error) {
+	if err := ValidateInput(c.clouds, apps); err != nil {
+		return RoundResult{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.publish()
+	return c.round(ctx, apps, nil, scheduler)
+}
+
+// Complete reports that the apps with ids have completed, e.g., a task finished earlier than estimated, or a service was stopped,
+// and reschedules the remaining apps without them. The apps that have already completed according to the estimation are ignored.
+func (c *Cluster) Complete(ctx context.Context, ids []int) (RoundResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.publish()
+	var completed map[int]bool = make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id < 0 || id >= c.nextOriIdx {
+			return RoundResult{}, fmt.Errorf("%w: id %d, the ids are in [0, %d)", ErrUnknownApp, id, c.nextOriIdx)
+		}
+		completed[id] = true
+	}
+	return c.round(ctx, nil, completed, nil)
+}
+
+// ReportFailure records a failure of a cloud or a link and reschedules the apps affected by it. The failure begins now if its Start is not after the last round.
+// If the rescheduling fails, the failure stays recorded, and the next round restarts the affected apps.
+func (c *Cluster) ReportFailure(ctx context.Context, failure model.CloudFailure) (RoundResult, error) {
+	if err := failure.Validate(len(c.clouds)); err != nil {
+		var failureErr *model.InvalidFailureError
+		errors.As(err, &failureErr)
+		return RoundResult{}, &InvalidInputError{Field: "failure." + failureErr.Field, Reason: failureErr.Reason}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.publish()
+	var now float64 = c.time()
+	// EvictFailedApps only restarts the apps for the failures beginning after the last round
+	if failure.Start <= c.lastTime || failure.Start > now {
+		failure.Start = now
+	}
+	if failure.End > failure.Start && failure.End <= now {
+		return RoundResult{}, &InvalidInputError{Field: "failure.end", Reason: "is already over"}
+	}
+	c.failures = append(c.failures, failure)
+	return c.round(ctx, nil, nil, nil)
+}
+
+// ReportRecovery ends the active failures of the cloud with cloudIdx, and of its links if links is true, and reschedules the remaining apps on the recovered clouds
+func (c *Cluster) ReportRecovery(ctx context.Context, cloudIdx int, links bool) (RoundResult, error) {
+	if cloudIdx < 0 || cloudIdx >= len(c.clouds) {
+		return RoundResult{}, &InvalidInputError{Field: "cloudIdx", Reason: fmt.Sprintf("is %d, it should be in [0, %d)", cloudIdx, len(c.clouds))}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.publish()
+	var now float64 = c.time()
+	var failures []model.CloudFailure
+	for _, failure := range c.failures {
+		if failure.Active(now) && failure.CloudIdx == cloudIdx && (failure.Kind != model.FailureLink || links) {
+			if failure.Start >= now { // it recovers as soon as it begins, so it never takes effect
+				continue
+			}
+			failure.End = now
+		}
+		failures = append(failures, failure)
+	}
+	c.failures = failures
+	return c.round(ctx, nil, nil, nil)
+}
+
+// State returns a snapshot of the cluster now, with the state of the last finished round. It does not wait for a round in progress.
+func (c *Cluster) State() (ClusterState, error) {
+	c.snapshotMu.RLock()
+	var snapshot roundState = c.snapshot
+	c.snapshotMu.RUnlock()
+
+	// the published snapshot is never changed, so it is read without the lock
+	var now float64 = c.time()
+	remainingApps, err := snapshot.remainingApps(now)
+	if err != nil {
+		return ClusterState{}, err
+	}
+	var remaining map[int]bool = make(map[int]bool, len(remainingApps))
+	for _, app := range remainingApps {
+		remaining[app.OriIdx] = true
+	}
+
+	var state ClusterState = ClusterState{
+		Time:      now,
+		RoundTime: snapshot.lastTime,
+		Scheduler: c.scheduler,
+		Clouds:    model.CloudsCopy(snapshot.deployed),
+		Submitted: snapshot.submitted,
+		Rejected:  snapshot.rejected,
+		Lost:      snapshot.lost,
+		Restarted: snapshot.restarted,
+		Completed: snapshot.completed,
+	}
+	for _, app := range clusterApps(snapshot.lastApps, snapshot.lastNew, snapshot.solution.SchedulingResult, snapshot.lastClouds) {
+		if remaining[app.ID] && app.CloudIdx >= 0 {
+			state.Apps = append(state.Apps, app)
+		}
+	}
+	for _, failure := range snapshot.failures {
+		if failure.Active(now) {
+			state.Failures = append(state.Failures, failure)
+		}
+	}
+	return state, nil
+}
+
+// remainingApps returns the apps of the last round that have not completed at now, from algorithms.CalcRemainingApps
+func (rs *roundState) remainingApps(now float64) ([]model.Application, error) {
+	if len(rs.lastApps) == 0 {
+		return nil, nil
+	}
+	timeApps := model.AppsCopy(rs.lastApps)
+	timeClouds := algorithms.SimulateDeploy(model.CloudsCopy(rs.lastClouds), timeApps, model.SolutionCopy(rs.solution))
+	algorithms.CalcStartComplTime(timeClouds, timeApps, rs.solution.SchedulingResult)
+	return algorithms.CalcRemainingApps(rs.deployed, timeClouds, now-rs.lastTime)
+}
+
+// round reschedules the remaining apps without the completed ones, after restarting the ones affected by the failures since the last round, together with newApps.
+// It should be called with c.mu locked.
+func (c *Cluster) round(ctx context.Context, newApps []model.Application, completed map[int]bool, scheduler *algorithms.SchedulerConfig) (RoundResult, error) {
+	var config algorithms.SchedulerConfig = c.scheduler
+	if scheduler != nil {
+		config = *scheduler
+	}
+	var now float64 = c.time()
+
+	remainingApps, err := c.remainingApps(now)
+	if err != nil {
+		return RoundResult{}, err
+	}
+	var completedNum int
+	for id := range completed {
+		for _, app := range remainingApps {
+			if app.OriIdx == id {
+				completedNum++
+				break
+			}
+		}
+	}
+	remainingApps = removeApps(remainingApps, completed)
+	var downTimes map[int]float64 = algorithms.EvictFailedApps(remainingApps, c.failures, c.lastTime, now)
+
+	// the new apps get their ids, and the remaining apps follow them, like in the experiments
+	newApps = model.ExpandReplicas(newApps)
+	var newIDs map[int]bool = make(map[int]bool)
+	for i := 0; i < len(newApps); i++ {
+		if newApps[i].ReplicaIdx > 0 { // the replicas of a service share its id
+			newApps[i].OriIdx = newApps[newApps[i].ReplicaOf].OriIdx
+		} else {
+			newApps[i].OriIdx = c.nextOriIdx + len(newIDs)
+			newIDs[newApps[i].OriIdx] = true
+		}
+		newApps[i].IsNew = true
+		newApps[i].GeneratedTime = now
+	}
+	var appsToDeploy []model.Application = model.CombApps(newApps, remainingApps)
+
+	var failedClouds []model.Cloud = model.ApplyFailures(c.clouds, c.failures, now)
+	var solution model.Solution
+	if len(appsToDeploy) > 0 {
+		algorithm, err := algorithms.NewScheduler(config, failedClouds, appsToDeploy)
+		if err != nil {
+			return RoundResult{}, err
+		}
+		solution, err = algorithm.ScheduleContext(ctx, model.CloudsCopy(failedClouds), model.AppsCopy(appsToDeploy))
+		if err != nil {
+			return RoundResult{}, err
+		}
+	}
+	metrics, err := Evaluate(failedClouds, appsToDeploy, solution.SchedulingResult)
+	if err != nil {
+		return RoundResult{}, err
+	}
+
+	// the round succeeded, so the state moves to now
+	var result RoundResult = RoundResult{
+		Time:      now,
+		Apps:      clusterApps(appsToDeploy, newIDs, solution.SchedulingResult, failedClouds),
+		Solution:  solution,
+		Metrics:   metrics,
+		Truncated: solution.Truncated,
+	}
+	var restarted map[int]bool = make(map[int]bool, len(downTimes))
+	for appIdx := range downTimes {
+		restarted[remainingApps[appIdx].OriIdx] = true
+	}
+	for id := range restarted {
+		result.Restarted = append(result.Restarted, id)
+	}
+	sort.Ints(result.Restarted)
+	var counted map[int]bool = make(map[int]bool) // the replicas of a service are counted once
+	for _, app := range result.Apps {
+		if counted[app.ID] {
+			continue
+		}
+		counted[app.ID] = true
+		switch {
+		case app.IsNew && app.CloudIdx >= 0:
+			result.Accepted = append(result.Accepted, app.ID)
+		case app.IsNew:
+			result.Rejected = append(result.Rejected, app.ID)
+		case app.CloudIdx < 0:
+			result.Lost = append(result.Lost, app.ID)
+		}
+	}
+
+	c.nextOriIdx += len(newIDs)
+	c.submitted += len(newIDs)
+	c.rejected += len(result.Rejected)
+	c.lost += len(result.Lost)
+	c.restarted += len(result.Restarted)
+	c.completed += completedNum
+
+	var deployed []model.Cloud = algorithms.TrulyDeploy(failedClouds, appsToDeploy, solution)
+	var elapsed time.Duration = time.Duration((now - c.lastTime) * float64(time.Second))
+	for i := 0; i < len(deployed); i++ {
+		deployed[i].UpdateTime = c.deployed[i].UpdateTime
+		if elapsed > 0 { // RefreshTime uses the real time for d <= 0, but no time has passed
+			deployed[i].RefreshTime(elapsed)
+		}
+	}
+	c.lastTime = now
+	c.lastClouds = failedClouds
+	c.lastApps = appsToDeploy
+	c.lastNew = newIDs
+	c.solution = solution
+	c.deployed = deployed
+	return result, nil
+}
+
+// clusterApps describes the apps of a round with schedulingResult on clouds, newIDs are the ids of the apps that arrived in the round
+func clusterApps(apps []model.Application, newIDs map[int]bool, schedulingResult []int, clouds []model.Cloud) []ClusterApp {
+	if len(apps) == 0 {
+		return nil
+	}
+	var described []ClusterApp
+	for i, timing := range Timings(clouds, apps, schedulingResult) {
+		described = append(described, ClusterApp{ID: apps[i].OriIdx, IsNew: newIDs[apps[i].OriIdx], AppTiming: timing})
+	}
+	return described
+}
+
+// removeApps returns a copy of apps without the apps whose OriIdx is in removed, with the indexes fixed like in algorithms.CalcRemainingApps.
+// The dependences on and the co-locations with the removed apps are deleted.
+func removeApps(apps []model.Application, removed map[int]bool) []model.Application {
+	if len(removed) == 0 {
+		return apps
+	}
+	var idxMap map[int]int = make(map[int]int)
+	var kept []model.Application
+	for i := 0; i < len(apps); i++ {
+		if removed[apps[i].OriIdx] {
+			continue
+		}
+		idxMap[apps[i].AppIdx] = len(kept)
+		kept = append(kept, model.AppCopy(apps[i]))
+	}
+	for i := 0; i < len(kept); i++ {
+		kept[i].AppIdx = idxMap[kept[i].AppIdx]
+		var depend []model.Dependence
+		for _, dependence := range kept[i].Depend {
+			if newIdx, exist := idxMap[dependence.AppIdx]; exist {
+				dependence.AppIdx = newIdx
+				depend = append(depend, dependence)
+			}
+		}
+		kept[i].Depend = depend
+		var coLocateWith []int
+		for _, coLocatedIdx := range kept[i].CoLocateWith {
+			if newIdx, exist := idxMap[coLocatedIdx]; exist {
+				coLocateWith = append(coLocateWith, newIdx)
+			}
+		}
+		kept[i].CoLocateWith = coLocateWith
+		if kept[i].ReplicaIdx > 0 { // the replicas of a service have the same OriIdx, so they are removed together
+			kept[i].ReplicaOf = idxMap[kept[i].ReplicaOf]
+		}
+	}
+	return kept
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/algorithms"
+	"gogeneticwrsp/model"
+)
+
+// forTestCluster returns a cluster of forTestClouds with First Fit, and the function moving its clock forward
+func forTestCluster(t *testing.T) (*Cluster, func(seconds float64)) {
+	cluster, err := NewCluster(forTestClouds(), algorithms.SchedulerConfig{Algorithm: "firstfit"})
+	if err != nil {
+		t.Fatalf("NewCluster error: %s", err.Error())
+	}
+	var now time.Time = cluster.start
+	cluster.now = func() time.Time { return now }
+	return cluster, func(seconds float64) {
+		now = now.Add(time.Duration(seconds * float64(time.Second)))
+	}
+}
+
+func forTestIDs(apps []ClusterApp) []int {
+	var ids []int
+	for _, app := range apps {
+		ids = append(ids, app.ID)
+	}
+	return ids
+}
+
+func TestClusterRounds(t *testing.T) {
+	cluster, advance := forTestCluster(t)
+	ctx := context.Background()
+
+	// the first group arrives
+	result, err := cluster.Submit(ctx, forTestApps(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, result.Accepted)
+	assert.Empty(t, result.Rejected)
+	assert.True(t, result.Metrics.Acceptable)
+
+	// all apps are running shortly after, and the tasks have completed much later
+	advance(3)
+	state, err := cluster.State()
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, state.Time)
+	assert.Equal(t, []int{0, 1, 2, 3}, forTestIDs(state.Apps))
+	advance(1000)
+	state, err = cluster.State()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, forTestIDs(state.Apps), "only the services should remain after the tasks complete")
+
+	// the second group is scheduled with the remaining services
+	newApps := forTestApps()[:2]
+	result, err = cluster.Submit(ctx, newApps, &algorithms.SchedulerConfig{Algorithm: "randomfit", Params: map[string]interface{}{"seed": 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 5}, result.Accepted)
+	assert.Equal(t, []int{4, 5, 0, 2}, forTestIDs(result.Apps))
+	assert.Equal(t, []bool{true, true, false, false}, []bool{result.Apps[0].IsNew, result.Apps[1].IsNew, result.Apps[2].IsNew, result.Apps[3].IsNew})
+	for _, cloud := range cluster.deployed {
+		assert.Equal(t, cluster.start.Add(1003*time.Second), cloud.UpdateTime, "the clouds should be refreshed to the time of the last round")
+	}
+
+	// a service is stopped
+	advance(1)
+	result, err = cluster.Complete(ctx, []int{0})
+	assert.NoError(t, err)
+	assert.NotContains(t, forTestIDs(result.Apps), 0)
+	state, err = cluster.State()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, state.Completed)
+	assert.Equal(t, 6, state.Submitted)
+	assert.NotContains(t, forTestIDs(state.Apps), 0)
+
+	_, err = cluster.Complete(ctx, []int{6})
+	assert.True(t, errors.Is(err, ErrUnknownApp), fmt.Sprintf("error should be ErrUnknownApp, but it is %v", err))
+
+	// the ids of the replicas come from replicaOf, which has to be checked
+	newApps = forTestApps()
+	newApps[2].Replicas, newApps[2].ReplicaIdx, newApps[2].ReplicaOf = 2, 1, 50
+	_, err = cluster.Submit(ctx, newApps, nil)
+	assert.True(t, errors.Is(err, ErrInvalidInput), fmt.Sprintf("error should be ErrInvalidInput, but it is %v", err))
+}
+
+func TestClusterStateDuringRound(t *testing.T) {
+	cluster, advance := forTestCluster(t)
+	_, err := cluster.Submit(context.Background(), forTestApps(), nil)
+	assert.NoError(t, err)
+	advance(3)
+
+	// a round in progress holds the lock and changes the state, State serves the last finished round without waiting
+	cluster.mu.Lock()
+	defer cluster.mu.Unlock()
+	cluster.submitted = 100
+	done := make(chan ClusterState)
+	go func() {
+		state, err := cluster.State()
+		assert.NoError(t, err)
+		done <- state
+	}()
+	select {
+	case state := <-done:
+		assert.Equal(t, 4, state.Submitted, "State should not see a round in progress")
+		assert.Equal(t, []int{0, 1, 2, 3}, forTestIDs(state.Apps))
+	case <-time.After(5 * time.Second):
+		t.Fatal("State waits for the round in progress")
+	}
+}
+
+func TestClusterFailures(t *testing.T) {
+	cluster, advance := forTestCluster(t)
+	ctx := context.Background()
+
+	_, err := cluster.Submit(ctx, forTestApps(), nil)
+	assert.NoError(t, err)
+	state, _ := cluster.State()
+	var onCloud0 []int
+	for _, app := range state.Apps {
+		if app.CloudIdx == 0 {
+			onCloud0 = append(onCloud0, app.ID)
+		}
+	}
+	assert.NotEmpty(t, onCloud0, "First Fit should use cloud 0")
+
+	// cloud 0 goes down, its apps are restarted on cloud 1 or lost
+	advance(2)
+	result, err := cluster.ReportFailure(ctx, model.CloudFailure{CloudIdx: 0})
+	assert.NoError(t, err)
+	assert.Equal(t, onCloud0, result.Restarted)
+	for _, app := range result.Apps {
+		assert.NotEqual(t, 0, app.CloudIdx, fmt.Sprintf("app %d should not be on the failed cloud", app.ID))
+	}
+	state, err = cluster.State()
+	assert.NoError(t, err)
+	assert.Len(t, state.Failures, 1)
+	assert.Equal(t, 2.0, state.Failures[0].Start)
+	assert.Equal(t, len(onCloud0), state.Restarted)
+	assert.Equal(t, len(result.Lost), state.Lost)
+
+	// cloud 0 recovers, and the new apps can use it again
+	advance(2)
+	_, err = cluster.ReportRecovery(ctx, 0, false)
+	assert.NoError(t, err)
+	state, err = cluster.State()
+	assert.NoError(t, err)
+	assert.Empty(t, state.Failures)
+	result, err = cluster.Submit(ctx, forTestApps()[:1], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Apps[0].CloudIdx)
+
+	_, err = cluster.ReportFailure(ctx, model.CloudFailure{CloudIdx: 2})
+	assert.True(t, errors.Is(err, ErrInvalidInput), "a failure of a missing cloud should be invalid")
+	_, err = cluster.ReportFailure(ctx, model.CloudFailure{CloudIdx: 1, Factor: 1})
+	assert.True(t, errors.Is(err, ErrInvalidInput), "a failure without any loss should be invalid")
+}
+
+func TestServerCluster(t *testing.T) {
+	cluster, advance := forTestCluster(t)
+	server := NewServer(DefaultServerConfig())
+	server.HandleCluster(cluster)
+
+	recorder := forTestPost(t, server, "/cluster/apps", SubmitRequest{Apps: forTestApps()})
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	advance(1)
+	recorder = forTestPost(t, server, "/cluster/failures", model.CloudFailure{CloudIdx: 1, Factor: 0.5})
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	recorder = forTestPost(t, server, "/cluster/recoveries", RecoveryRequest{CloudIdx: 1})
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	recorder = forTestPost(t, server, "/cluster/completions", CompleteRequest{IDs: []int{2}})
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	recorder = forTestPost(t, server, "/cluster/completions", CompleteRequest{IDs: []int{42}})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "an unknown app should be a bad request")
+
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/cluster/state", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Contains(t, recorder.Body.String(), `"completed":1`)
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidInput is matched by errors.Is for every InvalidInputError
+var ErrInvalidInput = errors.New("invalid input")
+
+// InvalidInputError means that the clouds or apps to be scheduled cannot be scheduled, e.g., they are inconsistent with each other
+type InvalidInputError struct {
+	Field  string // the invalid field, e.g., "apps[3].priority"
+	Reason string // why it is invalid
+}
+
+func (e *InvalidInputError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidInput.Error(), e.Field, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrInvalidInput) true
+func (e *InvalidInputError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gogeneticwrsp/model"
+	"io/ioutil"
+)
+
+// ReadClouds from a JSON file of []model.Cloud, the same format as the files written by experimenttools.GenerateClouds
+func ReadClouds(path string) ([]model.Cloud, error) {
+	var clouds []model.Cloud
+	if err := readJSON(path, &clouds); err != nil {
+		return nil, err
+	}
+	return clouds, nil
+}
+
+// ReadApps from a JSON file of []model.Application, the same format as the files written by experimenttools.GenerateApps
+func ReadApps(path string) ([]model.Application, error) {
+	var apps []model.Application
+	if err := readJSON(path, &apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// readJSON decodes the JSON file at path into v, a file that cannot be decoded is an InvalidInputError
+func readJSON(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return &InvalidInputError{Field: path, Reason: err.Error()}
+	}
+	return nil
+}
+
+// ValidateInput checks that clouds and apps can be scheduled together, the returned error is an *InvalidInputError.
+// The schedulers assume all of these, so invalid input may make them panic instead of returning an error.
+func ValidateInput(clouds []model.Cloud, apps []model.Application) error {
+	if len(clouds) == 0 {
+		return &InvalidInputError{Field: "clouds", Reason: "is empty"}
+	}
+	for i := 0; i < len(clouds); i++ {
+		if len(clouds[i].Capacity.NetCondClouds) != len(clouds) {
+			return &InvalidInputError{Field: fmt.Sprintf("clouds[%d].capacity.netCondClouds", i), Reason: fmt.Sprintf("has %d network conditions, but there are %d clouds", len(clouds[i].Capacity.NetCondClouds), len(clouds))}
+		}
+		if len(clouds[i].Allocatable.NetCondClouds) != len(clouds) {
+			return &InvalidInputError{Field: fmt.Sprintf("clouds[%d].allocatable.netCondClouds", i), Reason: fmt.Sprintf("has %d network conditions, but there are %d clouds", len(clouds[i].Allocatable.NetCondClouds), len(clouds))}
+		}
+		// a cloud that is down has no logical cores, see model.ApplyFailures
+		if clouds[i].Capacity.CPU.LogicalCores < 0 || clouds[i].Capacity.CPU.BaseClock <= 0 {
+			return &InvalidInputError{Field: fmt.Sprintf("clouds[%d].capacity.cpu", i), Reason: "should have non-negative logical cores and a positive base clock"}
+		}
+		// the timing model divides by these, see withTmpAlloc
+		if clouds[i].Allocatable.CPU.BaseClock <= 0 {
+			return &InvalidInputError{Field: fmt.Sprintf("clouds[%d].allocatable.cpu.baseClock", i), Reason: "should be positive"}
+		}
+		if clouds[i].Allocatable.NetCondImage.DownBw <= 0 {
+			return &InvalidInputError{Field: fmt.Sprintf("clouds[%d].allocatable.netCondImage.doneBw", i), Reason: "should be positive"}
+		}
+		if clouds[i].Allocatable.NetCondController.DownBw <= 0 {
+			return &InvalidInputError{Field: fmt.Sprintf("clouds[%d].allocatable.netCondController.doneBw", i), Reason: "should be positive"}
+		}
+	}
+
+	for i := 0; i < len(apps); i++ {
+		if apps[i].AppIdx != i {
+			return &InvalidInputError{Field: fmt.Sprintf("apps[%d].appIdx", i), Reason: fmt.Sprintf("is %d, it should be the index of the app", apps[i].AppIdx)}
+		}
+		if apps[i].Priority == 0 {
+			return &InvalidInputError{Field: fmt.Sprintf("apps[%d].priority", i), Reason: "should be in [1, 65535]"}
+		}
+		if !apps[i].IsNew && (apps[i].CloudRemainingOn < 0 || apps[i].CloudRemainingOn >= len(clouds)) {
+			return &InvalidInputError{Field: fmt.Sprintf("apps[%d].cloudRemainingOn", i), Reason: fmt.Sprintf("is %d, but there are %d clouds", apps[i].CloudRemainingOn, len(clouds))}
+		}
+		// replicaIdx and replicaOf are set by model.ExpandReplicas, a replica follows the first replica of the same service
+		if apps[i].ReplicaIdx < 0 || apps[i].ReplicaIdx > 0 && apps[i].ReplicaIdx >= apps[i].Replicas {
+			return &InvalidInputError{Field: fmt.Sprintf("apps[%d].replicaIdx", i), Reason: fmt.Sprintf("is %d, it should be in [0, %d)", apps[i].ReplicaIdx, apps[i].Replicas)}
+		}
+		if apps[i].ReplicaIdx > 0 {
+			first := apps[i].ReplicaOf
+			if !apps[i].Replicated() || first < 0 || first >= i || !apps[first].Replicated() || apps[first].ReplicaIdx != 0 || apps[first].Replicas != apps[i].Replicas {
+				return &InvalidInputError{Field: fmt.Sprintf("apps[%d].replicaOf", i), Reason: fmt.Sprintf("is %d, it should be the index of an earlier first replica of a service with %d replicas", first, apps[i].Replicas)}
+			}
+		}
+	}
+
+	if err := model.DependencyValid(apps); err != nil {
+		var dependencyErr *model.InvalidDependencyError
+		if errors.As(err, &dependencyErr) {
+			return &InvalidInputError{Field: fmt.Sprintf("apps[%d].depend", dependencyErr.AppIdx), Reason: err.Error()}
+		}
+		return &InvalidInputError{Field: "apps", Reason: err.Error()}
+	}
+	return nil
+}
+
+// withTmpAlloc returns a copy of clouds whose TmpAlloc is Allocatable, like in algorithms.checkAcceptable.
+// The timing model reads the base clock and the bandwidth from TmpAlloc, which is only a record during scheduling, so it is not taken from the input.
+func withTmpAlloc(clouds []model.Cloud) []model.Cloud {
+	var dst []model.Cloud = model.CloudsCopy(clouds)
+	for i := 0; i < len(dst); i++ {
+		dst[i].TmpAlloc = model.ResCopy(dst[i].Allocatable)
+	}
+	return dst
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// output formats of WriteResult
+const (
+	FormatJSON string = "json"
+	FormatCSV  string = "csv"
+)
+
+// WriteResult writes result to w in format, FormatJSON or FormatCSV
+func WriteResult(w io.Writer, result Result, format string) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, result)
+	case FormatCSV:
+		return WriteCSV(w, result)
+	}
+	return fmt.Errorf("unknown output format %q, it should be %q or %q", format, FormatJSON, FormatCSV)
+}
+
+// WriteJSON writes result to w as an indented JSON object
+func WriteJSON(w io.Writer, result Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// WriteCSV writes result to w as two CSV tables separated by an empty line:
+// one row for each app with its placement and timings, then one "metric,value" row for each metric.
+func WriteCSV(w io.Writer, result Result) error {
+	writer := csv.NewWriter(w)
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	rows := [][]string{{"appIdx", "cloudIdx", "isTask", "priority", "startTime", "imagePullDoneTime", "dataInputDoneTime", "stableTime", "taskCompletionTime", "rejectReason"}}
+	for i, timing := range result.Timings {
+		var rejectReason string
+		if i < len(result.Solution.Placements) {
+			rejectReason = string(result.Solution.Placements[i].RejectReason)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(timing.AppIdx),
+			strconv.Itoa(timing.CloudIdx),
+			strconv.FormatBool(timing.IsTask),
+			strconv.Itoa(int(timing.Priority)),
+			formatFloat(timing.StartTime),
+			formatFloat(timing.ImagePullDoneTime),
+			formatFloat(timing.DataInputDoneTime),
+			formatFloat(timing.StableTime),
+			formatFloat(timing.TaskCompletionTime),
+			rejectReason,
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+
+	m := result.Metrics
+	rows = [][]string{
+		{"metric", "value"},
+		{"algorithm", result.Algorithm},
+		{"acceptable", strconv.FormatBool(m.Acceptable)},
+		{"acceptedApps", strconv.Itoa(m.AcceptedApps)},
+		{"rejectedApps", strconv.Itoa(m.RejectedApps)},
+		{"acceptedPriority", strconv.FormatUint(m.AcceptedPriority, 10)},
+		{"totalPriority", strconv.FormatUint(m.TotalPriority, 10)},
+		{"acceptedPriorityRate", formatFloat(m.AcceptedPriorityRate)},
+		{"acceptedSvcPriRate", formatFloat(m.AcceptedSvcPriRate)},
+		{"acceptedTaskPriRate", formatFloat(m.AcceptedTaskPriRate)},
+		{"deadlineHitPriRate", formatFloat(m.DeadlineHitPriRate)},
+		{"makespan", formatFloat(m.Makespan)},
+		{"cpuIdleRate", formatFloat(m.CPUIdleRate)},
+		{"memoryIdleRate", formatFloat(m.MemoryIdleRate)},
+		{"storageIdleRate", formatFloat(m.StorageIdleRate)},
+		{"gpuIdleRate", formatFloat(m.GPUIdleRate)},
+		{"bwIdleRate", formatFloat(m.BwIdleRate)},
+		{"cost", formatFloat(m.Cost)},
+		{"hourlyCost", formatFloat(m.HourlyCost)},
+		{"energy", formatFloat(m.Energy)},
+		{"carbon", formatFloat(m.Carbon)},
+	}
+	return writer.WriteAll(rows)
+}
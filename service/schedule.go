@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gogeneticwrsp/algorithms"
+	"gogeneticwrsp/model"
+	"math"
+)
+
+// Result is the output of Schedule, what the scheduler tool and the scheduling service return
+type Result struct {
+	Algorithm string         `json:"algorithm"` // the name of the scheduler in the registry
+	Solution  model.Solution `json:"solution"`
+	Timings   []AppTiming    `json:"timings"` // in the order of apps
+	Metrics   Metrics        `json:"metrics"`
+}
+
+// AppTiming is the time attributes of one app calculated by algorithms.CalcStartComplTime, all 0 if the app is rejected, unit second
+type AppTiming struct {
+	AppIdx             int     `json:"appIdx"`
+	CloudIdx           int     `json:"cloudIdx"` // -1 if the app is rejected
+	IsTask             bool    `json:"isTask"`
+	Priority           uint16  `json:"priority"`
+	StartTime          float64 `json:"startTime"`
+	ImagePullDoneTime  float64 `json:"imagePullDoneTime"`
+	DataInputDoneTime  float64 `json:"dataInputDoneTime"`
+	StableTime         float64 `json:"stableTime"`
+	TaskCompletionTime float64 `json:"taskCompletionTime"` // only task has this
+}
+
+// Metrics evaluate a scheduling result with the functions in algorithms/evaluation.go
+type Metrics struct {
+	Acceptable           bool    `json:"acceptable"` // whether the scheduling result violates no constraint, see algorithms.Acceptable
+	AcceptedApps         int     `json:"acceptedApps"`
+	RejectedApps         int     `json:"rejectedApps"`
+	AcceptedPriority     uint64  `json:"acceptedPriority"`
+	TotalPriority        uint64  `json:"totalPriority"`
+	AcceptedPriorityRate float64 `json:"acceptedPriorityRate"`
+	AcceptedSvcPriRate   float64 `json:"acceptedSvcPriRate"`  // 0 if there is no service
+	AcceptedTaskPriRate  float64 `json:"acceptedTaskPriRate"` // 0 if there is no task
+	DeadlineHitPriRate   float64 `json:"deadlineHitPriRate"`
+	Makespan             float64 `json:"makespan"` // the time when the last accepted app becomes stable or completes, unit second
+	CPUIdleRate          float64 `json:"cpuIdleRate"`
+	MemoryIdleRate       float64 `json:"memoryIdleRate"`
+	StorageIdleRate      float64 `json:"storageIdleRate"`
+	GPUIdleRate          float64 `json:"gpuIdleRate"`
+	BwIdleRate           float64 `json:"bwIdleRate"` // 0 if there is only one cloud
+	Cost                 float64 `json:"cost"`       // the cost paid once, see algorithms.Cost
+	HourlyCost           float64 `json:"hourlyCost"` // the cost paid every hour for the accepted services
+	Energy               float64 `json:"energy"`     // the energy consumed until Makespan, unit joule
+	Carbon               float64 `json:"carbon"`     // the carbon emissions of Energy, unit gram CO2-equivalent
+}
+
+// Schedule validates clouds and apps, and schedules apps to clouds with the scheduler selected by config.
+// The replicas of the replicated services are expanded with model.ExpandReplicas before scheduling, so the result may have more apps than the input.
+// The TmpAlloc of clouds is ignored, it is derived from Allocatable.
+// An invalid input is an *InvalidInputError, and an invalid config is algorithms.ErrUnknownScheduler or algorithms.ErrInvalidParams.
+func Schedule(ctx context.Context, clouds []model.Cloud, apps []model.Application, config algorithms.SchedulerConfig) (Result, error) {
+	if err := ValidateInput(clouds, apps); err != nil {
+		return Result{}, err
+	}
+	clouds, apps = withTmpAlloc(clouds), model.ExpandReplicas(apps)
+
+	scheduler, err := algorithms.NewScheduler(config, clouds, apps)
+	if err != nil {
+		return Result{}, err
+	}
+	solution, err := scheduler.ScheduleContext(ctx, model.CloudsCopy(clouds), model.AppsCopy(apps))
+	if err != nil {
+		return Result{}, err
+	}
+
+	metrics, err := Evaluate(clouds, apps, solution.SchedulingResult)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Algorithm: config.Algorithm,
+		Solution:  solution,
+		Timings:   Timings(clouds, apps, solution.SchedulingResult),
+		Metrics:   metrics,
+	}, nil
+}
+
+// Timings calculates the time attributes of every app with schedulingResult
+func Timings(clouds []model.Cloud, apps []model.Application, schedulingResult []int) []AppTiming {
+	var deployedClouds []model.Cloud = algorithms.SimulateDeploy(clouds, apps, model.Solution{SchedulingResult: schedulingResult})
+	var timeApps []model.Application = algorithms.CalcStartComplTime(deployedClouds, model.AppsCopy(apps), schedulingResult)
+
+	var timings []AppTiming = make([]AppTiming, len(apps))
+	for i := 0; i < len(apps); i++ {
+		timings[i] = AppTiming{AppIdx: i, CloudIdx: -1, IsTask: apps[i].IsTask, Priority: apps[i].Priority}
+		if schedulingResult[i] == len(clouds) {
+			continue
+		}
+		timings[i].CloudIdx = schedulingResult[i]
+		timings[i].StartTime = timeApps[i].StartTime
+		timings[i].ImagePullDoneTime = timeApps[i].ImagePullDoneTime
+		timings[i].DataInputDoneTime = timeApps[i].DataInputDoneTime
+		timings[i].StableTime = timeApps[i].StableTime
+		timings[i].TaskCompletionTime = timeApps[i].TaskCompletionTime
+	}
+	return timings
+}
+
+// ValidateSchedulingResult checks that schedulingResult has a cloud index or len(clouds) for each app, the returned error is an *InvalidInputError
+func ValidateSchedulingResult(clouds []model.Cloud, apps []model.Application, schedulingResult []int) error {
+	if len(schedulingResult) != len(apps) {
+		return &InvalidInputError{Field: "schedulingResult", Reason: fmt.Sprintf("has %d genes, but there are %d apps", len(schedulingResult), len(apps))}
+	}
+	for i := 0; i < len(schedulingResult); i++ {
+		if schedulingResult[i] < 0 || schedulingResult[i] > len(clouds) {
+			return &InvalidInputError{Field: fmt.Sprintf("schedulingResult[%d]", i), Reason: fmt.Sprintf("is %d, it should be in [0, %d]", schedulingResult[i], len(clouds))}
+		}
+	}
+	return nil
+}
+
+// Evaluate calculates the metrics of schedulingResult, an invalid input is an *InvalidInputError
+func Evaluate(clouds []model.Cloud, apps []model.Application, schedulingResult []int) (Metrics, error) {
+	if err := ValidateInput(clouds, apps); err != nil {
+		return Metrics{}, err
+	}
+	if err := ValidateSchedulingResult(clouds, apps, schedulingResult); err != nil {
+		return Metrics{}, err
+	}
+	clouds = withTmpAlloc(clouds)
+
+	var metrics Metrics = Metrics{
+		Acceptable:          algorithms.Acceptable(clouds, apps, schedulingResult),
+		AcceptedPriority:    algorithms.AcceptedPriority(clouds, apps, schedulingResult),
+		TotalPriority:       algorithms.TotalPriority(clouds, apps, schedulingResult),
+		AcceptedSvcPriRate:  finite(algorithms.AcceptedSvcPriRate(clouds, apps, schedulingResult)),
+		AcceptedTaskPriRate: finite(algorithms.AcceptedTaskPriRate(clouds, apps, schedulingResult)),
+		DeadlineHitPriRate:  finite(algorithms.DeadlineHitPriRate(clouds, apps, schedulingResult)),
+		CPUIdleRate:         finite(algorithms.CPUIdleRate(clouds, apps, schedulingResult)),
+		MemoryIdleRate:      finite(algorithms.MemoryIdleRate(clouds, apps, schedulingResult)),
+		StorageIdleRate:     finite(algorithms.StorageIdleRate(clouds, apps, schedulingResult)),
+		GPUIdleRate:         finite(algorithms.GPUIdleRate(clouds, apps, schedulingResult)),
+		BwIdleRate:          finite(algorithms.BwIdleRate(clouds, apps, schedulingResult)),
+		Cost:                algorithms.Cost(clouds, apps, schedulingResult, 0),
+	}
+	metrics.HourlyCost = algorithms.Cost(clouds, apps, schedulingResult, 1) - metrics.Cost
+	if metrics.TotalPriority > 0 {
+		metrics.AcceptedPriorityRate = float64(metrics.AcceptedPriority) / float64(metrics.TotalPriority)
+	}
+
+	for i, timing := range Timings(clouds, apps, schedulingResult) {
+		if timing.CloudIdx < 0 {
+			metrics.RejectedApps++
+			continue
+		}
+		metrics.AcceptedApps++
+		// a service finishes its work on the cloud when it is stable, a task finishes when it completes
+		if apps[i].IsTask {
+			metrics.Makespan = math.Max(metrics.Makespan, timing.TaskCompletionTime)
+		} else {
+			metrics.Makespan = math.Max(metrics.Makespan, timing.StableTime)
+		}
+	}
+	metrics.Energy = algorithms.Energy(clouds, apps, schedulingResult, metrics.Makespan)
+	metrics.Carbon = algorithms.Carbon(clouds, apps, schedulingResult, 0, metrics.Makespan)
+	return metrics, nil
+}
+
+// finite replaces the NaN of a rate without a denominator, e.g., the service rate without services, by 0, because JSON has no NaN
+func finite(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/algorithms"
+	"gogeneticwrsp/model"
+)
+
+func forTestClouds() []model.Cloud {
+	var cores []float64 = []float64{16, 8}
+	var clouds []model.Cloud = make([]model.Cloud, len(cores))
+	for i := 0; i < len(clouds); i++ {
+		clouds[i].Capacity.CPU = model.CPUResource{LogicalCores: cores[i], BaseClock: 2.5}
+		clouds[i].Capacity.Memory = 32 * 1024 * 1024 * 1024
+		clouds[i].Capacity.Storage = 256 * 1024 * 1024 * 1024
+		clouds[i].Capacity.NetCondClouds = make([]model.NetworkCondition, len(cores))
+		for j := 0; j < len(cores); j++ {
+			if i == j {
+				clouds[i].Capacity.NetCondClouds[j] = model.NetworkCondition{RTT: 0, DownBw: 100000}
+			} else {
+				clouds[i].Capacity.NetCondClouds[j] = model.NetworkCondition{RTT: 20, DownBw: 200}
+			}
+		}
+		clouds[i].Capacity.NetCondImage = model.NetworkCondition{RTT: 50, DownBw: 100}
+		clouds[i].Capacity.NetCondController = model.NetworkCondition{RTT: 20, DownBw: 100}
+		clouds[i].Capacity.UpBwImage = 100
+		clouds[i].Capacity.UpBwController = 100
+		clouds[i].Allocatable = model.ResCopy(clouds[i].Capacity)
+		clouds[i].TmpAlloc = model.ResCopy(clouds[i].Capacity)
+	}
+	return clouds
+}
+
+func forTestApps() []model.Application {
+	var apps []model.Application
+	for i := 0; i < 4; i++ {
+		var app model.Application = model.Application{
+			InputDataSize:   float64(1+i) * 1024 * 1024,
+			ImageSize:       float64(10+i) * 1024 * 1024,
+			StartUpCPUCycle: 3 * 1024 * 1024 * 1024,
+			Priority:        uint16(1000 * (4 - i)),
+			AppIdx:          i,
+			IsNew:           true,
+		}
+		if i%2 == 0 {
+			app.SvcReq = model.ServiceResources{CPUClock: float64(4 + i), Memory: 2 * 1024 * 1024 * 1024, Storage: 8 * 1024 * 1024 * 1024}
+		} else {
+			app.IsTask = true
+			app.TaskReq = model.TaskResources{CPUCycle: float64(100+10*i) * 1024 * 1024 * 1024, Memory: 4 * 1024 * 1024 * 1024, Storage: 16 * 1024 * 1024 * 1024}
+		}
+		apps = append(apps, app)
+	}
+	apps[3].Depend = []model.Dependence{{AppIdx: 0, DownBw: 10, UpBw: 10, RTT: 100}}
+	return apps
+}
+
+func TestValidateInput(t *testing.T) {
+	testCases := []struct {
+		name     string
+		modify   func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application)
+		expected string // the invalid field, empty if the input is valid
+	}{
+		{
+			name: "case 1: valid",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				return clouds, apps
+			},
+		},
+		{
+			name: "case 2: no cloud",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				return nil, apps
+			},
+			expected: "clouds",
+		},
+		{
+			name: "case 3: network conditions of a missing cloud",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				return clouds[:1], apps
+			},
+			expected: "clouds[0].capacity.netCondClouds",
+		},
+		{
+			name: "case 4: wrong app index",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				apps[2].AppIdx = 5
+				return clouds, apps
+			},
+			expected: "apps[2].appIdx",
+		},
+		{
+			name: "case 5: zero priority",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				apps[1].Priority = 0
+				return clouds, apps
+			},
+			expected: "apps[1].priority",
+		},
+		{
+			name: "case 6: dependence on an app with a lower priority",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				apps[0].Depend = []model.Dependence{{AppIdx: 3}}
+				return clouds, apps
+			},
+			expected: "apps[0].depend",
+		},
+		{
+			name: "case 7: remaining on a missing cloud",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				apps[1].IsNew = false
+				apps[1].CloudRemainingOn = 2
+				return clouds, apps
+			},
+			expected: "apps[1].cloudRemainingOn",
+		},
+		{
+			name: "case 8: no base clock to allocate",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				clouds[1].Allocatable.CPU.BaseClock = 0
+				return clouds, apps
+			},
+			expected: "clouds[1].allocatable.cpu.baseClock",
+		},
+		{
+			name: "case 9: no bandwidth from the image repository",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				clouds[0].Allocatable.NetCondImage.DownBw = 0
+				return clouds, apps
+			},
+			expected: "clouds[0].allocatable.netCondImage.doneBw",
+		},
+		{
+			name: "case 10: no bandwidth from the controller",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				clouds[0].Allocatable.NetCondController.DownBw = 0
+				return clouds, apps
+			},
+			expected: "clouds[0].allocatable.netCondController.doneBw",
+		},
+		{
+			name: "case 11: expanded replicas",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				apps[0].Replicas = 3
+				return clouds, model.ExpandReplicas(apps)
+			},
+		},
+		{
+			name: "case 12: replica of a missing app",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				apps[2].Replicas, apps[2].ReplicaIdx, apps[2].ReplicaOf = 2, 1, 50
+				return clouds, apps
+			},
+			expected: "apps[2].replicaOf",
+		},
+		{
+			name: "case 13: replica of a later app",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				apps[0].Replicas, apps[0].ReplicaIdx, apps[0].ReplicaOf = 2, 1, 2
+				apps[2].Replicas = 2
+				return clouds, apps
+			},
+			expected: "apps[0].replicaOf",
+		},
+		{
+			name: "case 14: replica of a service without replicas",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				apps[2].Replicas, apps[2].ReplicaIdx, apps[2].ReplicaOf = 2, 1, 0
+				return clouds, apps
+			},
+			expected: "apps[2].replicaOf",
+		},
+		{
+			name: "case 15: replica index out of the replicas",
+			modify: func(clouds []model.Cloud, apps []model.Application) ([]model.Cloud, []model.Application) {
+				apps[0].Replicas = 2
+				apps[2].Replicas, apps[2].ReplicaIdx, apps[2].ReplicaOf = 2, 2, 0
+				return clouds, apps
+			},
+			expected: "apps[2].replicaIdx",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		err := ValidateInput(testCase.modify(forTestClouds(), forTestApps()))
+		if testCase.expected == "" {
+			assert.NoError(t, err, fmt.Sprintf("%s: unexpected error", testCase.name))
+			continue
+		}
+		var inputErr *InvalidInputError
+		assert.True(t, errors.As(err, &inputErr), fmt.Sprintf("%s: error should be an *InvalidInputError, but it is %v", testCase.name, err))
+		assert.True(t, errors.Is(err, ErrInvalidInput), fmt.Sprintf("%s: error should match ErrInvalidInput", testCase.name))
+		if inputErr != nil {
+			assert.Equal(t, testCase.expected, inputErr.Field, fmt.Sprintf("%s: invalid field not as expected", testCase.name))
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	clouds, apps := forTestClouds(), forTestApps()
+	result, err := Schedule(context.Background(), clouds, apps, algorithms.SchedulerConfig{Algorithm: "firstfit"})
+	assert.NoError(t, err)
+	assert.Equal(t, "firstfit", result.Algorithm)
+	assert.Len(t, result.Timings, len(apps))
+	assert.True(t, result.Metrics.Acceptable)
+	assert.Equal(t, len(apps), result.Metrics.AcceptedApps+result.Metrics.RejectedApps)
+	for i, timing := range result.Timings {
+		assert.Equal(t, result.Solution.Placements[i].CloudIdx, timing.CloudIdx, fmt.Sprintf("the timing of app %d should be on the cloud of its placement", i))
+		if timing.CloudIdx >= 0 && timing.IsTask {
+			assert.Greater(t, timing.TaskCompletionTime, timing.StableTime, fmt.Sprintf("task %d should complete after it is stable", i))
+			assert.LessOrEqual(t, timing.TaskCompletionTime, result.Metrics.Makespan)
+		}
+	}
+	for i := 0; i < len(apps); i++ {
+		assert.Zero(t, apps[i].StartTime, "Schedule should not change the input apps")
+	}
+
+	// TmpAlloc is derived from Allocatable, so the input does not need it
+	var noTmpClouds []model.Cloud = model.CloudsCopy(clouds)
+	for i := 0; i < len(noTmpClouds); i++ {
+		noTmpClouds[i].TmpAlloc = model.Resources{}
+	}
+	noTmpResult, err := Schedule(context.Background(), noTmpClouds, apps, algorithms.SchedulerConfig{Algorithm: "firstfit"})
+	assert.NoError(t, err)
+	assert.Equal(t, result.Timings, noTmpResult.Timings, "the timings should not depend on the TmpAlloc of the input")
+	assert.Equal(t, result.Metrics, noTmpResult.Metrics, "the metrics should not depend on the TmpAlloc of the input")
+
+	_, err = Schedule(context.Background(), clouds, apps, algorithms.SchedulerConfig{Algorithm: "nosuchscheduler"})
+	assert.True(t, errors.Is(err, algorithms.ErrUnknownScheduler), fmt.Sprintf("error should be ErrUnknownScheduler, but it is %v", err))
+
+	apps[0].Priority = 0
+	_, err = Schedule(context.Background(), clouds, apps, algorithms.SchedulerConfig{Algorithm: "firstfit"})
+	assert.True(t, errors.Is(err, ErrInvalidInput), fmt.Sprintf("error should be ErrInvalidInput, but it is %v", err))
+}
+
+func TestEvaluate(t *testing.T) {
+	clouds, apps := forTestClouds(), forTestApps()
+
+	metrics, err := Evaluate(clouds, apps, []int{0, 0, 1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, metrics.AcceptedApps)
+	assert.Equal(t, 1, metrics.RejectedApps)
+	assert.Equal(t, uint64(9000), metrics.AcceptedPriority)
+	assert.Equal(t, uint64(10000), metrics.TotalPriority)
+	assert.InDelta(t, 0.9, metrics.AcceptedPriorityRate, 1e-9)
+	assert.Equal(t, 1.0, metrics.AcceptedSvcPriRate)
+
+	// every rate should be encodable, also without services
+	var task model.Application = apps[1]
+	task.AppIdx = 0
+	metrics, err = Evaluate(clouds, []model.Application{task}, []int{len(clouds)})
+	assert.NoError(t, err)
+	_, err = json.Marshal(metrics)
+	assert.NoError(t, err, "the metrics should have no NaN")
+
+	_, err = Evaluate(clouds, apps, []int{0, 0})
+	assert.True(t, errors.Is(err, ErrInvalidInput), "a scheduling result of a wrong length should be invalid")
+	_, err = Evaluate(clouds, apps, []int{0, 0, 3, 0})
+	assert.True(t, errors.Is(err, ErrInvalidInput), "a missing cloud should be invalid")
+}
+
+func TestReadAndWrite(t *testing.T) {
+	dir := t.TempDir()
+	cloudsJson, _ := json.Marshal(forTestClouds())
+	appsJson, _ := json.Marshal(forTestApps())
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "clouds.json"), cloudsJson, 0666))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "apps.json"), appsJson, 0666))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte("[{"), 0666))
+
+	clouds, err := ReadClouds(filepath.Join(dir, "clouds.json"))
+	assert.NoError(t, err)
+	apps, err := ReadApps(filepath.Join(dir, "apps.json"))
+	assert.NoError(t, err)
+	_, err = ReadApps(filepath.Join(dir, "broken.json"))
+	assert.True(t, errors.Is(err, ErrInvalidInput), "a broken file should be an invalid input")
+	_, err = ReadApps(filepath.Join(dir, "missing.json"))
+	assert.False(t, errors.Is(err, ErrInvalidInput), "a missing file should not be an invalid input")
+
+	result, err := Schedule(context.Background(), clouds, apps, algorithms.SchedulerConfig{Algorithm: "firstfit"})
+	assert.NoError(t, err)
+
+	var jsonOut bytes.Buffer
+	assert.NoError(t, WriteResult(&jsonOut, result, FormatJSON))
+	var decoded Result
+	assert.NoError(t, json.Unmarshal(jsonOut.Bytes(), &decoded))
+	assert.Equal(t, result.Timings, decoded.Timings)
+	assert.Equal(t, result.Metrics, decoded.Metrics)
+
+	var csvOut bytes.Buffer
+	assert.NoError(t, WriteResult(&csvOut, result, FormatCSV))
+	tables := strings.Split(csvOut.String(), "\n\n")
+	assert.Len(t, tables, 2, "the CSV output should have the timings and the metrics")
+	assert.Len(t, strings.Split(strings.TrimSpace(tables[0]), "\n"), 1+len(apps), "the timings should have a header and a row for each app")
+	assert.Contains(t, tables[1], "acceptable,true")
+
+	assert.Error(t, WriteResult(&csvOut, result, "xml"))
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gogeneticwrsp/algorithms"
+	"gogeneticwrsp/model"
+)
+
+// ServerConfig bounds the requests of Server
+type ServerConfig struct {
+	Timeout       time.Duration // the longest time that a scheduler searches for one request, a request can ask for a shorter one
+	MaxConcurrent int           // the largest number of /schedule and /evaluate requests handled at the same time, the others get 503
+	MaxBodyBytes  int64         // the largest request body
+}
+
+// DefaultServerConfig returns the config used by the scheduler tool
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Timeout:       60 * time.Second,
+		MaxConcurrent: 4,
+		MaxBodyBytes:  32 * 1024 * 1024,
+	}
+}
+
+// ScheduleRequest is the body of POST /schedule, clouds and apps are in the same formats as the files read by ReadClouds and ReadApps
+type ScheduleRequest struct {
+	Clouds    []model.Cloud              `json:"clouds"`
+	Apps      []model.Application        `json:"apps"`
+	Scheduler algorithms.SchedulerConfig `json:"scheduler"`
+	Timeout   float64                    `json:"timeout"` // unit second, 0 or larger than ServerConfig.Timeout means ServerConfig.Timeout
+}
+
+// EvaluateRequest is the body of POST /evaluate
+type EvaluateRequest struct {
+	Clouds           []model.Cloud       `json:"clouds"`
+	Apps             []model.Application `json:"apps"`
+	SchedulingResult []int               `json:"schedulingResult"` // the cloud index of each app, len(clouds) means that the app is rejected
+}
+
+// EvaluateResponse is the body returned by POST /evaluate
+type EvaluateResponse struct {
+	Metrics    Metrics           `json:"metrics"`
+	Timings    []AppTiming       `json:"timings"`
+	Violations []model.Violation `json:"violations"` // all constraints violated by the scheduling result, empty if it is acceptable
+}
+
+// SubmitRequest is the body of POST /cluster/apps
+type SubmitRequest struct {
+	Apps      []model.Application         `json:"apps"`
+	Scheduler *algorithms.SchedulerConfig `json:"scheduler,omitempty"` // the scheduler of the cluster if it is nil
+}
+
+// CompleteRequest is the body of POST /cluster/completions
+type CompleteRequest struct {
+	IDs []int `json:"ids"` // ids of the completed apps
+}
+
+// RecoveryRequest is the body of POST /cluster/recoveries
+type RecoveryRequest struct {
+	CloudIdx int  `json:"cloudIdx"`
+	Links    bool `json:"links"` // whether the failed links from the cloud also recover
+}
+
+// ErrorResponse is the body returned with every status other than 200
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server serves the schedulers over HTTP with a JSON API:
+// POST /schedule takes a ScheduleRequest and returns a Result, POST /evaluate takes an EvaluateRequest and returns an EvaluateResponse,
+// GET /healthz returns 200 while the server is up, and GET /metrics returns the request counters in the Prometheus text format.
+type Server struct {
+	Config ServerConfig
+
+	mux   *http.ServeMux
+	slots chan struct{} // a request holds a slot while it is handled, so that at most Config.MaxConcurrent requests are handled at the same time
+	stats *serverStats
+}
+
+// NewServer returns a Server with config
+func NewServer(config ServerConfig) *Server {
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = 1
+	}
+	s := &Server{
+		Config: config,
+		mux:    http.NewServeMux(),
+		slots:  make(chan struct{}, config.MaxConcurrent),
+		stats:  newServerStats(),
+	}
+	s.mux.HandleFunc("/schedule", s.limited(http.MethodPost, s.handleSchedule))
+	s.mux.HandleFunc("/evaluate", s.limited(http.MethodPost, s.handleEvaluate))
+	s.mux.HandleFunc("/healthz", s.counted(http.MethodGet, s.handleHealth))
+	s.mux.HandleFunc("/metrics", s.counted(http.MethodGet, s.handleMetrics))
+	return s
+}
+
+// HandleCluster serves cluster with the endpoints of a stateful scheduler: POST /cluster/apps takes a SubmitRequest,
+// POST /cluster/completions takes a CompleteRequest, POST /cluster/failures takes a model.CloudFailure, and POST /cluster/recoveries takes a RecoveryRequest.
+// They return the RoundResult of rescheduling the cluster. GET /cluster/state returns the ClusterState.
+func (s *Server) HandleCluster(cluster *Cluster) {
+	s.mux.HandleFunc("/cluster/apps", s.limited(http.MethodPost, s.clusterRound(func(ctx context.Context, r *http.Request) (RoundResult, error) {
+		var request SubmitRequest
+		if err := decodeBody(r, &request); err != nil {
+			return RoundResult{}, err
+		}
+		return cluster.Submit(ctx, request.Apps, request.Scheduler)
+	})))
+	s.mux.HandleFunc("/cluster/completions", s.limited(http.MethodPost, s.clusterRound(func(ctx context.Context, r *http.Request) (RoundResult, error) {
+		var request CompleteRequest
+		if err := decodeBody(r, &request); err != nil {
+			return RoundResult{}, err
+		}
+		return cluster.Complete(ctx, request.IDs)
+	})))
+	s.mux.HandleFunc("/cluster/failures", s.limited(http.MethodPost, s.clusterRound(func(ctx context.Context, r *http.Request) (RoundResult, error) {
+		var failure model.CloudFailure
+		if err := decodeBody(r, &failure); err != nil {
+			return RoundResult{}, err
+		}
+		return cluster.ReportFailure(ctx, failure)
+	})))
+	s.mux.HandleFunc("/cluster/recoveries", s.limited(http.MethodPost, s.clusterRound(func(ctx context.Context, r *http.Request) (RoundResult, error) {
+		var request RecoveryRequest
+		if err := decodeBody(r, &request); err != nil {
+			return RoundResult{}, err
+		}
+		return cluster.ReportRecovery(ctx, request.CloudIdx, request.Links)
+	})))
+	s.mux.HandleFunc("/cluster/state", s.counted(http.MethodGet, func(w http.ResponseWriter, r *http.Request) int {
+		state, err := cluster.State()
+		if err != nil {
+			return writeError(w, errorStatus(err), err)
+		}
+		return writeJSON(w, http.StatusOK, state)
+	}))
+}
+
+// clusterRound handles a request that reschedules a cluster, the rescheduling is bounded by Config.Timeout
+func (s *Server) clusterRound(round func(ctx context.Context, r *http.Request) (RoundResult, error)) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) int {
+		ctx := r.Context()
+		if s.Config.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.Config.Timeout)
+			defer cancel()
+		}
+		result, err := round(ctx, r)
+		if err != nil {
+			return writeError(w, errorStatus(err), err)
+		}
+		return writeJSON(w, http.StatusOK, result)
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handlerFunc handles a request and returns the status it wrote
+type handlerFunc func(w http.ResponseWriter, r *http.Request) int
+
+// counted only allows method and counts the requests by status
+func (s *Server) counted(method string, handler handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var status int
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			status = writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s only allows %s", r.URL.Path, method))
+		} else {
+			status = handler(w, r)
+		}
+		s.stats.addRequest(r.URL.Path, status)
+	}
+}
+
+// limited is counted with the concurrency limit and the body limit
+func (s *Server) limited(method string, handler handlerFunc) http.HandlerFunc {
+	return s.counted(method, func(w http.ResponseWriter, r *http.Request) int {
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			w.Header().Set("Retry-After", "1")
+			return writeError(w, http.StatusServiceUnavailable, fmt.Errorf("%d requests are being handled, try again later", cap(s.slots)))
+		}
+		s.stats.addInFlight(1)
+		defer func() {
+			s.stats.addInFlight(-1)
+			<-s.slots
+		}()
+		r.Body = http.MaxBytesReader(w, r.Body, s.Config.MaxBodyBytes)
+		return handler(w, r)
+	})
+}
+
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request) int {
+	var request ScheduleRequest
+	if err := decodeBody(r, &request); err != nil {
+		return writeError(w, http.StatusBadRequest, err)
+	}
+
+	var timeout time.Duration = s.Config.Timeout
+	if requested := time.Duration(request.Timeout * float64(time.Second)); requested > 0 && (timeout <= 0 || requested < timeout) {
+		timeout = requested
+	}
+	ctx := r.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result, err := Schedule(ctx, request.Clouds, request.Apps, request.Scheduler)
+	s.stats.addSchedule(request.Scheduler.Algorithm, time.Since(start), err == nil && result.Solution.Truncated)
+	if err != nil {
+		return writeError(w, errorStatus(err), err)
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleEvaluate(w http.ResponseWriter, r *http.Request) int {
+	var request EvaluateRequest
+	if err := decodeBody(r, &request); err != nil {
+		return writeError(w, http.StatusBadRequest, err)
+	}
+	metrics, err := Evaluate(request.Clouds, request.Apps, request.SchedulingResult)
+	if err != nil {
+		return writeError(w, errorStatus(err), err)
+	}
+	// the input is valid now, and the timing model needs the TmpAlloc derived in the same way as in Evaluate
+	clouds := withTmpAlloc(request.Clouds)
+	return writeJSON(w, http.StatusOK, EvaluateResponse{
+		Metrics:    metrics,
+		Timings:    Timings(clouds, request.Apps, request.SchedulingResult),
+		Violations: algorithms.AcceptableViolations(clouds, request.Apps, request.SchedulingResult),
+	})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) int {
+	return writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) int {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	s.stats.write(w)
+	return http.StatusOK
+}
+
+// decodeBody decodes the JSON body of r into v, an unknown field is an error
+func decodeBody(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &InvalidInputError{Field: "request body", Reason: err.Error()}
+	}
+	return nil
+}
+
+// statusClientClosedRequest is the non-standard status of a request whose client went away before the response, as in nginx
+const statusClientClosedRequest = 499
+
+// errorStatus classifies the errors of Schedule and Evaluate into HTTP statuses
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, algorithms.ErrNoAcceptableSolution):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrInvalidInput), errors.Is(err, algorithms.ErrDirtyTimeState), errors.Is(err, algorithms.ErrUnknownScheduler), errors.Is(err, algorithms.ErrInvalidParams), errors.Is(err, ErrUnknownApp):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// writeJSON writes v with status and returns the status written. v is encoded before anything is written,
+// so a v that cannot be encoded, e.g., with a NaN, gets 500 instead of a truncated body with the original status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) int {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		log.Printf("encoding the response with status %d error: %s", status, err.Error())
+		status = http.StatusInternalServerError
+		body.Reset()
+		json.NewEncoder(&body).Encode(ErrorResponse{Error: fmt.Sprintf("encoding the response error: %s", err.Error())})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// the status has been sent, so an error here can only be logged, e.g., the client went away
+	if _, err := w.Write(body.Bytes()); err != nil {
+		log.Printf("writing the response with status %d error: %s", status, err.Error())
+	}
+	return status
+}
+
+func writeError(w http.ResponseWriter, status int, err error) int {
+	return writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+// serverStats are the counters exposed by GET /metrics
+type serverStats struct {
+	mu              sync.Mutex
+	requests        map[string]map[int]uint64 // number of requests by path and status
+	inFlight        int
+	scheduleCount   map[string]uint64  // number of schedulings by algorithm, including the failed ones
+	scheduleSeconds map[string]float64 // total time of the schedulings by algorithm
+	truncated       map[string]uint64  // number of schedulings stopped by the timeout by algorithm
+}
+
+func newServerStats() *serverStats {
+	return &serverStats{
+		requests:        make(map[string]map[int]uint64),
+		scheduleCount:   make(map[string]uint64),
+		scheduleSeconds: make(map[string]float64),
+		truncated:       make(map[string]uint64),
+	}
+}
+
+func (ss *serverStats) addRequest(path string, status int) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.requests[path] == nil {
+		ss.requests[path] = make(map[int]uint64)
+	}
+	ss.requests[path][status]++
+}
+
+func (ss *serverStats) addInFlight(d int) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.inFlight += d
+}
+
+func (ss *serverStats) addSchedule(algorithm string, d time.Duration, truncated bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.scheduleCount[algorithm]++
+	ss.scheduleSeconds[algorithm] += d.Seconds()
+	if truncated {
+		ss.truncated[algorithm]++
+	}
+}
+
+// write the counters in the Prometheus text format, in a fixed order
+func (ss *serverStats) write(w http.ResponseWriter) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	fmt.Fprintln(w, "# TYPE wrsp_requests_total counter")
+	var paths []string
+	for path := range ss.requests {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		var statuses []int
+		for status := range ss.requests[path] {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			fmt.Fprintf(w, "wrsp_requests_total{path=%q,code=%q} %d\n", path, strconv.Itoa(status), ss.requests[path][status])
+		}
+	}
+	fmt.Fprintln(w, "# TYPE wrsp_in_flight_requests gauge")
+	fmt.Fprintf(w, "wrsp_in_flight_requests %d\n", ss.inFlight)
+	fmt.Fprintln(w, "# TYPE wrsp_schedule_seconds summary")
+	for _, algorithm := range sortedKeys(ss.scheduleCount) {
+		fmt.Fprintf(w, "wrsp_schedule_seconds_sum{algorithm=%q} %g\n", algorithm, ss.scheduleSeconds[algorithm])
+		fmt.Fprintf(w, "wrsp_schedule_seconds_count{algorithm=%q} %d\n", algorithm, ss.scheduleCount[algorithm])
+	}
+	fmt.Fprintln(w, "# TYPE wrsp_schedule_truncated_total counter")
+	for _, algorithm := range sortedKeys(ss.truncated) {
+		fmt.Fprintf(w, "wrsp_schedule_truncated_total{algorithm=%q} %d\n", algorithm, ss.truncated[algorithm])
+	}
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
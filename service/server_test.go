@@ -0,0 +1,213 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogeneticwrsp/algorithms"
+	"gogeneticwrsp/model"
+)
+
+func forTestPost(t *testing.T, server http.Handler, path string, body interface{}) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("json.Marshal(body) error: %s", err.Error())
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data)))
+	return recorder
+}
+
+func TestServerSchedule(t *testing.T) {
+	server := NewServer(DefaultServerConfig())
+	testCases := []struct {
+		name     string
+		request  ScheduleRequest
+		expected int
+	}{
+		{
+			name:     "case 1: first fit",
+			request:  ScheduleRequest{Clouds: forTestClouds(), Apps: forTestApps(), Scheduler: algorithms.SchedulerConfig{Algorithm: "firstfit"}},
+			expected: http.StatusOK,
+		},
+		{
+			name: "case 2: genetic with params and a timeout",
+			request: ScheduleRequest{Clouds: forTestClouds(), Apps: forTestApps(), Timeout: 10, Scheduler: algorithms.SchedulerConfig{Algorithm: "mcasga", Params: map[string]interface{}{
+				"chromosomesCount": 10,
+				"iterationCount":   20,
+				"seed":             1,
+			}}},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "case 3: unknown scheduler",
+			request:  ScheduleRequest{Clouds: forTestClouds(), Apps: forTestApps(), Scheduler: algorithms.SchedulerConfig{Algorithm: "nosuchscheduler"}},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "case 4: no cloud",
+			request:  ScheduleRequest{Apps: forTestApps(), Scheduler: algorithms.SchedulerConfig{Algorithm: "firstfit"}},
+			expected: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		recorder := forTestPost(t, server, "/schedule", testCase.request)
+		assert.Equal(t, testCase.expected, recorder.Code, fmt.Sprintf("%s: status not as expected, body: %s", testCase.name, recorder.Body.String()))
+		if testCase.expected != http.StatusOK {
+			var errResponse ErrorResponse
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &errResponse))
+			assert.NotEmpty(t, errResponse.Error, fmt.Sprintf("%s: error message should not be empty", testCase.name))
+			continue
+		}
+		var result Result
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+		assert.Len(t, result.Timings, len(testCase.request.Apps), fmt.Sprintf("%s: timings not as expected", testCase.name))
+		assert.True(t, result.Metrics.Acceptable, fmt.Sprintf("%s: the result should be acceptable", testCase.name))
+	}
+
+	// unknown fields and other methods
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/schedule", strings.NewReader(`{"clouds": [], "algorithm": "firstfit"}`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "an unknown field should be a bad request")
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/schedule", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, http.MethodPost, recorder.Header().Get("Allow"))
+}
+
+func TestServerEvaluate(t *testing.T) {
+	server := NewServer(DefaultServerConfig())
+
+	recorder := forTestPost(t, server, "/evaluate", EvaluateRequest{Clouds: forTestClouds(), Apps: forTestApps(), SchedulingResult: []int{0, 0, 1, 2}})
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var response EvaluateResponse
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Metrics.AcceptedApps)
+	assert.Equal(t, -1, response.Timings[3].CloudIdx)
+
+	// too many services on the smaller cloud
+	apps := forTestApps()
+	apps[0].SvcReq.CPUClock, apps[2].SvcReq.CPUClock = 15, 15
+	recorder = forTestPost(t, server, "/evaluate", EvaluateRequest{Clouds: forTestClouds(), Apps: apps, SchedulingResult: []int{1, 2, 1, 2}})
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	response = EvaluateResponse{}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.False(t, response.Metrics.Acceptable)
+	assert.NotEmpty(t, response.Violations, "the violations of an unacceptable result should be returned")
+
+	recorder = forTestPost(t, server, "/evaluate", EvaluateRequest{Clouds: forTestClouds(), Apps: forTestApps(), SchedulingResult: []int{0}})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "a scheduling result of a wrong length should be a bad request")
+
+	// the clients do not need to send TmpAlloc
+	var noTmpClouds []model.Cloud = forTestClouds()
+	for i := 0; i < len(noTmpClouds); i++ {
+		noTmpClouds[i].TmpAlloc = model.Resources{}
+	}
+	recorder = forTestPost(t, server, "/evaluate", EvaluateRequest{Clouds: noTmpClouds, Apps: forTestApps(), SchedulingResult: []int{0, 0, 1, 2}})
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var noTmpResponse EvaluateResponse
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &noTmpResponse))
+	recorder = forTestPost(t, server, "/evaluate", EvaluateRequest{Clouds: forTestClouds(), Apps: forTestApps(), SchedulingResult: []int{0, 0, 1, 2}})
+	response = EvaluateResponse{}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, response, noTmpResponse, "the response should not depend on the TmpAlloc of the request")
+}
+
+func TestServerLimits(t *testing.T) {
+	var config ServerConfig = DefaultServerConfig()
+	config.MaxConcurrent = 1
+	config.MaxBodyBytes = 1024
+	server := NewServer(config)
+
+	// hold the only slot, as a request being handled does
+	server.slots <- struct{}{}
+	recorder := forTestPost(t, server, "/evaluate", EvaluateRequest{})
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "a request over the concurrency limit should be rejected")
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+	<-server.slots
+
+	recorder = forTestPost(t, server, "/evaluate", EvaluateRequest{Clouds: forTestClouds(), Apps: forTestApps(), SchedulingResult: []int{0, 0, 1, 2}})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "a body over the limit should be a bad request")
+
+	// the search stops at the timeout of the request and returns the best solution found until then
+	config.MaxBodyBytes = DefaultServerConfig().MaxBodyBytes
+	config.Timeout = 200 * time.Millisecond
+	server = NewServer(config)
+	before := time.Now()
+	recorder = forTestPost(t, server, "/schedule", ScheduleRequest{Clouds: forTestClouds(), Apps: forTestApps(), Scheduler: algorithms.SchedulerConfig{Algorithm: "mcasga", Params: map[string]interface{}{
+		"iterationCount":        1000000,
+		"stopNoUpdateIteration": 1000000,
+		"init":                  "acceptable",
+	}}})
+	assert.Less(t, time.Since(before), 10*time.Second, "the scheduling should stop at the timeout")
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var result Result
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	assert.True(t, result.Solution.Truncated)
+}
+
+func TestServerHealthAndMetrics(t *testing.T) {
+	server := NewServer(DefaultServerConfig())
+	forTestPost(t, server, "/schedule", ScheduleRequest{Clouds: forTestClouds(), Apps: forTestApps(), Scheduler: algorithms.SchedulerConfig{Algorithm: "firstfit"}})
+	forTestPost(t, server, "/schedule", ScheduleRequest{Scheduler: algorithms.SchedulerConfig{Algorithm: "firstfit"}})
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.Contains(t, string(body), `wrsp_requests_total{path="/schedule",code="200"} 1`)
+	assert.Contains(t, string(body), `wrsp_requests_total{path="/schedule",code="400"} 1`)
+	assert.Contains(t, string(body), `wrsp_requests_total{path="/healthz",code="200"} 1`)
+	assert.Contains(t, string(body), `wrsp_schedule_seconds_count{algorithm="firstfit"} 2`)
+	assert.Contains(t, string(body), "wrsp_in_flight_requests 0")
+}
+
+func TestWriteJSON(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	assert.Equal(t, http.StatusOK, writeJSON(recorder, http.StatusOK, map[string]float64{"makespan": 1}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"makespan": 1}`, recorder.Body.String())
+
+	// a NaN cannot be encoded in JSON
+	recorder = httptest.NewRecorder()
+	assert.Equal(t, http.StatusInternalServerError, writeJSON(recorder, http.StatusOK, map[string]float64{"makespan": math.NaN()}))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	var response ErrorResponse
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response), "the body should be a complete ErrorResponse")
+	assert.NotEmpty(t, response.Error)
+}
+
+func TestErrorStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "case 1: no acceptable solution", err: algorithms.ErrNoAcceptableSolution, expected: http.StatusUnprocessableEntity},
+		{name: "case 2: invalid input", err: &InvalidInputError{Field: "clouds", Reason: "is empty"}, expected: http.StatusBadRequest},
+		{name: "case 3: timeout", err: fmt.Errorf("scheduling: %w", context.DeadlineExceeded), expected: http.StatusGatewayTimeout},
+		{name: "case 4: client gone", err: fmt.Errorf("scheduling: %w", context.Canceled), expected: statusClientClosedRequest},
+		{name: "case 5: unknown", err: errors.New("broken"), expected: http.StatusInternalServerError},
+	}
+	for _, testCase := range testCases {
+		t.Logf("test: %s", testCase.name)
+		assert.Equal(t, testCase.expected, errorStatus(testCase.err), testCase.name)
+	}
+}
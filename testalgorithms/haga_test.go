@@ -25,7 +25,10 @@ func TestHagaSchedule(t *testing.T) {
 	clouds = experimenttools.ReadClouds(numCloud)
 	apps = experimenttools.ReadApps(numApp, appSuffix)
 
-	hagaAlgorithm := algorithms.NewHAGA(10, 0.6, 200, 5000, 0.6, 0.7, 250, clouds, apps)
+	hagaAlgorithm, err := algorithms.NewHAGA(10, 0.6, 200, 5000, 0.6, 0.7, 250, clouds, apps)
+	if err != nil {
+		log.Panicf("algorithms.NewHAGA, error: %s", err.Error())
+	}
 	solution, err := hagaAlgorithm.Schedule(clouds, apps)
 	if err != nil {
 		log.Panicf("geneticAlgorithm.Schedule(clouds, apps), error: %s", err.Error())
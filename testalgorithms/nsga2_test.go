@@ -25,7 +25,10 @@ func TestNsgaiiSchedule(t *testing.T) {
 	clouds = experimenttools.ReadClouds(numCloud)
 	apps = experimenttools.ReadApps(numApp, appSuffix)
 
-	nsgaiiAlgorithm := algorithms.NewNSGAII(200, 5000, 1, 0.25, 250, clouds, apps)
+	nsgaiiAlgorithm, err := algorithms.NewNSGAII(200, 5000, 1, 0.25, 250, clouds, apps)
+	if err != nil {
+		log.Panicf("algorithms.NewNSGAII, error: %s", err.Error())
+	}
 	solution, err := nsgaiiAlgorithm.Schedule(clouds, apps)
 	if err != nil {
 		log.Panicf("geneticAlgorithm.Schedule(clouds, apps), error: %s", err.Error())